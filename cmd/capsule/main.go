@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -14,7 +17,9 @@ import (
 
 	"github.com/jeanhaley32/claude-capsule/internal/constants"
 	"github.com/jeanhaley32/claude-capsule/internal/docker"
+	"github.com/jeanhaley32/claude-capsule/internal/docslink"
 	"github.com/jeanhaley32/claude-capsule/internal/embedded"
+	"github.com/jeanhaley32/claude-capsule/internal/lifecycle"
 	"github.com/jeanhaley32/claude-capsule/internal/platform"
 	"github.com/jeanhaley32/claude-capsule/internal/repo"
 	"github.com/jeanhaley32/claude-capsule/internal/state"
@@ -24,6 +29,9 @@ import (
 
 var version = "0.3.0"
 
+// docsLinkName is the workspace symlink name from --docs-link; empty selects the default.
+var docsLinkName string
+
 // setupShutdownHandler registers signal handlers for graceful shutdown.
 // Returns a cancel function that should be deferred to cleanup the handler.
 // The cleanup function is ONLY called when a signal is received, not on normal exit.
@@ -99,12 +107,61 @@ func getContainerNameForCwd() (string, string, error) {
 
 	containerName, err := repoIdentifier.GetContainerName(workspacePath)
 	if err != nil {
-		return docker.DefaultContainerName, cwd, nil
+		containerName, err = docker.ResolveContainerName("")
+	} else {
+		containerName, err = docker.ApplyNamePrefix(containerName)
+	}
+	if err != nil {
+		return "", "", err
 	}
 
 	return containerName, cwd, nil
 }
 
+// printStartWarnings prints advice about valid but risky container settings,
+// including resource limits beyond what the Docker engine has.
+func printStartWarnings(dockerManager *docker.Manager, config docker.ContainerConfig) {
+	warnings := config.Warnings()
+	// Only limits in ExtraArgs can exceed the engine; skip docker info otherwise
+	if len(config.ExtraArgs) > 0 {
+		if res, err := dockerManager.EngineResources(); err == nil {
+			warnings = append(warnings, config.ResourceWarnings(res)...)
+		}
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+}
+
+// passwordSourcesFromFlags reads the --password-file and --password-stdin flags.
+func passwordSourcesFromFlags(cmd *cobra.Command) (terminal.PasswordSources, error) {
+	var sources terminal.PasswordSources
+	var err error
+	if sources.File, err = cmd.Flags().GetString("password-file"); err != nil {
+		return sources, fmt.Errorf("invalid password-file flag: %w", err)
+	}
+	if sources.Stdin, err = cmd.Flags().GetBool("password-stdin"); err != nil {
+		return sources, fmt.Errorf("invalid password-stdin flag: %w", err)
+	}
+	return sources, nil
+}
+
+// newPathResolver creates a path resolver honoring the --volume-dir flag.
+func newPathResolver(cmd *cobra.Command) (*volume.PathResolver, error) {
+	pathResolver, err := volume.NewPathResolver()
+	if err != nil {
+		return nil, err
+	}
+	volumeDir, err := cmd.Flags().GetString("volume-dir")
+	if err != nil {
+		return nil, err
+	}
+	if err := pathResolver.SetVolumeDir(volumeDir); err != nil {
+		return nil, err
+	}
+	return pathResolver, nil
+}
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "capsule",
@@ -112,10 +169,28 @@ func main() {
 		Long:  "A containerized, security-focused workspace for Claude Code with encrypted credential storage.",
 	}
 
+	rootCmd.PersistentFlags().String("volume-dir", "", "Directory for encrypted volumes (default ~/.capsule/volumes)")
+	rootCmd.PersistentFlags().String("temp-dir", "", "Directory for volume scratch files (default system temp directory)")
+	rootCmd.PersistentFlags().String("docs-link", "", "Name of the workspace docs symlink (default "+constants.DocsSymlinkName+")")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		tempDir, err := cmd.Flags().GetString("temp-dir")
+		if err != nil {
+			return err
+		}
+		if err := volume.SetTempDir(tempDir); err != nil {
+			return err
+		}
+		if docsLinkName, err = cmd.Flags().GetString("docs-link"); err != nil {
+			return err
+		}
+		return docslink.Validate(docsLinkName)
+	}
+
 	rootCmd.AddCommand(
 		newBootstrapCmd(),
 		newStartCmd(),
 		newStopCmd(),
+		newReconcileCmd(),
 		newUnlockCmd(),
 		newLockCmd(),
 		newStatusCmd(),
@@ -139,6 +214,7 @@ func newBootstrapCmd() *cobra.Command {
 	cmd.Flags().Int("size", 0, "Volume size in GB (prompts if not specified)")
 	cmd.Flags().String("api-key", "", "Claude API key (optional, can be added later)")
 	cmd.Flags().String("volume", "", "Explicit path for encrypted volume")
+	cmd.Flags().String("format", "", "Volume image format: sparseimage (default) or sparsebundle")
 	cmd.Flags().Bool("local", false, "Create volume in current directory")
 	cmd.Flags().Bool("global", false, "Create volume in ~/.capsule/volumes/ (default)")
 	cmd.Flags().StringSlice("context", []string{}, "Markdown files to extend Claude context (can be specified multiple times)")
@@ -171,6 +247,14 @@ func runBootstrap(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("invalid context flag: %w", err)
 	}
+	formatFlag, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return fmt.Errorf("invalid format flag: %w", err)
+	}
+	format := volume.VolumeFormat(formatFlag)
+	if err := format.Validate(); err != nil {
+		return err
+	}
 	// Convert context files to absolute paths
 	for i, ctxFile := range contextFiles {
 		if !filepath.IsAbs(ctxFile) {
@@ -183,7 +267,7 @@ func runBootstrap(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create path resolver
-	pathResolver, err := volume.NewPathResolver()
+	pathResolver, err := newPathResolver(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to create path resolver: %w", err)
 	}
@@ -230,6 +314,16 @@ func runBootstrap(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Default paths take the format's extension; an explicit path implies
+	// the format by its extension unless --format is given
+	if volumePathFlag == "" {
+		volumePath = volume.WithFormatExtension(volumePath, format)
+	} else if format == "" {
+		if detected, err := volume.DetectVolumeFormat(volumePath); err == nil {
+			format = detected
+		}
+	}
+
 	// Prompt for size if not specified
 	if size == 0 {
 		if locationSpecified {
@@ -279,6 +373,7 @@ func runBootstrap(cmd *cobra.Command, args []string) error {
 		Password:     password,
 		ContextFiles: contextFiles,
 		Version:      version,
+		Format:       format,
 	}
 
 	if err := volumeManager.Bootstrap(cfg); err != nil {
@@ -319,6 +414,14 @@ func newStartCmd() *cobra.Command {
 
 	cmd.Flags().String("volume", "", "Path to encrypted volume (auto-detected if not specified)")
 	cmd.Flags().String("workspace", "", "Workspace path (defaults to current directory or git root)")
+	cmd.Flags().Bool("keep-running", false, "Leave the container running after the shell exits")
+	cmd.Flags().String("record", "", "Record the shell session transcript to this file")
+	cmd.Flags().Bool("password-stdin", false, "Not supported: the shell needs stdin (use --password-file or 'capsule unlock --password-stdin')")
+	cmd.Flags().String("password-file", "", "Read password from a file (must be mode 0600 or stricter)")
+	cmd.Flags().Bool("strict-filesystem", false, "Fail instead of warning when the workspace or volume is on a network or FUSE filesystem")
+	cmd.Flags().Bool("ephemeral", false, "Run without the encrypted volume; nothing is kept after the container stops")
+	cmd.Flags().Bool("mirror-path", false, "Mount the workspace at its host path instead of /workspace")
+	cmd.Flags().StringArray("post-start", nil, "Shell command to run in a newly created container before the shell opens (can be specified multiple times)")
 
 	return cmd
 }
@@ -332,6 +435,43 @@ func runStart(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("invalid workspace flag: %w", err)
 	}
+	keepRunning, err := cmd.Flags().GetBool("keep-running")
+	if err != nil {
+		return fmt.Errorf("invalid keep-running flag: %w", err)
+	}
+	strictFilesystem, err := cmd.Flags().GetBool("strict-filesystem")
+	if err != nil {
+		return fmt.Errorf("invalid strict-filesystem flag: %w", err)
+	}
+	recordPath, err := cmd.Flags().GetString("record")
+	if err != nil {
+		return fmt.Errorf("invalid record flag: %w", err)
+	}
+	ephemeral, err := cmd.Flags().GetBool("ephemeral")
+	if err != nil {
+		return fmt.Errorf("invalid ephemeral flag: %w", err)
+	}
+	mirrorPath, err := cmd.Flags().GetBool("mirror-path")
+	if err != nil {
+		return fmt.Errorf("invalid mirror-path flag: %w", err)
+	}
+	postStart, err := cmd.Flags().GetStringArray("post-start")
+	if err != nil {
+		return fmt.Errorf("invalid post-start flag: %w", err)
+	}
+	passwordSources, err := passwordSourcesFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	if passwordSources.Stdin {
+		// Reading the password would leave stdin at EOF, and the shell would exit at once
+		return fmt.Errorf("--password-stdin cannot be used with start because the shell reads stdin; use --password-file, CAPSULE_PASSWORD, or 'capsule unlock --password-stdin' before starting")
+	}
+	if recordPath != "" {
+		if recordPath, err = filepath.Abs(recordPath); err != nil {
+			return fmt.Errorf("invalid record path: %w", err)
+		}
+	}
 
 	// Get current directory once for reuse
 	cwd, err := os.Getwd()
@@ -345,18 +485,27 @@ func runStart(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create volume manager: %w", err)
 	}
 	dockerManager := docker.NewManager()
+	if err := dockerManager.SetDocsLinkName(docsLinkName); err != nil {
+		return err
+	}
 	repoIdentifier := repo.NewIdentifier()
+	if err := dockerManager.SetRecordPath(recordPath); err != nil {
+		return err
+	}
 
 	// Create path resolver
-	pathResolver, err := volume.NewPathResolver()
+	pathResolver, err := newPathResolver(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to create path resolver: %w", err)
 	}
 
-	// Find volume path using priority rules
-	volumePath, err := pathResolver.ResolveVolumePathStrict(volumePathFlag, cwd)
-	if err != nil {
-		return err
+	// Find volume path using priority rules (ephemeral sessions have none)
+	var volumePath string
+	if !ephemeral {
+		volumePath, err = pathResolver.ResolveVolumePathStrict(volumePathFlag, cwd)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Determine workspace
@@ -383,69 +532,181 @@ func runStart(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to generate container name: %w", err)
 	}
+	if containerName, err = docker.ApplyNamePrefix(containerName); err != nil {
+		return err
+	}
+
+	// Docker Desktop may still be starting right after login
+	if err := state.CheckDockerRunning(); err != nil {
+		fmt.Println("Waiting for Docker to start...")
+		ctx, cancel := context.WithTimeout(context.Background(), docker.DefaultDaemonWaitTimeout)
+		err := dockerManager.WaitDaemon(ctx)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
 
 	// Check if Docker image exists, build if needed
 	if !embedded.ImageExists(docker.DefaultImageName) {
 		fmt.Printf("Docker image '%s' not found. Building...\n", docker.DefaultImageName)
-		if err := embedded.BuildImage(docker.DefaultImageName); err != nil {
+		if err := embedded.BuildImage(docker.DefaultImageName, docker.ResolvePlatform(docker.ContainerConfig{}), nil, nil); err != nil {
 			return fmt.Errorf("failed to build Docker image: %w", err)
 		}
 		fmt.Println("Docker image built successfully!")
+	} else if outdated, err := embedded.ImageOutdated(docker.DefaultImageName); docsLinkName != "" && (err != nil || outdated) {
+		// Older images ignore the link name and would create _docs instead
+		return fmt.Errorf("Docker image '%s' predates --docs-link support; run 'capsule build-image --force' to rebuild it", docker.DefaultImageName)
+	} else if err == nil && outdated {
+		fmt.Fprintf(os.Stderr, "Warning: Docker image '%s' is out of date. Run 'capsule build-image --force' to rebuild.\n", docker.DefaultImageName)
 	}
 
 	// Verify Docker Desktop can access /tmp for encrypted volume mounts
-	fmt.Println("Checking Docker file sharing configuration...")
-	if err := dockerManager.CheckTmpFileSharing(); err != nil {
-		return fmt.Errorf("Docker file sharing check failed: %w", err)
+	if !ephemeral {
+		fmt.Println("Checking Docker file sharing configuration...")
+		if err := dockerManager.CheckTmpFileSharing(); err != nil {
+			return fmt.Errorf("Docker file sharing check failed: %w", err)
+		}
 	}
 
 	// Pre-start cleanup: remove any stale container from previous runs
-	// This prevents Docker mount conflicts even with stopped containers
+	// This prevents Docker mount conflicts even with stopped containers.
+	// A running container was left warm with --keep-running, so re-enter it instead.
 	fmt.Println("Checking for stale containers...")
-	if err := dockerManager.RemoveContainer(containerName); err == nil {
+	reentering := dockerManager.IsRunning(containerName)
+	if reentering {
+		if ephemeral {
+			// It may have the volume mounted; don't hand it out as ephemeral
+			return fmt.Errorf("container %s is already running; run 'capsule stop' before starting an ephemeral session", containerName)
+		}
+		fmt.Println("Container already running, re-entering.")
+		// A long-running container's clock can drift after the host sleeps
+		if skew, err := dockerManager.ClockSkew(containerName); err == nil && (skew > docker.ClockSkewThreshold || skew < -docker.ClockSkewThreshold) {
+			fmt.Fprintf(os.Stderr, "Warning: container clock is off by %v; run 'capsule stop' and start again if authentication fails.\n", skew)
+		}
+	} else if err := dockerManager.RemoveContainer(containerName); err == nil {
 		fmt.Println("Removed stale container.")
 		time.Sleep(docker.MountReleaseDelay)
 	}
 
-	// Check if volume is already mounted (reuse existing mount for fast re-entry)
+	// Undo setup on any failure before the shell opens; discarded once it succeeds
+	setupCleanup := &lifecycle.Cleanup{}
+	defer func() {
+		if err := setupCleanup.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: cleanup incomplete: %v\n", err)
+		}
+	}()
+
 	var mountPoint string
 	var password *terminal.SecurePassword
-	if existingMount := volumeManager.GetMountPoint(volumePath); existingMount != "" {
-		fmt.Printf("Volume already mounted at %s\n", existingMount)
-		mountPoint = existingMount
-	} else {
-		// Prompt for password only when we need to mount
-		password, err = terminal.ReadPasswordSecure("Enter volume password: ")
-		if err != nil {
-			return fmt.Errorf("password error: %w", err)
+	if !ephemeral {
+		// Check if volume is already mounted (reuse existing mount for fast re-entry)
+		if existingMount := volumeManager.GetMountPoint(volumePath); existingMount != "" {
+			fmt.Printf("Volume already mounted at %s\n", existingMount)
+			mountPoint = existingMount
+		} else {
+			// Prompt for password only when we need to mount
+			password, err = terminal.ReadPasswordFromSources(passwordSources, "Enter volume password: ")
+			if err != nil {
+				return fmt.Errorf("password error: %w", err)
+			}
+			defer password.Clear()
+
+			// Mount volume, showing that a slow attach is still working
+			fmt.Println("Mounting encrypted volume...")
+			events := make(chan volume.MountEvent)
+			printed := make(chan struct{})
+			endMount := dockerManager.TrackPhase(docker.PhaseMount, containerName, repoID)
+			go func() {
+				defer close(printed)
+				for e := range events {
+					dockerManager.PublishProgress(docker.PhaseMount, containerName, repoID, string(e.Phase))
+					if e.Phase == volume.PhaseAttaching && e.Elapsed >= time.Second {
+						fmt.Printf("Still unlocking volume (%v)...\n", e.Elapsed.Round(time.Second))
+					}
+				}
+			}()
+			mountPoint, err = volumeManager.MountWithProgress(volumePath, password, volume.MountProgressOptions{RepoID: repoID}, events)
+			<-printed
+			endMount(&err)
+			if err != nil {
+				return fmt.Errorf("failed to mount volume: %w", err)
+			}
+			if err := volume.WaitMounted(mountPoint, volume.DefaultMountWaitTimeout); err != nil {
+				return err
+			}
+			fmt.Printf("Volume mounted at %s\n", mountPoint)
 		}
-		defer password.Clear()
 
-		// Mount volume
-		fmt.Println("Mounting encrypted volume...")
-		mountPoint, err = volumeManager.Mount(volumePath, password)
-		if err != nil {
-			return fmt.Errorf("failed to mount volume: %w", err)
+		// mountPoint may change if the start below has to remount
+		setupCleanup.Push("unmount volume", func() (err error) {
+			if mountPoint == "" {
+				return nil // A failed remount left nothing mounted
+			}
+			defer dockerManager.TrackPhase(docker.PhaseUnmount, containerName, repoID)(&err)
+			events := make(chan volume.MountEvent)
+			go func() {
+				for e := range events {
+					dockerManager.PublishProgress(docker.PhaseUnmount, containerName, repoID, string(e.Phase))
+				}
+			}()
+			return volumeManager.UnmountWithProgress(mountPoint, events)
+		})
+
+		// Volumes created outside capsule (or by older versions) may lack home and repos
+		if err := volume.InitVolumeLayout(mountPoint); err != nil {
+			return fmt.Errorf("failed to initialize volume layout: %w", err)
 		}
-		fmt.Printf("Volume mounted at %s\n", mountPoint)
-	}
 
-	// Setup shutdown handler to lock volume on crash/termination
-	// This ensures the volume is secured if the process is killed unexpectedly
-	cancelShutdown := setupShutdownHandler(createShutdownCleanup(volumePath, containerName))
-	defer cancelShutdown()
+		// Setup shutdown handler to lock volume on crash/termination
+		// This ensures the volume is secured if the process is killed unexpectedly
+		cancelShutdown := setupShutdownHandler(createShutdownCleanup(volumePath, containerName))
+		defer cancelShutdown()
+
+		// Clear VM cache and refresh Docker's VirtioFS view of the mount point
+		// This is necessary because Docker Desktop caches mount information,
+		// and freshly mounted volumes may not be visible without cache clearing
+		fmt.Println("Preparing Docker mount...")
+		if err := dockerManager.ClearVMCache(); err != nil {
+			// Non-fatal: log warning but continue
+			fmt.Fprintf(os.Stderr, "Warning: failed to clear VM cache: %v\n", err)
+		}
+		if err := dockerManager.RefreshMountCache(mountPoint); err != nil {
+			// Non-fatal: if refresh fails, the actual mount will report a clearer error
+			fmt.Fprintf(os.Stderr, "Warning: cache refresh failed (will retry on mount): %v\n", err)
+		}
+	}
 
-	// Clear VM cache and refresh Docker's VirtioFS view of the mount point
-	// This is necessary because Docker Desktop caches mount information,
-	// and freshly mounted volumes may not be visible without cache clearing
-	fmt.Println("Preparing Docker mount...")
-	if err := dockerManager.ClearVMCache(); err != nil {
-		// Non-fatal: log warning but continue
-		fmt.Fprintf(os.Stderr, "Warning: failed to clear VM cache: %v\n", err)
+	// Bind mounts from network and FUSE filesystems can silently lose writes
+	for _, path := range []string{workspacePath, mountPoint} {
+		if path == "" {
+			continue
+		}
+		var fsErr *platform.UnsupportedFilesystemError
+		if err := platform.CheckBindMountFilesystem(path); errors.As(err, &fsErr) {
+			if strictFilesystem {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
 	}
-	if err := dockerManager.RefreshMountCache(mountPoint); err != nil {
-		// Non-fatal: if refresh fails, the actual mount will report a clearer error
-		fmt.Fprintf(os.Stderr, "Warning: cache refresh failed (will retry on mount): %v\n", err)
+
+	// Repo IDs used to keep the remote's case; move docs stored under the old ID
+	if !ephemeral {
+		if legacyID, err := volume.AdoptLegacyCaseRepoDocs(mountPoint, repoID); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else if legacyID != "" {
+			fmt.Printf("Moved docs from repos/%s to repos/%s.\n", legacyID, repoID)
+		}
+		// Directories outside git used to be identified by their name alone
+		if repoID == repo.PathDerivedID(workspacePath) {
+			legacyID := repo.LegacyPathDerivedID(workspacePath)
+			if moved, err := volume.AdoptLegacyRepoDocs(mountPoint, legacyID, repoID); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			} else if moved {
+				fmt.Printf("Moved docs from repos/%s to repos/%s.\n", legacyID, repoID)
+			}
+		}
 	}
 
 	// Start container with retry on Docker mount cache errors
@@ -455,10 +716,17 @@ func runStart(cmd *cobra.Command, args []string) error {
 		ContainerName:    containerName,
 		VolumeMountPoint: mountPoint,
 		WorkspacePath:    workspacePath,
+		RepoID:           repoID,
+		Ephemeral:        ephemeral,
+		MirrorHostPath:   mirrorPath,
+	}
+	for _, command := range postStart {
+		containerConfig.PostStartCommands = append(containerConfig.PostStartCommands, []string{"sh", "-c", command})
 	}
 
+	printStartWarnings(dockerManager, containerConfig)
 	startErr := dockerManager.Start(containerConfig)
-	if startErr != nil && strings.Contains(startErr.Error(), "file exists") {
+	if startErr != nil && !ephemeral && strings.Contains(startErr.Error(), "file exists") {
 		// Docker Desktop has stale mount cache - clean up and retry
 		fmt.Println("Docker mount cache conflict detected, cleaning up...")
 
@@ -478,7 +746,7 @@ func runStart(cmd *cobra.Command, args []string) error {
 
 		// If we didn't have a password (volume was pre-mounted), prompt now
 		if password == nil {
-			password, err = terminal.ReadPasswordSecure("Enter volume password to remount: ")
+			password, err = terminal.ReadPasswordFromSources(passwordSources, "Enter volume password to remount: ")
 			if err != nil {
 				return fmt.Errorf("password error: %w", err)
 			}
@@ -487,10 +755,13 @@ func runStart(cmd *cobra.Command, args []string) error {
 
 		// Remount
 		fmt.Println("Remounting volume...")
-		mountPoint, err = volumeManager.Mount(volumePath, password)
+		mountPoint, err = volumeManager.MountForRepo(volumePath, repoID, password)
 		if err != nil {
 			return fmt.Errorf("failed to remount volume after cleanup: %w", err)
 		}
+		if err := volume.WaitMounted(mountPoint, volume.DefaultMountWaitTimeout); err != nil {
+			return err
+		}
 
 		// Update config with new mount point
 		containerConfig.VolumeMountPoint = mountPoint
@@ -500,53 +771,110 @@ func runStart(cmd *cobra.Command, args []string) error {
 		startErr = dockerManager.Start(containerConfig)
 	}
 
+	var driftErr *docker.ConfigDriftError
+	if errors.As(startErr, &driftErr) {
+		// The warm container is still in use; leave it and its volume alone
+		setupCleanup.Discard()
+		for _, d := range driftErr.Drift {
+			fmt.Fprintf(os.Stderr, "  %s\n", d)
+		}
+		return startErr
+	}
 	if startErr != nil {
 		// Clean up any partially created container before returning error
 		fmt.Println("Cleaning up failed container...")
-		if err := dockerManager.RemoveContainer(containerName); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: container removal failed: %v\n", err)
-		}
-
-		if unmountErr := volumeManager.Unmount(mountPoint); unmountErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: volume unmount failed: %v\n", unmountErr)
-		}
+		setupCleanup.Push("remove container", func() error { return dockerManager.RemoveContainer(containerName) })
 		return fmt.Errorf("failed to start container: %w", startErr)
 	}
 	fmt.Println("Container started!")
+	setupCleanup.Push("stop container", func() error { return dockerManager.Stop(containerName) })
+
+	// Record which remote owns repos/<repoID>, warning if another already does
+	if !ephemeral {
+		if _, remote, err := repo.IdentifyWorkspace(workspacePath); err == nil && remote != "" {
+			remote = repo.NormalizeRemoteURL(remote)
+			if inUse, existing, err := volume.RepoIDInUse(mountPoint, repoID, remote); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not check repo ID %s: %v\n", repoID, err)
+			} else if inUse {
+				fmt.Fprintf(os.Stderr, "Warning: docs for %s were created for remote %s, not %s\n", repoID, existing, remote)
+			} else if err := volume.WriteRepoRemote(mountPoint, repoID, remote); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not record remote for %s: %v\n", repoID, err)
+			}
+		}
+	}
 
 	// Setup symlink inside container
 	fmt.Println("Setting up shadow documentation...")
-	if err := dockerManager.SetupWorkspaceSymlink(containerName, repoID); err != nil {
-		// Clean up on failure
-		if stopErr := dockerManager.Stop(containerName); stopErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: cleanup failed to stop container: %v\n", stopErr)
+	if err := dockerManager.SetupWorkspaceSymlinks(containerConfig); err != nil {
+		return fmt.Errorf("failed to setup workspace symlink: %w", err)
+	}
+	// A re-entered container already ran its setup commands
+	if !reentering && len(containerConfig.PostStartCommands) > 0 {
+		fmt.Println("Running post-start commands...")
+		if err := dockerManager.RunPostStart(containerConfig); err != nil {
+			return err
 		}
-		if unmountErr := volumeManager.Unmount(mountPoint); unmountErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: cleanup failed to unmount volume: %v\n", unmountErr)
+	}
+	if !ephemeral {
+		if err := volume.TouchRepoAccess(mountPoint, repoID); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not record access time for %s: %v\n", repoID, err)
+		}
+		// Without a persistent HOME, logins and credentials are lost with the container
+		if err := dockerManager.VerifyHome(containerName); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v; credentials will not persist between sessions\n", err)
 		}
-		return fmt.Errorf("failed to setup workspace symlink: %w", err)
 	}
+	setupCleanup.Discard()
 	fmt.Println("")
 	fmt.Println("Entering container... (type 'exit' to leave)")
 	fmt.Println("")
 
-	// Exec into container and wait for user to exit
-	execErr := dockerManager.Exec(containerName)
+	// Session cleanup runs when the shell exits or capsule is interrupted.
+	// The volume stays mounted for fast re-entry.
+	sessionCleanup := &lifecycle.Cleanup{}
+	if !keepRunning {
+		sessionCleanup.Push("stop container", func() error {
+			fmt.Println("Cleaning up...")
+			if err := dockerManager.Stop(containerName); err != nil {
+				return err
+			}
+			fmt.Println("Container stopped.")
+			return nil
+		})
+	}
+	runSessionCleanup := func() {
+		if err := sessionCleanup.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	// Exec into container and wait for user to exit, tracking peak usage
+	peaksCtx, stopPeaks := context.WithCancel(context.Background())
+	peaksDone := make(chan *docker.Peaks, 1)
+	go func() {
+		peaks, _ := dockerManager.TrackPeaks(peaksCtx, containerName)
+		peaksDone <- peaks
+	}()
+	execErr := dockerManager.ExecWithCleanup(containerName, "", runSessionCleanup)
+	stopPeaks()
 
-	// Clean up after user exits the shell
 	fmt.Println("")
-	fmt.Println("Cleaning up...")
+	if peaks := <-peaksDone; peaks != nil && peaks.Samples > 0 {
+		fmt.Printf("Session peak memory %.1fGiB, peak CPU %.0f%%\n",
+			float64(peaks.MemoryUsage)/(1<<30), peaks.CPUPercent)
+	}
+	if keepRunning {
+		fmt.Printf("Container %s left running. Run 'capsule stop' to stop it.\n", containerName)
+	}
+	runSessionCleanup()
 
-	// Stop container (keep volume mounted for fast re-entry)
-	if err := dockerManager.Stop(containerName); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to stop container: %v\n", err)
+	if ephemeral {
+		fmt.Println("Ephemeral session: nothing is kept once the container stops.")
 	} else {
-		fmt.Println("Container stopped.")
+		fmt.Println("Volume remains unlocked for quick re-entry.")
+		fmt.Println("Run 'capsule lock' when done to secure your credentials.")
 	}
 
-	fmt.Println("Volume remains unlocked for quick re-entry.")
-	fmt.Println("Run 'capsule lock' when done to secure your credentials.")
-
 	// Ignore common exit codes (0 = normal, 130 = Ctrl+C)
 	if execErr != nil {
 		if exitErr, ok := execErr.(*exec.ExitError); ok {
@@ -569,6 +897,139 @@ func newStopCmd() *cobra.Command {
 	}
 }
 
+func newReconcileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Mount, start, and link whatever is missing, after confirmation",
+		Long: `Compares the environment with a fully running capsule (volume mounted,
+container running, _docs symlink in place) and shows the steps needed to get
+there. Nothing is changed until you confirm; pass --yes to skip the prompt,
+which is required when stdin is not a terminal. Nothing is ever torn down.`,
+		RunE: runReconcile,
+	}
+
+	cmd.Flags().String("volume", "", "Path to encrypted volume (auto-detected if not specified)")
+	cmd.Flags().Bool("yes", false, "Apply the plan without asking for confirmation")
+	cmd.Flags().Bool("password-stdin", false, "Read password from stdin instead of terminal prompt")
+	cmd.Flags().String("password-file", "", "Read password from a file (must be mode 0600 or stricter)")
+
+	return cmd
+}
+
+func runReconcile(cmd *cobra.Command, args []string) error {
+	volumePathFlag, err := cmd.Flags().GetString("volume")
+	if err != nil {
+		return fmt.Errorf("invalid volume flag: %w", err)
+	}
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return fmt.Errorf("invalid yes flag: %w", err)
+	}
+	passwordSources, err := passwordSourcesFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	pathResolver, err := newPathResolver(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to create path resolver: %w", err)
+	}
+	volumePath, err := pathResolver.ResolveVolumePathStrict(volumePathFlag, cwd)
+	if err != nil {
+		return err
+	}
+
+	repoIdentifier := repo.NewIdentifier()
+	workspacePath, err := repoIdentifier.GetWorkspaceRoot(cwd)
+	if err != nil {
+		return fmt.Errorf("failed to determine workspace root: %w", err)
+	}
+	repoID, err := repoIdentifier.GetRepoID(workspacePath)
+	if err != nil {
+		return fmt.Errorf("failed to identify repository: %w", err)
+	}
+	containerName, err := repoIdentifier.GetContainerName(workspacePath)
+	if err != nil {
+		return fmt.Errorf("failed to generate container name: %w", err)
+	}
+	if containerName, err = docker.ApplyNamePrefix(containerName); err != nil {
+		return err
+	}
+
+	volumeManager, err := volume.New()
+	if err != nil {
+		return fmt.Errorf("failed to create volume manager: %w", err)
+	}
+	dockerManager := docker.NewManager()
+	if err := dockerManager.SetDocsLinkName(docsLinkName); err != nil {
+		return err
+	}
+
+	opts := lifecycle.ReconcileOptions{
+		Desired: lifecycle.DesiredState{SymlinkValid: true},
+		Config: docker.ContainerConfig{
+			ImageName:     docker.DefaultImageName,
+			ContainerName: containerName,
+			WorkspacePath: workspacePath,
+		},
+		RepoID:     repoID,
+		VolumePath: volumePath,
+		Docker:     dockerManager,
+		Volume:     volumeManager,
+	}
+
+	plan, err := lifecycle.Plan(opts)
+	if err != nil {
+		return err
+	}
+	if len(plan) == 0 {
+		fmt.Println("Nothing to do; the environment is up to date.")
+		return nil
+	}
+	fmt.Println("Planned steps:")
+	for _, action := range plan {
+		fmt.Printf("  - %s\n", action)
+	}
+	if !yes {
+		confirmed, err := terminal.PromptConfirm("Apply these steps?", false)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			if !terminal.IsTerminal() {
+				return fmt.Errorf("not applying the plan without confirmation; pass --yes to apply it non-interactively")
+			}
+			fmt.Println("Nothing changed.")
+			return nil
+		}
+	}
+
+	if slices.Contains(plan, lifecycle.ActionMountVolume) {
+		password, err := terminal.ReadPasswordFromSources(passwordSources, "Enter volume password: ")
+		if err != nil {
+			return fmt.Errorf("password error: %w", err)
+		}
+		defer password.Clear()
+		opts.Password = password
+	}
+
+	taken, err := lifecycle.Reconcile(opts)
+	for _, action := range taken {
+		fmt.Printf("Done: %s\n", action)
+	}
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(taken, plan) {
+		fmt.Fprintf(os.Stderr, "Warning: the environment changed after planning; ran %v instead of %v\n", taken, plan)
+	}
+	return nil
+}
+
 func newUnlockCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "unlock",
@@ -582,13 +1043,17 @@ Output is in KEY=VALUE format for easy parsing:
 
 Password can be provided via:
   - Interactive prompt (default)
+  - --password-file flag: a file readable only by its owner (chmod 600)
   - --password-stdin flag: echo $PASS | capsule unlock --password-stdin
-  - CAPSULE_PASSWORD environment variable`,
+  - CAPSULE_PASSWORD environment variable
+
+Sources are tried in that order: file, stdin, environment, prompt.`,
 		RunE: runUnlock,
 	}
 
 	cmd.Flags().String("volume", "", "Path to encrypted volume (auto-detected if not specified)")
 	cmd.Flags().Bool("password-stdin", false, "Read password from stdin instead of terminal prompt")
+	cmd.Flags().String("password-file", "", "Read password from a file (must be mode 0600 or stricter)")
 
 	return cmd
 }
@@ -598,9 +1063,9 @@ func runUnlock(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("invalid volume flag: %w", err)
 	}
-	passwordStdin, err := cmd.Flags().GetBool("password-stdin")
+	passwordSources, err := passwordSourcesFromFlags(cmd)
 	if err != nil {
-		return fmt.Errorf("invalid password-stdin flag: %w", err)
+		return err
 	}
 
 	// Get current directory
@@ -616,7 +1081,7 @@ func runUnlock(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create path resolver
-	pathResolver, err := volume.NewPathResolver()
+	pathResolver, err := newPathResolver(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to create path resolver: %w", err)
 	}
@@ -637,7 +1102,7 @@ func runUnlock(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get password from multiple sources
-	password, err := terminal.ReadPasswordMultiSourceSecure(passwordStdin, "Enter volume password: ")
+	password, err := terminal.ReadPasswordFromSources(passwordSources, "Enter volume password: ")
 	if err != nil {
 		return fmt.Errorf("password error: %w", err)
 	}
@@ -695,13 +1160,16 @@ func runLock(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create path resolver
-	pathResolver, err := volume.NewPathResolver()
+	pathResolver, err := newPathResolver(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to create path resolver: %w", err)
 	}
 
 	// Find volume path using priority rules (allow non-existent for status reporting)
-	volumePath, _ := pathResolver.ResolveVolumePath(volumePathFlag, cwd)
+	volumePath, _, err := pathResolver.ResolveVolumePath(volumePathFlag, cwd)
+	if err != nil {
+		return err
+	}
 
 	// Get the mount point for this specific volume (not any volume)
 	mountPoint := volumeManager.GetMountPoint(volumePath)
@@ -782,16 +1250,23 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create path resolver
-	pathResolver, err := volume.NewPathResolver()
+	pathResolver, err := newPathResolver(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to create path resolver: %w", err)
 	}
 
 	// Find volume path using priority rules (allow non-existent for status display)
-	volumePath, _ := pathResolver.ResolveVolumePath(volumePathFlag, cwd)
+	volumePath, _, err := pathResolver.ResolveVolumePath(volumePathFlag, cwd)
+	if err != nil {
+		return err
+	}
 
 	// Create detector
 	detector := state.NewDetector(volumePath, containerName, cwd)
+	detector.SetDocsLinkName(docsLinkName)
+	if repoID, err := repo.NewIdentifier().GetRepoID(cwd); err == nil {
+		detector.SetExpectedTarget(repoID, "")
+	}
 	envState := detector.Detect()
 
 	// Display status
@@ -807,7 +1282,9 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	// Mount status
-	if envState.VolumeMounted {
+	if envState.VolumeStale {
+		fmt.Printf("Mounted:    Stale (%s) - run 'capsule lock' then 'capsule start'\n", envState.MountPoint)
+	} else if envState.VolumeMounted {
 		fmt.Printf("Mounted:    Yes (%s)\n", envState.MountPoint)
 	} else {
 		fmt.Println("Mounted:    No")
@@ -824,7 +1301,9 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 	// Symlink status
 	if envState.SymlinkExists {
-		if envState.SymlinkBroken {
+		if envState.SymlinkTarget != "" && !envState.SymlinkTargetCorrect {
+			fmt.Printf("Symlink:    Stale (%s -> %s)\n", envState.SymlinkPath, envState.SymlinkTarget)
+		} else if envState.SymlinkBroken {
 			fmt.Printf("Symlink:    Broken (%s)\n", envState.SymlinkPath)
 		} else {
 			fmt.Printf("Symlink:    Active (%s)\n", envState.SymlinkPath)
@@ -872,7 +1351,7 @@ func runBuildImage(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("Building Docker image '%s'...\n", docker.DefaultImageName)
-	if err := embedded.BuildImage(docker.DefaultImageName); err != nil {
+	if err := embedded.BuildImage(docker.DefaultImageName, docker.ResolvePlatform(docker.ContainerConfig{}), nil, nil); err != nil {
 		return fmt.Errorf("failed to build image: %w", err)
 	}
 
@@ -0,0 +1,187 @@
+// Package audit records capsule operations as JSON lines and reads them back.
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// followPollInterval is how often FollowAuditLog checks for new lines.
+const followPollInterval = 500 * time.Millisecond
+
+// AuditEntry is one line of the audit log.
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	Action    string    `json:"action"`
+	RepoID    string    `json:"repo_id,omitempty"`
+	Container string    `json:"container,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+}
+
+// AuditFilter selects entries in ReadAuditLog. Zero fields match everything.
+type AuditFilter struct {
+	Action string
+	RepoID string
+	Since  time.Time // Inclusive
+	Until  time.Time // Exclusive
+}
+
+// Match reports whether an entry passes the filter.
+func (f AuditFilter) Match(e AuditEntry) bool {
+	if f.Action != "" && e.Action != f.Action {
+		return false
+	}
+	if f.RepoID != "" && e.RepoID != f.RepoID {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Time.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// MalformedLinesError is returned by ReadAuditLog alongside the entries that
+// could be decoded when some lines could not.
+type MalformedLinesError struct {
+	Path  string
+	Count int
+}
+
+func (e *MalformedLinesError) Error() string {
+	return fmt.Sprintf("skipped %d malformed lines in %s", e.Count, e.Path)
+}
+
+// Append writes an entry to the audit log at path, creating it if needed.
+// A zero Time is set to now.
+func Append(path string, entry AuditEntry) error {
+	if entry.Action == "" {
+		return fmt.Errorf("audit action is required")
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, constants.FilePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// ReadAuditLog decodes the audit log at path and returns the entries matching
+// filter, oldest first. Lines that cannot be decoded are skipped; if there were
+// any, the matching entries are returned together with a *MalformedLinesError.
+func ReadAuditLog(path string, filter AuditFilter) ([]AuditEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	malformed := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		entry, ok := decodeEntry(line)
+		if !ok {
+			malformed++
+			continue
+		}
+		if filter.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	if malformed > 0 {
+		return entries, &MalformedLinesError{Path: path, Count: malformed}
+	}
+	return entries, nil
+}
+
+// FollowAuditLog streams entries appended to the audit log at path after the
+// call, like `tail -f`. Malformed lines are skipped. The channel is closed when
+// ctx is done or the file can no longer be read.
+func FollowAuditLog(ctx context.Context, path string) (<-chan AuditEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to seek audit log: %w", err)
+	}
+
+	entries := make(chan AuditEntry)
+	go func() {
+		defer close(entries)
+		defer f.Close()
+
+		reader := bufio.NewReader(f)
+		var partial string
+		for {
+			line, err := reader.ReadString('\n')
+			partial += line
+			if err == io.EOF {
+				// Wait for the writer to finish the line
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(followPollInterval):
+				}
+				continue
+			}
+			if err != nil {
+				return
+			}
+
+			entry, ok := decodeEntry(strings.TrimSpace(partial))
+			partial = ""
+			if !ok {
+				continue
+			}
+			select {
+			case entries <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return entries, nil
+}
+
+// decodeEntry parses one audit log line.
+func decodeEntry(line string) (AuditEntry, bool) {
+	var entry AuditEntry
+	if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Action == "" {
+		return AuditEntry{}, false
+	}
+	return entry, true
+}
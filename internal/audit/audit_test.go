@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, e := range []AuditEntry{
+		{Action: "start", RepoID: "repo-a"},
+		{Action: "stop", RepoID: "repo-a"},
+		{Action: "start", RepoID: "repo-b"},
+	} {
+		e.Time = start.Add(time.Duration(i) * time.Hour)
+		if err := Append(path, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("not json\n")
+	f.Close()
+
+	entries, err := ReadAuditLog(path, AuditFilter{Action: "start"})
+	var malformed *MalformedLinesError
+	if !errors.As(err, &malformed) || malformed.Count != 1 {
+		t.Errorf("ReadAuditLog() error = %v, want 1 malformed line", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("ReadAuditLog(action=start) = %v, want 2 entries", entries)
+	}
+
+	entries, _ = ReadAuditLog(path, AuditFilter{RepoID: "repo-a", Since: start.Add(time.Hour)})
+	if len(entries) != 1 || entries[0].Action != "stop" {
+		t.Errorf("ReadAuditLog(repo-a since +1h) = %v, want the stop entry", entries)
+	}
+}
+
+func TestFollowAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	if err := Append(path, AuditEntry{Action: "old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	entries, err := FollowAuditLog(ctx, path)
+	if err != nil {
+		t.Fatalf("FollowAuditLog() error = %v", err)
+	}
+
+	if err := Append(path, AuditEntry{Action: "new"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-entries:
+		if e.Action != "new" {
+			t.Errorf("first followed entry = %q, want new", e.Action)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for followed entry")
+	}
+}
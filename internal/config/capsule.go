@@ -0,0 +1,151 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+	"github.com/jeanhaley32/claude-capsule/internal/docker"
+	"github.com/jeanhaley32/claude-capsule/internal/volume"
+)
+
+// validationMountPoint stands in for the volume mount point during Validate.
+const validationMountPoint = "/Volumes/" + constants.MacOSVolumeName
+
+// Config is the resolved configuration for a capsule session.
+type Config struct {
+	// VolumePath is the encrypted volume image on the host.
+	VolumePath string
+	// VolumeSizeGB is the size used when bootstrapping the volume.
+	VolumeSizeGB int
+
+	// WorkspacePath is the host directory mounted at /workspace.
+	WorkspacePath string
+	// RepoID identifies the repository in the workspace.
+	RepoID string
+
+	// ImageName defaults to docker.DefaultImageName.
+	ImageName string
+	// ContainerName defaults to docker.GenerateContainerName(RepoID).
+	ContainerName string
+
+	// ExtraMounts are additional bind mounts passed through to the container.
+	ExtraMounts []docker.ExtraMount
+	// ExtraArgs are extra `docker run` flags, e.g. resource limits such as --memory.
+	ExtraArgs []string
+
+	// PostStartCommands run in the container after it starts, e.g. to install
+	// dependencies. See docker.Manager.RunPostStart.
+	PostStartCommands [][]string
+
+	// Profiles are named overrides; RepoProfiles selects one by repo ID. See Resolve.
+	Profiles     map[string]Profile
+	RepoProfiles []RepoProfile
+}
+
+// EffectiveImageName returns the configured image or the default.
+func (c *Config) EffectiveImageName() string {
+	if c.ImageName == "" {
+		return docker.DefaultImageName
+	}
+	return c.ImageName
+}
+
+// EffectiveContainerName returns the configured container name, or one derived from RepoID.
+func (c *Config) EffectiveContainerName() string {
+	if c.ContainerName == "" {
+		return docker.GenerateContainerName(c.RepoID)
+	}
+	return c.ContainerName
+}
+
+// ContainerConfig builds the container configuration for a volume mounted at
+// mountPoint. The repo's profile is resolved first, so the container gets the
+// effective image, mounts, and arguments.
+func (c *Config) ContainerConfig(mountPoint string) (docker.ContainerConfig, error) {
+	resolved, err := c.Resolve()
+	if err != nil {
+		return docker.ContainerConfig{}, err
+	}
+	return resolved.containerConfig(mountPoint), nil
+}
+
+// containerConfig builds the container configuration from the config as is,
+// without resolving profiles.
+func (c *Config) containerConfig(mountPoint string) docker.ContainerConfig {
+	return docker.ContainerConfig{
+		ImageName:         c.EffectiveImageName(),
+		ContainerName:     c.EffectiveContainerName(),
+		VolumeMountPoint:  mountPoint,
+		WorkspacePath:     c.WorkspacePath,
+		RepoID:            c.RepoID,
+		ExtraMounts:       c.ExtraMounts,
+		ExtraArgs:         c.ExtraArgs,
+		PostStartCommands: c.PostStartCommands,
+	}
+}
+
+// Validate checks every field and the relationships between them, returning
+// all problems at once as a joined error. Call it before any capsule operation.
+// Profiles are resolved first, so the checks apply to the effective values.
+func (c *Config) Validate() error {
+	var errs []error
+
+	resolved, err := c.Resolve()
+	if err != nil {
+		errs = append(errs, err)
+		resolved = *c
+	}
+	c = &resolved
+
+	if c.VolumePath == "" || !filepath.IsAbs(c.VolumePath) {
+		errs = append(errs, fmt.Errorf("volume path must be an absolute path: %q", c.VolumePath))
+	}
+	if c.VolumeSizeGB < constants.MinVolumeSizeGB || c.VolumeSizeGB > constants.MaxVolumeSizeGB {
+		errs = append(errs, fmt.Errorf("volume size %dGB out of range (%d-%d)",
+			c.VolumeSizeGB, constants.MinVolumeSizeGB, constants.MaxVolumeSizeGB))
+	}
+
+	// The workspace must not be nested in the volume: neither inside the image
+	// itself (a sparse bundle is a directory) nor inside a mounted volume. The
+	// volume may live in the workspace, as the local ./capsule.sparseimage does
+	if c.VolumePath != "" && c.WorkspacePath != "" {
+		workspace := filepath.Clean(c.WorkspacePath)
+		if isWithin(workspace, c.VolumePath) {
+			errs = append(errs, fmt.Errorf("workspace %s is inside volume %s", workspace, c.VolumePath))
+		}
+		if mountPoint := capsuleMountContaining(workspace); mountPoint != "" {
+			errs = append(errs, fmt.Errorf("workspace %s is inside volume mount point %s", workspace, mountPoint))
+		}
+	}
+
+	// Checks the derived container name, image, workspace, and extra mounts.
+	// The real mount point is only known after mounting, so a placeholder is used
+	containerConfig := c.containerConfig(validationMountPoint)
+	if err := containerConfig.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// capsuleMountContaining returns the capsule mount point that p is or lies
+// beneath, or "" if there is none.
+func capsuleMountContaining(p string) string {
+	for dir := filepath.Clean(p); ; dir = filepath.Dir(dir) {
+		if volume.IsCapsuleMount(dir) {
+			return dir
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return ""
+		}
+	}
+}
+
+// isWithin reports whether p is dir or a path beneath it.
+func isWithin(p, dir string) bool {
+	p, dir = filepath.Clean(p), filepath.Clean(dir)
+	return p == dir || strings.HasPrefix(p, dir+string(filepath.Separator))
+}
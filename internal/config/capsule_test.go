@@ -0,0 +1,112 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jeanhaley32/claude-capsule/internal/docker"
+)
+
+func validConfig() Config {
+	return Config{
+		VolumePath:    "/Users/me/.capsule/volumes/capsule.sparseimage",
+		VolumeSizeGB:  2,
+		WorkspacePath: "/Users/me/src/project",
+		RepoID:        "github.com-me-project",
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Setenv(docker.NamePrefixEnv, "")
+	cfg := validConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := cfg.EffectiveContainerName(); got != "claude-capsule-github.com-me-project" {
+		t.Errorf("EffectiveContainerName() = %q", got)
+	}
+}
+
+func TestConfigValidate_ReportsAllProblems(t *testing.T) {
+	cfg := validConfig()
+	cfg.VolumeSizeGB = 0
+	cfg.WorkspacePath = "/Volumes/Capsule-github.com-me-project/work"
+	cfg.ExtraMounts = []docker.ExtraMount{{HostPath: "/data", ContainerPath: "/claude-env/data"}}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil, want error")
+	}
+	for _, want := range []string{"volume size", "inside volume mount point", "overlaps"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %q, want it to mention %q", err, want)
+		}
+	}
+}
+
+func TestConfigValidate_VolumeInsideWorkspace(t *testing.T) {
+	// The local volume layout keeps ./capsule.sparseimage in the workspace
+	cfg := validConfig()
+	cfg.VolumePath = "/Users/me/src/project/capsule.sparseimage"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with a local volume error = %v", err)
+	}
+}
+
+func TestConfigValidate_WorkspaceInsideVolume(t *testing.T) {
+	cfg := validConfig()
+	cfg.VolumePath = "/Users/me/.capsule/volumes/capsule.sparsebundle"
+	cfg.WorkspacePath = "/Users/me/.capsule/volumes/capsule.sparsebundle/bands"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "inside volume /Users") {
+		t.Errorf("Validate() error = %v, want workspace inside volume", err)
+	}
+
+	cfg = validConfig()
+	cfg.WorkspacePath = "/Volumes/Capsule-abc/repos/project"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "inside volume mount point /Volumes/Capsule-abc") {
+		t.Errorf("Validate() error = %v, want workspace inside mount point", err)
+	}
+}
+
+func TestConfigResolve_Profiles(t *testing.T) {
+	cfg := validConfig()
+	cfg.ExtraArgs = []string{"--cpus", "2"}
+	cfg.Profiles = map[string]Profile{
+		"large": {ExtraArgs: []string{"--memory", "8g"}},
+		"rust":  {Inherits: "large", ImageName: "capsule-rust:latest"},
+	}
+	cfg.RepoProfiles = []RepoProfile{
+		{Match: "github.com-me-docs", Profile: "small"},
+		{Match: "github.com-me-*", Profile: "rust"},
+	}
+
+	resolved, err := cfg.Resolve()
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if resolved.ImageName != "capsule-rust:latest" {
+		t.Errorf("ImageName = %q, want profile image", resolved.ImageName)
+	}
+	if got := strings.Join(resolved.ExtraArgs, " "); got != "--cpus 2 --memory 8g" {
+		t.Errorf("ExtraArgs = %q, want base then inherited profile args", got)
+	}
+
+	cc, err := cfg.ContainerConfig("/Volumes/Capsule-abc")
+	if err != nil {
+		t.Fatalf("ContainerConfig() error = %v", err)
+	}
+	if cc.ImageName != "capsule-rust:latest" {
+		t.Errorf("ContainerConfig().ImageName = %q, want profile image", cc.ImageName)
+	}
+	if got := strings.Join(cc.ExtraArgs, " "); got != "--cpus 2 --memory 8g" {
+		t.Errorf("ContainerConfig().ExtraArgs = %q, want profile args applied once", got)
+	}
+
+	cfg.RepoID = "github.com-me-docs"
+	if _, err := cfg.ContainerConfig("/Volumes/Capsule-abc"); err == nil {
+		t.Error("ContainerConfig() with unknown profile succeeded, want error")
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "unknown profile") {
+		t.Errorf("Validate() error = %v, want unknown profile", err)
+	}
+}
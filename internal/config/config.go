@@ -1,13 +1,7 @@
 package config
 
+import "github.com/jeanhaley32/claude-capsule/internal/constants"
+
 // VolumeStructure defines the directory structure inside the encrypted volume.
-var VolumeStructure = []string{
-	"auth",                // API keys, authentication tokens
-	"config",              // User preferences, Claude Code settings
-	"claude-context",      // .claude conversation history
-	"bootstrap",           // Templates and starting files
-	"repos",               // Per-repository documentation and context
-	"home",                // User home directory (persists Claude credentials, shell history, etc.)
-	"home/.claude",        // Claude Code configuration directory
-	"home/.claude/skills", // Skills directory for doc-sync and other extensions
-}
+// It is constants.VolumeStructure, kept here for existing callers.
+var VolumeStructure = constants.VolumeStructure
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// DumpMount is one entry of the container mount layout in a dump.
+type DumpMount struct {
+	Source   string // Host path; the volume is listed by its image path
+	Target   string // Container path
+	ReadOnly bool   `json:",omitempty"`
+}
+
+// dump is the serialized form written by Dump.
+type dump struct {
+	Config
+	Mounts []DumpMount
+}
+
+// Dump returns the effective configuration as indented JSON: profiles are
+// resolved and then dropped, and the derived image and container names are
+// filled in. The container mount layout is included for reference and ignored
+// by ParseDump, so ParseDump(Dump()) yields a config with the same effective
+// settings.
+func (c *Config) Dump() ([]byte, error) {
+	resolved, err := c.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	resolved.ImageName = resolved.EffectiveImageName()
+	resolved.ContainerName = resolved.EffectiveContainerName()
+	resolved.Profiles = nil
+	resolved.RepoProfiles = nil
+
+	d := dump{Config: resolved}
+	d.Mounts = append(d.Mounts,
+		DumpMount{Source: resolved.VolumePath, Target: constants.ContainerVolumePath},
+		DumpMount{Source: resolved.WorkspacePath, Target: constants.ContainerWorkspacePath},
+	)
+	for _, m := range resolved.ExtraMounts {
+		d.Mounts = append(d.Mounts, DumpMount{Source: m.HostPath, Target: m.ContainerPath, ReadOnly: m.ReadOnly})
+	}
+
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// ParseDump reads a configuration written by Dump.
+func ParseDump(data []byte) (Config, error) {
+	var d dump
+	if err := json.Unmarshal(data, &d); err != nil {
+		return Config{}, fmt.Errorf("failed to parse config dump: %w", err)
+	}
+	return d.Config, nil
+}
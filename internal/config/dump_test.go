@@ -0,0 +1,47 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/jeanhaley32/claude-capsule/internal/docker"
+)
+
+func TestDump_RoundTrip(t *testing.T) {
+	cfg := validConfig()
+	cfg.ExtraArgs = []string{"--cpus", "2"}
+	cfg.Profiles = map[string]Profile{
+		"large": {ExtraArgs: []string{"--memory", "8g"}, ExtraMounts: []docker.ExtraMount{{HostPath: "/data", ContainerPath: "/data", ReadOnly: true}}},
+	}
+	cfg.RepoProfiles = []RepoProfile{{Match: "github.com-me-*", Profile: "large"}}
+
+	data, err := cfg.Dump()
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	loaded, err := ParseDump(data)
+	if err != nil {
+		t.Fatalf("ParseDump() error = %v", err)
+	}
+
+	want, err := cfg.ContainerConfig("/Volumes/Capsule-abc")
+	if err != nil {
+		t.Fatalf("ContainerConfig() error = %v", err)
+	}
+	got, err := loaded.ContainerConfig("/Volumes/Capsule-abc")
+	if err != nil {
+		t.Fatalf("ContainerConfig() after ParseDump error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round-tripped container config = %+v, want %+v", got, want)
+	}
+
+	again, err := loaded.Dump()
+	if err != nil {
+		t.Fatalf("Dump() after ParseDump error = %v", err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Errorf("second Dump() differs:\n%s\nwant:\n%s", again, data)
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/jeanhaley32/claude-capsule/internal/docker"
+)
+
+// Profile overrides container settings for a group of repositories.
+//
+// Precedence, highest first: the repo's profile, the profiles it inherits from,
+// the Config's own fields (the base), then built-in defaults. Scalar fields
+// replace the inherited value when set; ExtraMounts and ExtraArgs are appended.
+type Profile struct {
+	// Inherits names another profile to start from. Empty inherits the base.
+	Inherits string
+
+	ImageName   string
+	ExtraMounts []docker.ExtraMount
+	ExtraArgs   []string // e.g. {"--memory", "8g"}
+}
+
+// RepoProfile maps repository IDs to a profile.
+type RepoProfile struct {
+	// Match is a repo ID or a path.Match glob such as "github.com-acme-*".
+	Match   string
+	Profile string
+}
+
+// ProfileName returns the profile selected for the config's RepoID by the first
+// matching RepoProfiles entry, or "" if none matches.
+func (c *Config) ProfileName() (string, error) {
+	for _, rp := range c.RepoProfiles {
+		matched, err := path.Match(rp.Match, c.RepoID)
+		if err != nil {
+			return "", fmt.Errorf("invalid repo profile pattern %q: %w", rp.Match, err)
+		}
+		if matched {
+			return rp.Profile, nil
+		}
+	}
+	return "", nil
+}
+
+// Resolve returns a copy of the config with the repo's profile chain applied.
+// A config with no matching profile is returned unchanged.
+func (c *Config) Resolve() (Config, error) {
+	resolved := *c
+
+	name, err := c.ProfileName()
+	if err != nil || name == "" {
+		return resolved, err
+	}
+
+	// Collect the inheritance chain, most specific first
+	var chain []Profile
+	seen := map[string]bool{}
+	for name != "" {
+		if seen[name] {
+			return resolved, fmt.Errorf("profile %q inherits from itself", name)
+		}
+		seen[name] = true
+
+		profile, ok := c.Profiles[name]
+		if !ok {
+			return resolved, fmt.Errorf("unknown profile %q", name)
+		}
+		chain = append(chain, profile)
+		name = profile.Inherits
+	}
+
+	// Apply from the base outwards so the most specific profile wins
+	resolved.ExtraMounts = append([]docker.ExtraMount(nil), c.ExtraMounts...)
+	resolved.ExtraArgs = append([]string(nil), c.ExtraArgs...)
+	for i := len(chain) - 1; i >= 0; i-- {
+		profile := chain[i]
+		if profile.ImageName != "" {
+			resolved.ImageName = profile.ImageName
+		}
+		resolved.ExtraMounts = append(resolved.ExtraMounts, profile.ExtraMounts...)
+		resolved.ExtraArgs = append(resolved.ExtraArgs, profile.ExtraArgs...)
+	}
+	return resolved, nil
+}
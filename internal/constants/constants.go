@@ -1,6 +1,9 @@
 package constants
 
-import "os"
+import (
+	"os"
+	"time"
+)
 
 // Volume-related constants
 const (
@@ -15,12 +18,51 @@ const (
 
 	// VolumesSubdir is the subdirectory under CapsuleConfigDir for volumes.
 	VolumesSubdir = "volumes"
+
+	// LocksSubdir is the subdirectory under CapsuleConfigDir for operation lock files.
+	LocksSubdir = "locks"
+
+	// VerifiedImagesSubdir is the subdirectory under CapsuleConfigDir recording
+	// image IDs that passed the image contract check.
+	VerifiedImagesSubdir = "verified-images"
+
+	// VolumeLockFileName is the lock file in LocksSubdir held during volume
+	// attach, detach and compaction.
+	VolumeLockFileName = "volume.lock"
+
+	// ContainerLockPrefix starts each container's lock file in LocksSubdir,
+	// named <prefix><container>.lock, so no container name can collide with
+	// VolumeLockFileName.
+	ContainerLockPrefix = "container-"
 )
 
 // Shadow documentation constants
 const (
 	// DocsSymlinkName is the name of the shadow documentation directory.
 	DocsSymlinkName = "_docs"
+
+	// ReposDirName is the directory inside the volume holding per-repo documentation.
+	ReposDirName = "repos"
+
+	// RepoRemoteMarkerName is the file inside repos/<repoID> recording the
+	// normalized remote the directory was created for.
+	RepoRemoteMarkerName = ".capsule-remote"
+
+	// RepoAccessMarkerName is the file inside repos/<repoID> whose modification
+	// time records when the repo was last used in a session.
+	RepoAccessMarkerName = ".capsule-accessed"
+)
+
+// Container path constants
+const (
+	// ContainerVolumePath is where the encrypted volume is mounted inside the container.
+	ContainerVolumePath = "/claude-env"
+
+	// ContainerHomePath is HOME inside the container when it persists on the volume.
+	ContainerHomePath = ContainerVolumePath + "/home"
+
+	// ContainerWorkspacePath is where the workspace is mounted inside the container.
+	ContainerWorkspacePath = "/workspace"
 )
 
 // Volume size limits
@@ -45,3 +87,23 @@ const (
 	// PublicFilePermissions is the permission mode for non-sensitive, readable files.
 	PublicFilePermissions os.FileMode = 0644
 )
+
+// Delays for Docker Desktop to catch up after a volume is unmounted
+const (
+	// MountReleaseDelay waits for Docker to release mount references.
+	MountReleaseDelay = 1 * time.Second
+	// CacheRefreshDelay waits for Docker's VirtioFS cache to refresh.
+	CacheRefreshDelay = 2 * time.Second
+)
+
+// VolumeStructure defines the directory structure inside the encrypted volume.
+var VolumeStructure = []string{
+	"auth",                // API keys, authentication tokens
+	"config",              // User preferences, Claude Code settings
+	"claude-context",      // .claude conversation history
+	"bootstrap",           // Templates and starting files
+	"repos",               // Per-repository documentation and context
+	"home",                // User home directory (persists Claude credentials, shell history, etc.)
+	"home/.claude",        // Claude Code configuration directory
+	"home/.claude/skills", // Skills directory for doc-sync and other extensions
+}
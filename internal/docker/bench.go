@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Benchmark parameters, sized so a run takes a few seconds on a healthy mount
+const (
+	benchSeqSizeMB   = 64
+	benchSmallFiles  = 200
+	benchTimeout     = 60 * time.Second
+	benchMountTarget = "/bench"
+)
+
+// Thresholds below which MountBench.Slow reports a pathological mount
+const (
+	slowSeqMBps         = 20
+	slowSmallFileOpTime = 20 * time.Millisecond
+)
+
+// benchScript writes and reads a file sequentially, then creates and reads many
+// small files, printing nanosecond timestamps between phases.
+var benchScript = fmt.Sprintf(`set -e
+d=%[1]s/.capsule-bench-$$
+mkdir -p "$d"
+trap 'rm -rf "$d"' EXIT
+t0=$(date +%%s%%N)
+dd if=/dev/zero of="$d/seq" bs=1M count=%[2]d conv=fsync 2>/dev/null
+t1=$(date +%%s%%N)
+dd if="$d/seq" of=/dev/null bs=1M 2>/dev/null
+t2=$(date +%%s%%N)
+i=0
+while [ $i -lt %[3]d ]; do echo x > "$d/f$i"; cat "$d/f$i" > /dev/null; i=$((i+1)); done
+t3=$(date +%%s%%N)
+echo "$t0 $t1 $t2 $t3"`, benchMountTarget, benchSeqSizeMB, benchSmallFiles)
+
+// MountBench reports bind mount performance measured by BenchmarkMount.
+type MountBench struct {
+	SeqWriteMBps float64 // Sequential write throughput, including fsync
+	SeqReadMBps  float64 // Sequential read throughput; may be served from cache
+	SmallFileOp  time.Duration
+	Duration     time.Duration
+}
+
+// Slow reports whether the mount is slow enough to explain sluggish builds.
+// Consider consistency=cached or a named volume for build output if so.
+func (b *MountBench) Slow() bool {
+	return b.SeqWriteMBps < slowSeqMBps || b.SeqReadMBps < slowSeqMBps || b.SmallFileOp > slowSmallFileOpTime
+}
+
+func (b *MountBench) String() string {
+	return fmt.Sprintf("write %.1f MB/s, read %.1f MB/s, small-file create+read %v",
+		b.SeqWriteMBps, b.SeqReadMBps, b.SmallFileOp)
+}
+
+// BenchmarkMount measures sequential throughput and small-file latency of
+// mountPoint as seen from a container, using the helper image. It writes
+// temporary files to the mount and removes them afterwards.
+func (m *Manager) BenchmarkMount(mountPoint string) (*MountBench, error) {
+	if err := validatePath(mountPoint, "mount point"); err != nil {
+		return nil, err
+	}
+	if err := m.ensureHelperImage(); err != nil {
+		return nil, err
+	}
+
+	output, err := m.getCommandOutputWithTimeout(benchTimeout, "docker", "run", "--rm",
+		"--mount", fmt.Sprintf("type=bind,source=%s,target=%s", mountPoint, benchMountTarget),
+		m.helperImageName(), "sh", "-c", benchScript)
+	if err != nil {
+		return nil, fmt.Errorf("mount benchmark failed: %w", err)
+	}
+	return parseBenchOutput(string(output))
+}
+
+// parseBenchOutput converts the benchmark script's timestamps to a MountBench.
+func parseBenchOutput(output string) (*MountBench, error) {
+	fields := strings.Fields(output)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("unexpected benchmark output: %q", strings.TrimSpace(output))
+	}
+	var ts [4]int64
+	for i, f := range fields {
+		n, err := strconv.ParseInt(f, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected benchmark output %q: %w", f, err)
+		}
+		ts[i] = n
+	}
+
+	write, read, small := time.Duration(ts[1]-ts[0]), time.Duration(ts[2]-ts[1]), time.Duration(ts[3]-ts[2])
+	if write <= 0 || read <= 0 || small <= 0 {
+		return nil, fmt.Errorf("benchmark timestamps are not increasing: %q", strings.TrimSpace(output))
+	}
+	return &MountBench{
+		SeqWriteMBps: benchSeqSizeMB / write.Seconds(),
+		SeqReadMBps:  benchSeqSizeMB / read.Seconds(),
+		SmallFileOp:  small / benchSmallFiles,
+		Duration:     time.Duration(ts[3] - ts[0]),
+	}, nil
+}
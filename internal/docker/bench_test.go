@@ -0,0 +1,29 @@
+package docker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBenchOutput(t *testing.T) {
+	// 1s write, 0.5s read, 200 small files in 2s
+	bench, err := parseBenchOutput("1000000000 2000000000 2500000000 4500000000\n")
+	if err != nil {
+		t.Fatalf("parseBenchOutput() error = %v", err)
+	}
+	if bench.SeqWriteMBps != 64 || bench.SeqReadMBps != 128 {
+		t.Errorf("throughput = %v/%v, want 64/128", bench.SeqWriteMBps, bench.SeqReadMBps)
+	}
+	if bench.SmallFileOp != 10*time.Millisecond || bench.Duration != 3500*time.Millisecond {
+		t.Errorf("SmallFileOp = %v, Duration = %v", bench.SmallFileOp, bench.Duration)
+	}
+	if bench.Slow() {
+		t.Error("Slow() = true for a healthy mount")
+	}
+
+	for _, bad := range []string{"", "1 2 3", "1 2 x 4", "4 3 2 1"} {
+		if _, err := parseBenchOutput(bad); err == nil {
+			t.Errorf("parseBenchOutput(%q): want error", bad)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package docker
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+)
+
+// Range of --blkio-weight accepted by docker
+const (
+	minBlkioWeight = 10
+	maxBlkioWeight = 1000
+)
+
+// BlkioDeviceLimit caps the bandwidth of one host block device, as passed to
+// --device-read-bps and --device-write-bps.
+type BlkioDeviceLimit struct {
+	Device string // Host device path, e.g. /dev/disk0 or /dev/sda
+	Rate   string // Bytes per second with an optional unit, e.g. "50m"
+}
+
+func (l BlkioDeviceLimit) String() string {
+	return l.Device + ":" + l.Rate
+}
+
+// validate checks the device is an absolute path and the rate is a byte size.
+func (l BlkioDeviceLimit) validate(flag string) error {
+	if !filepath.IsAbs(l.Device) {
+		return fmt.Errorf("invalid %s device %q: must be an absolute path", flag, l.Device)
+	}
+	rate, err := parseDockerMemory(l.Rate)
+	if err != nil {
+		return fmt.Errorf("invalid %s rate for %s: %w", flag, l.Device, err)
+	}
+	if rate == 0 {
+		return fmt.Errorf("invalid %s rate for %s: must be greater than zero", flag, l.Device)
+	}
+	return nil
+}
+
+// validateBlkio checks the block IO weight and device limits.
+func (c *ContainerConfig) validateBlkio() error {
+	if c.BlkioWeight != nil && (*c.BlkioWeight < minBlkioWeight || *c.BlkioWeight > maxBlkioWeight) {
+		return fmt.Errorf("invalid block IO weight %d: must be between %d and %d", *c.BlkioWeight, minBlkioWeight, maxBlkioWeight)
+	}
+	for _, l := range c.DeviceReadBps {
+		if err := l.validate("--device-read-bps"); err != nil {
+			return err
+		}
+	}
+	for _, l := range c.DeviceWriteBps {
+		if err := l.validate("--device-write-bps"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blkioArgs returns the block IO flags for `docker run`.
+func (c *ContainerConfig) blkioArgs() []string {
+	var args []string
+	if c.BlkioWeight != nil {
+		args = append(args, "--blkio-weight", strconv.Itoa(int(*c.BlkioWeight)))
+	}
+	for _, l := range c.DeviceReadBps {
+		args = append(args, "--device-read-bps", l.String())
+	}
+	for _, l := range c.DeviceWriteBps {
+		args = append(args, "--device-write-bps", l.String())
+	}
+	return args
+}
@@ -0,0 +1,101 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// File sharing guidance shown by CheckTmpFileSharing, per engine
+const (
+	dockerDesktopSharingGuidance = `Please ensure Docker Desktop is running and file sharing is enabled:
+  1. Open Docker Desktop
+  2. Go to Settings (gear icon) → Resources → File sharing
+  3. Verify file sharing is enabled
+  4. Click "Apply & Restart" if you make changes`
+	vmSharingGuidance = `Please ensure the engine's VM is running and shares the host paths capsule uses
+(/tmp, /Volumes, and your workspaces), e.g. with "colima start --mount" or
+the file sharing settings of OrbStack or Rancher Desktop.`
+	nativeSharingGuidance = `Please ensure the Docker daemon is running and can read the host path.`
+)
+
+// EngineCapabilities says which of the Manager's engine-specific workarounds
+// apply to the Docker engine in use.
+type EngineCapabilities struct {
+	Kind EngineKind
+
+	// DropCaches is whether ClearVMCache drops the VM's kernel caches. It only
+	// helps Docker Desktop; elsewhere it would flush a VM without VirtioFS, or
+	// the host itself.
+	DropCaches bool
+
+	// RefreshMountCache is whether RefreshMountCache runs a probe container to
+	// refresh the VM's view of a host path. Native engines see host paths
+	// directly.
+	RefreshMountCache bool
+
+	// StaleMountDetection is whether CheckAllMounts classifies mount failures
+	// as stale VirtioFS state.
+	StaleMountDetection bool
+
+	// FileSharingGuidance tells the user how to let the engine see host files.
+	FileSharingGuidance string
+}
+
+// capabilitiesFor returns the capabilities of an engine kind. An unknown
+// engine is treated as Docker Desktop, the engine capsule was built for.
+func capabilitiesFor(kind EngineKind) EngineCapabilities {
+	switch kind {
+	case EngineNative:
+		return EngineCapabilities{Kind: kind, FileSharingGuidance: nativeSharingGuidance}
+	case EngineVM:
+		return EngineCapabilities{Kind: kind, RefreshMountCache: true, FileSharingGuidance: vmSharingGuidance}
+	default:
+		return EngineCapabilities{
+			Kind:                kind,
+			DropCaches:          true,
+			RefreshMountCache:   true,
+			StaleMountDetection: true,
+			FileSharingGuidance: dockerDesktopSharingGuidance,
+		}
+	}
+}
+
+// Capabilities reports which engine-specific workarounds apply, based on
+// Engine. The result is cached once the engine has been classified; if it
+// can't be, the Docker Desktop capabilities are returned and detection is
+// retried on the next call.
+func (m *Manager) Capabilities() EngineCapabilities {
+	m.capsMu.Lock()
+	defer m.capsMu.Unlock()
+	if m.caps != nil {
+		return *m.caps
+	}
+	kind, err := m.Engine()
+	caps := capabilitiesFor(kind)
+	if err == nil && kind != EngineUnknown {
+		m.caps = &caps
+	}
+	return caps
+}
+
+// CheckPrivilegedRun checks that the engine allows `docker run --privileged`,
+// which ClearVMCache needs. It runs `true` in the helper image and changes
+// nothing. Rootless engines and policy plugins commonly refuse it.
+func (m *Manager) CheckPrivilegedRun() error {
+	if err := m.ensureHelperImage(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeouts.Quick)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "docker", "run", "--rm", "--privileged", m.helperImageName(), "true").CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("privileged probe timed out after %v", m.timeouts.Quick)
+		}
+		return fmt.Errorf("docker refused a --privileged container: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
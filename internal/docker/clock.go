@@ -0,0 +1,42 @@
+package docker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ClockSkewThreshold is the skew beyond which tokens and TLS start failing in
+// practice; restarting the container resyncs its clock with the VM.
+const ClockSkewThreshold = 30 * time.Second
+
+// ClockSkew returns how far the container's clock is ahead of the host's
+// (negative if behind), to one second of precision. Docker Desktop's VM clock
+// can drift after the host sleeps. Returns *ContainerNotFoundError if the
+// container does not exist.
+func (m *Manager) ClockSkew(containerName string) (time.Duration, error) {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return 0, err
+	}
+	if !m.containerExists(containerName) {
+		return 0, &ContainerNotFoundError{Name: containerName}
+	}
+
+	before := time.Now()
+	output, err := m.getCommandOutputWithTimeout(m.timeouts.Quick, "docker", "exec", containerName, "date", "+%s")
+	after := time.Now()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read container clock: %w", err)
+	}
+
+	seconds, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected date output %q: %w", strings.TrimSpace(string(output)), err)
+	}
+
+	// Compare against the midpoint to cancel out docker exec's round trip
+	host := before.Add(after.Sub(before) / 2)
+	return time.Unix(seconds, 0).Sub(host).Truncate(time.Second), nil
+}
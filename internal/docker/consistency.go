@@ -0,0 +1,48 @@
+package docker
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Bind mount consistency modes understood by Docker Desktop for Mac
+const (
+	ConsistencyDelegated  = "delegated"
+	ConsistencyCached     = "cached"
+	ConsistencyConsistent = "consistent"
+)
+
+// NormalizeConsistency lowercases and validates a --mount consistency value,
+// mapping empty to delegated. Outside macOS it returns "" so the option is
+// dropped rather than passed to an engine that does not support it.
+func NormalizeConsistency(v string) (string, error) {
+	return normalizeConsistency(v, runtime.GOOS)
+}
+
+func normalizeConsistency(v, goos string) (string, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	switch v {
+	case "":
+		v = ConsistencyDelegated
+	case ConsistencyDelegated, ConsistencyCached, ConsistencyConsistent:
+	default:
+		return "", fmt.Errorf("invalid mount consistency %q: must be %s, %s, or %s",
+			v, ConsistencyDelegated, ConsistencyCached, ConsistencyConsistent)
+	}
+	if goos != "darwin" {
+		return "", nil
+	}
+	return v, nil
+}
+
+// consistencyOption returns the ",consistency=<v>" suffix for a --mount spec,
+// or "" when the option should be dropped. Invalid values are rejected by
+// Validate before mounts are assembled.
+func consistencyOption(v string) string {
+	normalized, err := NormalizeConsistency(v)
+	if err != nil || normalized == "" {
+		return ""
+	}
+	return ",consistency=" + normalized
+}
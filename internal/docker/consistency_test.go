@@ -0,0 +1,26 @@
+package docker
+
+import "testing"
+
+func TestNormalizeConsistency(t *testing.T) {
+	tests := []struct {
+		in, goos string
+		want     string
+		wantErr  bool
+	}{
+		{"", "darwin", ConsistencyDelegated, false},
+		{" Cached ", "darwin", ConsistencyCached, false},
+		{"consistent", "darwin", ConsistencyConsistent, false},
+		{"cached", "linux", "", false},
+		{"", "linux", "", false},
+		{"fast", "darwin", "", true},
+		{"fast", "linux", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizeConsistency(tt.in, tt.goos)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("normalizeConsistency(%q, %q) = %q, %v, want %q, wantErr %v", tt.in, tt.goos, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
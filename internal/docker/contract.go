@@ -0,0 +1,137 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// requiredImageCommands are run inside every capsule container: tail keeps it
+// alive and setup-workspace-symlink.sh creates the _docs symlink.
+var requiredImageCommands = []string{"tail", "setup-workspace-symlink.sh"}
+
+// requiredCommands returns the commands capsule runs in containers created
+// from config. With UseImageEntrypoint the image's own process keeps the
+// container alive, so tail is not needed.
+func (c *ContainerConfig) requiredCommands() []string {
+	if !c.UseImageEntrypoint {
+		return requiredImageCommands
+	}
+	var commands []string
+	for _, command := range requiredImageCommands {
+		if command != "tail" {
+			commands = append(commands, command)
+		}
+	}
+	return commands
+}
+
+// checkCommandsScript prints each argument that is not an executable path or
+// a command on PATH.
+const checkCommandsScript = `for c in "$@"; do
+	case "$c" in
+		/*) [ -x "$c" ] ;;
+		*) command -v "$c" >/dev/null 2>&1 ;;
+	esac || echo "$c"
+done`
+
+// ImageContractError is returned when an image lacks commands capsule relies on.
+type ImageContractError struct {
+	Image   string
+	Missing []string
+}
+
+func (e *ImageContractError) Error() string {
+	return fmt.Sprintf("image %s is missing %s", e.Image, strings.Join(e.Missing, ", "))
+}
+
+// VerifyImageContract runs the image briefly and checks that it provides the
+// commands capsule executes in it: tail, setup-workspace-symlink.sh, and the
+// shell. Returns *ImageContractError listing anything missing, so a custom
+// image fails up front instead of deep inside Start or Exec. An image ID that
+// passed is recorded under ~/.capsule/verified-images and not probed again.
+func (m *Manager) VerifyImageContract(imageName string) error {
+	return m.verifyImageContract(imageName, requiredImageCommands)
+}
+
+// verifyImageContract checks that imageName provides commands and the shell,
+// skipping the probe if its image ID already passed the same check.
+func (m *Manager) verifyImageContract(imageName string, commands []string) error {
+	if err := ValidateImageName(imageName); err != nil {
+		return fmt.Errorf("invalid image name: %w", err)
+	}
+	commands = append(append([]string{}, commands...), DefaultShell)
+
+	// Without an ID (e.g. the image is pulled by docker run) the check just runs every time
+	var marker string
+	if id, err := m.imageID(imageName); err == nil {
+		if dir, err := verifiedImagesDir(); err == nil {
+			marker = contractMarkerPath(dir, id, commands)
+			if _, err := os.Stat(marker); err == nil {
+				return nil
+			}
+		}
+	}
+
+	args := []string{"run", "--rm", "--entrypoint", "sh", imageName, "-c", checkCommandsScript, "sh"}
+	args = append(args, commands...)
+
+	output, err := m.getCommandOutputWithTimeout(m.timeouts.Command, "docker", args...)
+	if err != nil {
+		return fmt.Errorf("failed to check image %s (does it provide /bin/sh?): %w", imageName, err)
+	}
+
+	var missing []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			missing = append(missing, line)
+		}
+	}
+	if len(missing) > 0 {
+		return &ImageContractError{Image: imageName, Missing: missing}
+	}
+
+	// Best effort: failing to record only means probing again next time
+	if marker != "" {
+		if err := os.MkdirAll(filepath.Dir(marker), constants.DirPermissions); err == nil {
+			_ = os.WriteFile(marker, []byte(imageName+"\n"), constants.FilePermissions)
+		}
+	}
+	return nil
+}
+
+// imageID returns the local image's ID, e.g. "sha256:...".
+func (m *Manager) imageID(imageName string) (string, error) {
+	output, err := m.getCommandOutputWithTimeout(m.timeouts.Quick, "docker", "image", "inspect", "--format", "{{.Id}}", imageName)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect image %s: %w", imageName, err)
+	}
+	id := strings.TrimSpace(string(output))
+	if id == "" {
+		return "", fmt.Errorf("image %s has no ID", imageName)
+	}
+	return id, nil
+}
+
+// verifiedImagesDir returns the directory recording images that passed the
+// contract check.
+func verifiedImagesDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, constants.CapsuleConfigDir, constants.VerifiedImagesSubdir), nil
+}
+
+// contractMarkerPath returns the file in dir recording that image ID id
+// provides commands. Hashing the commands with the ID means a check for a
+// different set of commands never reuses the result.
+func contractMarkerPath(dir, id string, commands []string) string {
+	hash := sha256.Sum256([]byte(id + "\n" + strings.Join(commands, "\n")))
+	return filepath.Join(dir, hex.EncodeToString(hash[:]))
+}
@@ -0,0 +1,33 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContractMarkerPath(t *testing.T) {
+	dir := t.TempDir()
+	commands := []string{"tail", "setup-workspace-symlink.sh", DefaultShell}
+
+	marker := contractMarkerPath(dir, "sha256:abc", commands)
+	if marker != contractMarkerPath(dir, "sha256:abc", commands) {
+		t.Error("contractMarkerPath() is not stable")
+	}
+	if marker == contractMarkerPath(dir, "sha256:def", commands) {
+		t.Error("contractMarkerPath() ignores the image ID")
+	}
+	if marker == contractMarkerPath(dir, "sha256:abc", commands[1:]) {
+		t.Error("contractMarkerPath() ignores the commands checked")
+	}
+}
+
+func TestRequiredCommands(t *testing.T) {
+	config := ContainerConfig{}
+	if got := config.requiredCommands(); !reflect.DeepEqual(got, requiredImageCommands) {
+		t.Errorf("requiredCommands() = %v, want %v", got, requiredImageCommands)
+	}
+	config.UseImageEntrypoint = true
+	if got, want := config.requiredCommands(), []string{"setup-workspace-symlink.sh"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("requiredCommands() with UseImageEntrypoint = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,47 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// ErrDockerNotInstalled is returned by WaitDaemon when the docker CLI is not on PATH,
+// so waiting for the daemon would never succeed.
+var ErrDockerNotInstalled = errors.New("docker CLI not found on PATH; install Docker Desktop")
+
+// Backoff between `docker info` polls in WaitDaemon
+const (
+	daemonPollInitialDelay = 500 * time.Millisecond
+	daemonPollMaxDelay     = 5 * time.Second
+)
+
+// DefaultDaemonWaitTimeout is long enough for Docker Desktop to start after login.
+const DefaultDaemonWaitTimeout = 90 * time.Second
+
+// WaitDaemon polls `docker info` with exponential backoff until the daemon
+// answers or ctx is done, for scripts that race Docker Desktop's startup.
+// It returns ErrDockerNotInstalled immediately if there is no docker CLI.
+// Start keeps its own single-shot check, so callers opt in to waiting.
+func (m *Manager) WaitDaemon(ctx context.Context) error {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return ErrDockerNotInstalled
+	}
+
+	delay := daemonPollInitialDelay
+	for {
+		err := exec.CommandContext(ctx, "docker", "info").Run()
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Docker daemon did not start: %w (last error: %v)", ctx.Err(), err)
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, daemonPollMaxDelay)
+	}
+}
@@ -0,0 +1,62 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/docslink"
+)
+
+// DetachWorkspace removes the workspace's _docs symlink on the host and, if
+// the container is running, inside it, leaving repos/<repoID> in the volume
+// untouched so setting the symlink up again restores everything. Links that
+// capsule did not create (see docslink.IsManagedTarget) and real files are refused.
+// A missing symlink or container is not an error, so it is safe to repeat.
+func (m *Manager) DetachWorkspace(containerName, workspacePath, volumeMountPoint string) error {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return err
+	}
+
+	hostLink := filepath.Join(workspacePath, m.docsLinkName())
+	if info, err := os.Lstat(hostLink); err == nil {
+		if info.Mode()&os.ModeSymlink == 0 {
+			return fmt.Errorf("refusing to remove %s: not a symlink", hostLink)
+		}
+		target, err := os.Readlink(hostLink)
+		if err != nil {
+			return fmt.Errorf("failed to read symlink %s: %w", hostLink, err)
+		}
+		if !docslink.IsManagedTarget(target, workspacePath, volumeMountPoint) {
+			return fmt.Errorf("refusing to remove %s: not a capsule-managed symlink (points at %s)", hostLink, target)
+		}
+		if err := os.Remove(hostLink); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove symlink %s: %w", hostLink, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to stat %s: %w", hostLink, err)
+	}
+
+	// The workspace is bind-mounted, so this is usually already gone; removing
+	// it in the container as well clears any stale view of it there
+	if !m.IsRunning(containerName) {
+		return nil
+	}
+	workspaceTarget := m.containerWorkspaceTarget(containerName)
+	containerLink := path.Join(workspaceTarget, m.docsLinkName())
+	output, err := m.getCommandOutputWithTimeout(m.timeouts.Quick, "docker", "exec", containerName, "readlink", containerLink)
+	target := strings.TrimSpace(string(output))
+	if err != nil || target == "" {
+		return nil // Not a symlink, or already removed
+	}
+	if !docslink.IsManagedTarget(target, workspaceTarget, "") {
+		return fmt.Errorf("refusing to remove %s in %s: not a capsule-managed symlink (points at %s)", containerLink, containerName, target)
+	}
+	if err := m.runCommandWithTimeout(m.timeouts.Quick, "docker", "exec", containerName, "rm", "-f", containerLink); err != nil {
+		return fmt.Errorf("failed to remove %s in %s: %w", containerLink, containerName, err)
+	}
+	return nil
+}
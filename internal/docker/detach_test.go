@@ -0,0 +1,62 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// detachTestContainer is not expected to exist, so DetachWorkspace only
+// touches the host side.
+const detachTestContainer = "capsule-detach-test-nonexistent"
+
+func TestDetachWorkspaceHost(t *testing.T) {
+	m := NewManager()
+	mountPoint := t.TempDir()
+	link := func(workspace string) string { return filepath.Join(workspace, constants.DocsSymlinkName) }
+
+	for _, target := range []string{
+		filepath.Join(mountPoint, constants.ReposDirName, "github.com-user-project"),
+		filepath.Join(constants.ContainerVolumePath, constants.ReposDirName, "github.com-user-project"),
+	} {
+		workspace := t.TempDir()
+		if err := os.Symlink(target, link(workspace)); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.DetachWorkspace(detachTestContainer, workspace, mountPoint); err != nil {
+			t.Fatalf("DetachWorkspace(-> %s) error = %v", target, err)
+		}
+		if _, err := os.Lstat(link(workspace)); !os.IsNotExist(err) {
+			t.Errorf("DetachWorkspace(-> %s) left the symlink in place", target)
+		}
+		// Repeating it with nothing left to remove succeeds
+		if err := m.DetachWorkspace(detachTestContainer, workspace, mountPoint); err != nil {
+			t.Errorf("second DetachWorkspace() error = %v", err)
+		}
+	}
+}
+
+func TestDetachWorkspaceRefusesUnmanaged(t *testing.T) {
+	m := NewManager()
+	mountPoint := t.TempDir()
+
+	userLink := t.TempDir()
+	if err := os.Symlink(t.TempDir(), filepath.Join(userLink, constants.DocsSymlinkName)); err != nil {
+		t.Fatal(err)
+	}
+	realDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(realDir, constants.DocsSymlinkName), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, workspace := range map[string]string{"user symlink": userLink, "real directory": realDir} {
+		if err := m.DetachWorkspace(detachTestContainer, workspace, mountPoint); err == nil {
+			t.Errorf("DetachWorkspace(%s) = nil, want a refusal", name)
+		}
+		if _, err := os.Lstat(filepath.Join(workspace, constants.DocsSymlinkName)); err != nil {
+			t.Errorf("DetachWorkspace(%s) removed it: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrNotGitRepo is returned by WorkspaceDirty when the container's workspace
+// is not inside a git repository.
+var ErrNotGitRepo = errors.New("workspace is not a git repository")
+
+// WorkspaceDirty runs `git status --porcelain` in the container's working
+// directory (the workspace) and reports whether there are uncommitted changes,
+// along with the changed paths relative to the repository root. It returns
+// false and ErrNotGitRepo if the workspace is not a git repository, and
+// *ContainerNotFoundError if the container does not exist.
+func (m *Manager) WorkspaceDirty(containerName string) (bool, []string, error) {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return false, nil, err
+	}
+	if !m.containerExists(containerName) {
+		return false, nil, &ContainerNotFoundError{Name: containerName}
+	}
+	if !m.IsRunning(containerName) {
+		return false, nil, fmt.Errorf("container %s is not running", containerName)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeouts.Command)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "docker", "exec", containerName, "git", "status", "--porcelain")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return false, nil, fmt.Errorf("git status timed out after %v", m.timeouts.Command)
+		}
+		if strings.Contains(string(output), "not a git repository") {
+			return false, nil, ErrNotGitRepo
+		}
+		return false, nil, fmt.Errorf("git status failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	paths := parsePorcelain(string(output))
+	return len(paths) > 0, paths, nil
+}
+
+// parsePorcelain extracts the paths from `git status --porcelain` output. For
+// renames ("R  old -> new") the new path is returned.
+func parsePorcelain(output string) []string {
+	var paths []string
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		p := line[3:]
+		if _, to, ok := strings.Cut(p, " -> "); ok {
+			p = to
+		}
+		paths = append(paths, strings.Trim(p, `"`))
+	}
+	return paths
+}
@@ -0,0 +1,45 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PruneManagedDockerVolumes removes Docker volumes carrying the capsule.managed
+// label that no running container uses. It returns the names removed and,
+// separately, the names skipped because a running container uses them; being
+// in use is not an error. Use it after removing containers (e.g. with purge)
+// so no volumes are left behind.
+func (m *Manager) PruneManagedDockerVolumes() (removed, inUse []string, err error) {
+	output, err := m.getCommandOutputWithTimeout(m.timeouts.Command,
+		"docker", "volume", "ls", "--filter", "label="+LabelManaged+"=true", "--format", "{{.Name}}")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list capsule docker volumes: %w", err)
+	}
+
+	removed = []string{}
+	var errs []error
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		name := strings.TrimSpace(line)
+		if name == "" {
+			continue
+		}
+		users, err := m.getCommandOutputWithTimeout(m.timeouts.Command,
+			"docker", "ps", "-q", "--filter", "volume="+name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to check users of volume %s: %w", name, err))
+			continue
+		}
+		if strings.TrimSpace(string(users)) != "" {
+			inUse = append(inUse, name)
+			continue
+		}
+		if err := m.runCommandWithTimeout(m.timeouts.Command, "docker", "volume", "rm", name); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove volume %s: %w", name, err))
+			continue
+		}
+		removed = append(removed, name)
+	}
+	return removed, inUse, errors.Join(errs...)
+}
@@ -0,0 +1,19 @@
+package docker
+
+import "github.com/jeanhaley32/claude-capsule/internal/docslink"
+
+// SetDocsLinkName sets the name of the workspace symlink this manager creates,
+// checks, and removes, e.g. ".capsule-docs" for projects that already have a
+// _docs directory. Empty restores the default, constants.DocsSymlinkName.
+func (m *Manager) SetDocsLinkName(name string) error {
+	if err := docslink.Validate(name); err != nil {
+		return err
+	}
+	m.docsLink = name
+	return nil
+}
+
+// docsLinkName returns the manager's workspace symlink name.
+func (m *Manager) docsLinkName() string {
+	return docslink.Name(m.docsLink)
+}
@@ -0,0 +1,177 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Drift is one difference between a ContainerConfig and a live container.
+type Drift struct {
+	Field string // e.g. "image", "mount /workspace", "env HOME", "memory"
+	Want  string // From the configuration; empty if the config doesn't set it
+	Got   string // From the container; empty if the container doesn't have it
+}
+
+func (d Drift) String() string {
+	return fmt.Sprintf("%s: want %q, got %q", d.Field, d.Want, d.Got)
+}
+
+// ConfigDriftError is returned by Start when the running container it would
+// reuse was created from a different configuration.
+type ConfigDriftError struct {
+	ContainerName string
+	Drift         []Drift
+}
+
+func (e *ConfigDriftError) Error() string {
+	fields := make([]string, len(e.Drift))
+	for i, d := range e.Drift {
+		fields[i] = d.Field
+	}
+	return fmt.Sprintf("container %s differs from the configuration (%s); stop it to recreate",
+		e.ContainerName, strings.Join(fields, ", "))
+}
+
+// ConfigDrift compares config with the container as created: image, bind
+// mounts, environment, working directory, and the --memory and --cpus limits
+// in ExtraArgs. Environment variables the image sets are not reported, since
+// docker inspect cannot tell them apart from ones capsule passed. Returns
+// *ContainerNotFoundError if the container does not exist.
+func (m *Manager) ConfigDrift(containerName string, config ContainerConfig) ([]Drift, error) {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return nil, err
+	}
+	info, err := m.inspectContainer(containerName)
+	if err != nil {
+		return nil, err
+	}
+	return info.drift(config), nil
+}
+
+// checkReusable returns *ConfigDriftError if the running container differs
+// from config in anything but its environment. Environment drift is tolerated
+// because inherited values such as SSH_AUTH_SOCK change between logins.
+func (m *Manager) checkReusable(config ContainerConfig) error {
+	drift, err := m.ConfigDrift(config.ContainerName, config)
+	if err != nil {
+		return err
+	}
+	var blocking []Drift
+	for _, d := range drift {
+		if !strings.HasPrefix(d.Field, "env ") {
+			blocking = append(blocking, d)
+		}
+	}
+	if len(blocking) > 0 {
+		return &ConfigDriftError{ContainerName: config.ContainerName, Drift: blocking}
+	}
+	return nil
+}
+
+// drift lists the differences between config and the inspected container.
+func (c *containerInspect) drift(config ContainerConfig) []Drift {
+	var drift []Drift
+	add := func(field, want, got string) {
+		if want != got {
+			drift = append(drift, Drift{Field: field, Want: want, Got: got})
+		}
+	}
+
+	add("image", config.ImageName, c.Config.Image)
+	add("working dir", config.WorkDir(), c.Config.WorkingDir)
+
+	// Bind mounts, keyed by container path
+	wantMounts, wantEnv := expectedMountsAndEnv(containerOptionArgs(config))
+	gotMounts := map[string]string{}
+	for _, mount := range c.Mounts {
+		if mount.Type == "bind" {
+			gotMounts[mount.Destination] = filepath.Clean(mount.Source)
+		}
+	}
+	for _, target := range sortedKeys(wantMounts, gotMounts) {
+		add("mount "+target, wantMounts[target], gotMounts[target])
+	}
+
+	gotEnv := map[string]string{}
+	for _, env := range c.Config.Env {
+		name, value, _ := strings.Cut(env, "=")
+		gotEnv[name] = value
+	}
+	for _, name := range sortedKeys(wantEnv, nil) {
+		add("env "+name, wantEnv[name], gotEnv[name])
+	}
+
+	var wantMemory int64
+	if value, ok := config.extraArgValue("--memory", "-m"); ok {
+		if limit, err := parseDockerMemory(value); err == nil {
+			wantMemory = int64(limit)
+		}
+	}
+	add("memory", formatLimit(wantMemory), formatLimit(c.HostConfig.Memory))
+
+	var wantNanoCPUs int64
+	if value, ok := config.extraArgValue("--cpus"); ok {
+		if cpus, err := strconv.ParseFloat(value, 64); err == nil {
+			wantNanoCPUs = int64(cpus * 1e9)
+		}
+	}
+	add("cpus", formatLimit(wantNanoCPUs), formatLimit(c.HostConfig.NanoCpus))
+
+	return drift
+}
+
+// expectedMountsAndEnv extracts the bind mounts (target -> source) and the
+// environment variables from `docker run` arguments. A bare -e NAME takes its
+// value from the current environment, as docker run does.
+func expectedMountsAndEnv(args []string) (mounts, env map[string]string) {
+	mounts, env = map[string]string{}, map[string]string{}
+	for i := 0; i+1 < len(args); i++ {
+		switch args[i] {
+		case "--mount":
+			fields := map[string]string{}
+			for _, field := range strings.Split(args[i+1], ",") {
+				key, value, _ := strings.Cut(field, "=")
+				fields[key] = value
+			}
+			if fields["type"] == "bind" {
+				mounts[fields["target"]] = filepath.Clean(fields["source"])
+			}
+		case "-e":
+			name, value, hasValue := strings.Cut(args[i+1], "=")
+			if !hasValue {
+				value = os.Getenv(name)
+			}
+			env[name] = value
+		}
+	}
+	return mounts, env
+}
+
+// formatLimit renders a resource limit, where zero means none.
+func formatLimit(v int64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+// sortedKeys returns the union of the maps' keys in order.
+func sortedKeys(a, b map[string]string) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range []map[string]string{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,75 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// EngineKind classifies the Docker engine the CLI talks to.
+type EngineKind string
+
+const (
+	// EngineDockerDesktop is Docker Desktop, which runs containers in a Linux VM
+	// and shares host files over VirtioFS.
+	EngineDockerDesktop EngineKind = "docker-desktop"
+	// EngineVM is another VM-backed engine with host file sharing (Colima, OrbStack,
+	// Rancher Desktop).
+	EngineVM EngineKind = "vm"
+	// EngineNative is a daemon running directly on the host kernel.
+	EngineNative EngineKind = "native"
+	// EngineUnknown is returned when the engine cannot be classified.
+	EngineUnknown EngineKind = "unknown"
+)
+
+// engineInfo mirrors the subset of `docker info` output used to classify the
+// engine and report its resources.
+type engineInfo struct {
+	Name            string `json:"Name"`
+	OperatingSystem string `json:"OperatingSystem"`
+	ServerVersion   string `json:"ServerVersion"`
+	KernelVersion   string `json:"KernelVersion"`
+	MemTotal        int64  `json:"MemTotal"`
+	NCPU            int    `json:"NCPU"`
+}
+
+// Engine classifies the Docker engine from `docker info`, so VM-specific
+// workarounds like ClearVMCache only run where they apply.
+func (m *Manager) Engine() (EngineKind, error) {
+	info, err := m.engineInfo()
+	if err != nil {
+		return EngineUnknown, err
+	}
+	return classifyEngine(info), nil
+}
+
+// engineInfo returns the parsed `docker info` output.
+func (m *Manager) engineInfo() (engineInfo, error) {
+	var info engineInfo
+	output, err := m.getCommandOutputWithTimeout(m.timeouts.Command, "docker", "info", "--format", "{{json .}}")
+	if err != nil {
+		return info, fmt.Errorf("failed to query docker info: %w", err)
+	}
+	if err := json.Unmarshal(output, &info); err != nil {
+		return info, fmt.Errorf("failed to parse docker info: %w", err)
+	}
+	return info, nil
+}
+
+// classifyEngine maps docker info fields to an EngineKind.
+func classifyEngine(info engineInfo) EngineKind {
+	osName := strings.ToLower(info.OperatingSystem)
+	switch {
+	case strings.Contains(osName, "docker desktop"):
+		return EngineDockerDesktop
+	case strings.Contains(osName, "orbstack"),
+		strings.Contains(osName, "rancher desktop"),
+		strings.EqualFold(info.Name, "colima"),
+		strings.Contains(info.KernelVersion, "linuxkit"):
+		return EngineVM
+	case info.OperatingSystem != "":
+		return EngineNative
+	default:
+		return EngineUnknown
+	}
+}
@@ -0,0 +1,56 @@
+package docker
+
+import "testing"
+
+func TestClassifyEngine(t *testing.T) {
+	tests := []struct {
+		info engineInfo
+		want EngineKind
+	}{
+		{engineInfo{Name: "docker-desktop", OperatingSystem: "Docker Desktop", KernelVersion: "6.10.14-linuxkit"}, EngineDockerDesktop},
+		{engineInfo{Name: "orbstack", OperatingSystem: "OrbStack"}, EngineVM},
+		{engineInfo{Name: "colima", OperatingSystem: "Ubuntu 24.04 LTS"}, EngineVM},
+		{engineInfo{Name: "build-01", OperatingSystem: "Ubuntu 22.04.4 LTS", KernelVersion: "6.8.0-45-generic"}, EngineNative},
+		{engineInfo{}, EngineUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := classifyEngine(tt.info); got != tt.want {
+			t.Errorf("classifyEngine(%+v) = %q, want %q", tt.info, got, tt.want)
+		}
+	}
+}
+
+func TestResourceWarnings(t *testing.T) {
+	res := engineResources(engineInfo{MemTotal: 2 << 30, NCPU: 4})
+	if res.MemoryBytes != 2<<30 || res.CPUs != 4 || res.Note != "" {
+		t.Fatalf("engineResources() = %+v", res)
+	}
+	if res := engineResources(engineInfo{NCPU: 4}); res.Note == "" {
+		t.Error("engineResources() without memory has no note")
+	}
+
+	cfg := ContainerConfig{ExtraArgs: []string{"--memory=4g", "--cpus", "2"}}
+	if w := cfg.ResourceWarnings(res); len(w) != 1 {
+		t.Errorf("ResourceWarnings() = %v, want one memory warning", w)
+	}
+	cfg.ExtraArgs = []string{"-m", "1024m", "--cpus=8"}
+	if w := cfg.ResourceWarnings(res); len(w) != 1 {
+		t.Errorf("ResourceWarnings() = %v, want one CPU warning", w)
+	}
+	if w := cfg.ResourceWarnings(&EngineResources{}); len(w) != 0 {
+		t.Errorf("ResourceWarnings(unknown) = %v, want none", w)
+	}
+}
+
+func TestCapabilitiesFor(t *testing.T) {
+	if caps := capabilitiesFor(EngineNative); caps.DropCaches || caps.RefreshMountCache || caps.StaleMountDetection {
+		t.Errorf("capabilitiesFor(native) = %+v, want no VM workarounds", caps)
+	}
+	if caps := capabilitiesFor(EngineVM); caps.DropCaches || !caps.RefreshMountCache {
+		t.Errorf("capabilitiesFor(vm) = %+v, want refresh without drop_caches", caps)
+	}
+	if caps := capabilitiesFor(EngineUnknown); !caps.DropCaches || !caps.StaleMountDetection {
+		t.Errorf("capabilitiesFor(unknown) = %+v, want Docker Desktop workarounds", caps)
+	}
+}
@@ -0,0 +1,125 @@
+package docker
+
+import (
+	"fmt"
+	"time"
+)
+
+// eventBufferSize is how many events Events buffers for a slow consumer.
+const eventBufferSize = 256
+
+// EventPhase is the lifecycle step an Event reports on.
+type EventPhase string
+
+const (
+	PhaseStart EventPhase = "start" // Start: creating or reusing the container
+	PhaseStop  EventPhase = "stop"  // Stop: stopping and removing the container
+	PhaseSetup EventPhase = "setup" // Creating the workspace docs symlink
+	PhaseExec  EventPhase = "exec"  // An interactive shell session
+
+	// Volume phases are published by callers through TrackPhase and
+	// PublishProgress, since the Manager does not mount volumes itself.
+	PhaseMount   EventPhase = "mount"   // Mounting the encrypted volume
+	PhaseUnmount EventPhase = "unmount" // Unmounting the encrypted volume
+)
+
+// EventKind says whether an Event marks the beginning or the end of a phase.
+type EventKind string
+
+const (
+	EventBegin    EventKind = "begin"
+	EventProgress EventKind = "progress" // A step within a running phase
+	EventEnd      EventKind = "end"
+)
+
+// Event is one entry of the stream returned by Events. Step is only set on
+// EventProgress. Duration, Err and Error are only set on EventEnd; Err is nil
+// when the phase succeeded, and Error carries its message for JSON consumers.
+// Duration marshals as nanoseconds.
+type Event struct {
+	Time      time.Time     `json:"time"`
+	Phase     EventPhase    `json:"phase"`
+	Kind      EventKind     `json:"kind"`
+	Container string        `json:"container,omitempty"`
+	Repo      string        `json:"repo,omitempty"` // Empty for phases that don't know the repository
+	Step      string        `json:"step,omitempty"` // e.g. a volume.MountPhase
+	Duration  time.Duration `json:"duration,omitempty"`
+	Err       error         `json:"-"`
+	Error     string        `json:"error,omitempty"`
+}
+
+func (e Event) String() string {
+	s := fmt.Sprintf("%s %s %s", e.Phase, e.Kind, e.Container)
+	if e.Step != "" {
+		s += " " + e.Step
+	}
+	if e.Kind == EventEnd {
+		s += fmt.Sprintf(" (%v)", e.Duration.Round(time.Millisecond))
+	}
+	if e.Err != nil {
+		s += ": " + e.Err.Error()
+	}
+	return s
+}
+
+// Events returns a channel of begin and end events for Start, Stop, workspace
+// symlink setup and shell sessions run through this Manager, and for volume
+// mounts and unmounts callers report with TrackPhase and PublishProgress. Nothing is
+// recorded until the first call, and every call returns the same channel.
+//
+// Events from one goroutine arrive in the order they happened, and a phase's
+// EventBegin always precedes its EventEnd. The channel buffers 256 events;
+// publishing never blocks, so when the buffer is full new events are dropped
+// rather than slowing capsule down. Ignoring the channel is safe. It is never
+// closed.
+func (m *Manager) Events() <-chan Event {
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+	if m.events == nil {
+		m.events = make(chan Event, eventBufferSize)
+	}
+	return m.events
+}
+
+// publish sends an event if Events has been called, dropping it when the buffer is full.
+func (m *Manager) publish(e Event) {
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+	if m.events == nil {
+		return
+	}
+	select {
+	case m.events <- e:
+	default:
+	}
+}
+
+// track publishes EventBegin for a phase and returns a func that publishes
+// its EventEnd with the error *errp points to, for use as
+// `defer m.track(PhaseStop, name, "")(&err)`.
+func (m *Manager) track(phase EventPhase, container, repo string) func(errp *error) {
+	begin := time.Now()
+	m.publish(Event{Time: begin, Phase: phase, Kind: EventBegin, Container: container, Repo: repo})
+	return func(errp *error) {
+		end := Event{Time: time.Now(), Phase: phase, Kind: EventEnd, Container: container, Repo: repo}
+		end.Duration = end.Time.Sub(begin)
+		if errp != nil && *errp != nil {
+			end.Err = *errp
+			end.Error = end.Err.Error()
+		}
+		m.publish(end)
+	}
+}
+
+// TrackPhase publishes EventBegin for a phase the caller performs, such as
+// PhaseMount, and returns a func that publishes its EventEnd with the error
+// *errp points to, like the phases the Manager tracks itself.
+func (m *Manager) TrackPhase(phase EventPhase, container, repo string) func(errp *error) {
+	return m.track(phase, container, repo)
+}
+
+// PublishProgress publishes an EventProgress for step within a phase begun
+// with TrackPhase, e.g. each volume.MountEvent of MountWithProgress.
+func (m *Manager) PublishProgress(phase EventPhase, container, repo, step string) {
+	m.publish(Event{Time: time.Now(), Phase: phase, Kind: EventProgress, Container: container, Repo: repo, Step: step})
+}
@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTrackPublishesErrorString(t *testing.T) {
+	m := NewManager()
+	events := m.Events()
+
+	err := errors.New("attach failed")
+	end := m.TrackPhase(PhaseMount, "claude-capsule-test", "github.com-user-project")
+	m.PublishProgress(PhaseMount, "claude-capsule-test", "github.com-user-project", "attaching")
+	end(&err)
+
+	var kinds []EventKind
+	var last Event
+	for i := 0; i < 3; i++ {
+		last = <-events
+		kinds = append(kinds, last.Kind)
+	}
+	if want := []EventKind{EventBegin, EventProgress, EventEnd}; !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("event kinds = %v, want %v", kinds, want)
+	}
+	if !errors.Is(last.Err, err) || last.Error != "attach failed" {
+		t.Errorf("end event Err = %v, Error = %q", last.Err, last.Error)
+	}
+
+	data, jsonErr := json.Marshal(last)
+	if jsonErr != nil {
+		t.Fatalf("json.Marshal() error = %v", jsonErr)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded["phase"] != "mount" || decoded["kind"] != "end" || decoded["error"] != "attach failed" {
+		t.Errorf("marshaled event = %s", data)
+	}
+	if _, ok := decoded["Err"]; ok {
+		t.Errorf("marshaled event has an Err field: %s", data)
+	}
+}
+
+func TestTrackOmitsErrorOnSuccess(t *testing.T) {
+	m := NewManager()
+	events := m.Events()
+
+	var err error
+	m.TrackPhase(PhaseUnmount, "claude-capsule-test", "")(&err)
+	<-events
+	end := <-events
+	if end.Err != nil || end.Error != "" {
+		t.Errorf("end event Err = %v, Error = %q, want none", end.Err, end.Error)
+	}
+	data, _ := json.Marshal(end)
+	if strings.Contains(string(data), `"error"`) {
+		t.Errorf("marshaled event = %s, want no error field", data)
+	}
+}
@@ -0,0 +1,80 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// Errors returned by VerifyHome, one per way HOME can fail to persist
+var (
+	ErrHomeNotPersisted = errors.New("HOME is not set to the volume")
+	ErrHomeMissing      = errors.New("HOME directory does not exist")
+	ErrHomeNotWritable  = errors.New("HOME directory is not writable")
+	ErrHomeNotOnVolume  = errors.New("HOME directory is not on the mounted volume")
+)
+
+// verifyHomeScript checks HOME from inside the container. Each failure exits
+// with a distinct status, mapped to an error by homeExitErrors. stat -L
+// follows a symlinked HOME to where files actually land.
+const verifyHomeScript = `h="${HOME:-}"
+[ "$h" = "$1" ] || { printf '%s' "$h"; exit 10; }
+[ -d "$h" ] || exit 11
+probe="$h/.capsule-home-probe.$$"
+{ : > "$probe"; } 2>/dev/null && rm -f "$probe" || exit 12
+dev=$(stat -L -c %d "$h") || exit 13
+[ "$dev" = "$(stat -L -c %d "$2")" ] && [ "$dev" != "$(stat -L -c %d /)" ] || exit 13
+`
+
+// homeExitErrors maps verifyHomeScript exit statuses to errors.
+var homeExitErrors = map[int]error{
+	10: ErrHomeNotPersisted,
+	11: ErrHomeMissing,
+	12: ErrHomeNotWritable,
+	13: ErrHomeNotOnVolume,
+}
+
+// VerifyHome checks from inside a running container that HOME is
+// /claude-env/home, exists, is writable by the container user, and lives on
+// the mounted volume rather than the container's overlay filesystem. When
+// any of these fails, tools write credentials somewhere that vanishes with the
+// container. Each failure wraps ErrHomeNotPersisted, ErrHomeMissing,
+// ErrHomeNotWritable, or ErrHomeNotOnVolume. Only containers started with
+// a persistent HOME (see ContainerConfig.EphemeralHome) pass. Returns *ContainerNotFoundError if it is not running.
+func (m *Manager) VerifyHome(containerName string) error {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return err
+	}
+	if !m.IsRunning(containerName) {
+		return &ContainerNotFoundError{Name: containerName}
+	}
+
+	output, err := m.getCommandOutputWithTimeout(m.timeouts.Quick, "docker", "exec", containerName,
+		"sh", "-c", verifyHomeScript, "sh", constants.ContainerHomePath, constants.ContainerVolumePath)
+	if err == nil {
+		return nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if homeErr, ok := homeExitErrors[exitErr.ExitCode()]; ok {
+			if homeErr == ErrHomeNotPersisted {
+				return fmt.Errorf("%w: HOME is %q, want %s", homeErr, string(output), constants.ContainerHomePath)
+			}
+			return fmt.Errorf("%w: %s in %s", homeErr, constants.ContainerHomePath, containerName)
+		}
+		if stderr := strings.TrimSpace(string(exitErr.Stderr)); stderr != "" {
+			return fmt.Errorf("failed to verify HOME in %s: %w: %s", containerName, err, stderr)
+		}
+	}
+	return fmt.Errorf("failed to verify HOME in %s: %w", containerName, err)
+}
+
+// persistHome reports whether HOME is set to the volume: always, unless
+// EphemeralHome or Ephemeral is set.
+func (c *ContainerConfig) persistHome() bool {
+	return !c.EphemeralHome && !c.Ephemeral
+}
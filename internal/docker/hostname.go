@@ -0,0 +1,86 @@
+package docker
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxHostnameLength is the RFC 1123 limit for a single DNS label.
+const maxHostnameLength = 63
+
+// validHostnamePattern matches an RFC 1123 DNS label.
+var validHostnamePattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
+
+// invalidHostnameChars matches runs of characters not allowed in a DNS label.
+var invalidHostnameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// validateHostname checks that a hostname is a single RFC 1123 DNS label.
+// Empty is allowed and means the default.
+func validateHostname(hostname string) error {
+	if hostname == "" {
+		return nil
+	}
+	if len(hostname) > maxHostnameLength || !validHostnamePattern.MatchString(hostname) {
+		return fmt.Errorf("invalid hostname %q: must be a DNS label of letters, digits and '-' (max %d characters)", hostname, maxHostnameLength)
+	}
+	return nil
+}
+
+// hostnameForRepo derives a stable hostname from a repo ID, which has the
+// shape repo.NormalizeRemoteURL produces. The leading host segment of a
+// remote-derived ID is dropped, so "github.com-user-project" becomes
+// "capsule-user-project" and "local-dir" becomes "capsule-local-dir". Returns
+// "" if the repo ID has nothing usable.
+func hostnameForRepo(repoID string) string {
+	name := repoID
+	if host, rest, ok := strings.Cut(name, "-"); ok && strings.Contains(host, ".") {
+		name = rest
+	}
+	return hostnameFromName(name)
+}
+
+// hostnameFromName turns a directory or repo name into "capsule-<name>",
+// replacing characters not allowed in a DNS label and truncating to 63
+// characters. Returns "" if name has nothing usable.
+func hostnameFromName(name string) string {
+	name = invalidHostnameChars.ReplaceAllString(strings.ToLower(name), "-")
+	name = strings.Trim(name, "-")
+	if name == "" {
+		return ""
+	}
+	hostname := "capsule-" + name
+	if len(hostname) > maxHostnameLength {
+		hostname = strings.TrimRight(hostname[:maxHostnameLength], "-")
+	}
+	return hostname
+}
+
+// hostname returns the container hostname: Hostname if set, otherwise one
+// derived from the workspace directory name (the checkout, e.g.
+// "capsule-project"), falling back to RepoID. Repo IDs alone cannot tell
+// the user from the project, since both may contain hyphens. Returns "" to
+// leave Docker's default.
+func (c *ContainerConfig) hostname() string {
+	if c.Hostname != "" {
+		return c.Hostname
+	}
+	if c.RepoID == "" {
+		return ""
+	}
+	if workspace := c.primaryWorkspacePath(); workspace != "" {
+		if hostname := hostnameFromName(filepath.Base(workspace)); hostname != "" {
+			return hostname
+		}
+	}
+	return hostnameForRepo(c.RepoID)
+}
+
+// primaryWorkspacePath returns the host path of the first workspace.
+func (c *ContainerConfig) primaryWorkspacePath() string {
+	if len(c.Workspaces) > 0 {
+		return c.Workspaces[0].HostPath
+	}
+	return c.WorkspacePath
+}
@@ -0,0 +1,62 @@
+package docker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHostnameForRepo(t *testing.T) {
+	tests := []struct {
+		repoID string
+		want   string
+	}{
+		{"github.com-user-project", "capsule-user-project"},
+		{"gitlab.example.com-group-My_Project.v2", "capsule-group-my-project-v2"},
+		{"local-dir", "capsule-local-dir"},
+		{"project-1a2b3c4d", "capsule-project-1a2b3c4d"},
+		{"github.com-___", ""},
+		{"", ""},
+		{strings.Repeat("a", 100), "capsule-" + strings.Repeat("a", 55)},
+	}
+	for _, tt := range tests {
+		got := hostnameForRepo(tt.repoID)
+		if got != tt.want {
+			t.Errorf("hostnameForRepo(%q) = %q, want %q", tt.repoID, got, tt.want)
+		}
+		if err := validateHostname(got); err != nil {
+			t.Errorf("hostnameForRepo(%q) = %q is not valid: %v", tt.repoID, got, err)
+		}
+	}
+}
+
+func TestContainerConfigHostname(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ContainerConfig
+		want   string
+	}{
+		{"explicit", ContainerConfig{Hostname: "box", RepoID: "github.com-user-project"}, "box"},
+		{"no repo", ContainerConfig{WorkspacePath: "/src/project"}, ""},
+		{"workspace dir", ContainerConfig{RepoID: "github.com-user-project", WorkspacePath: "/src/project"}, "capsule-project"},
+		{"first workspace", ContainerConfig{RepoID: "github.com-user-project", Workspaces: []WorkspaceMount{{HostPath: "/src/app"}, {HostPath: "/src/lib"}}}, "capsule-app"},
+		{"repo fallback", ContainerConfig{RepoID: "github.com-user-project"}, "capsule-user-project"},
+	}
+	for _, tt := range tests {
+		if got := tt.config.hostname(); got != tt.want {
+			t.Errorf("%s: hostname() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateHostname(t *testing.T) {
+	for _, h := range []string{"", "capsule", "My-Box-1"} {
+		if err := validateHostname(h); err != nil {
+			t.Errorf("validateHostname(%q) error = %v", h, err)
+		}
+	}
+	for _, h := range []string{"-capsule", "capsule-", "cap.sule", "cap_sule", strings.Repeat("a", 64)} {
+		if err := validateHostname(h); err == nil {
+			t.Errorf("validateHostname(%q) = nil, want error", h)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Idle monitor polling configuration
+const idlePollInterval = 30 * time.Second
+
+// IdleThreshold defines the activity level below which a container is considered idle.
+type IdleThreshold struct {
+	// CPUPercent is the CPU usage at or below which a sample counts as idle.
+	CPUPercent float64
+	// IOBytes is the combined block and network I/O between two samples
+	// at or below which a sample counts as idle.
+	IOBytes uint64
+}
+
+// DefaultIdleThreshold is used when no threshold has been configured.
+var DefaultIdleThreshold = IdleThreshold{
+	CPUPercent: 1.0,
+	IOBytes:    64 * 1024,
+}
+
+// SetIdleThreshold configures the activity threshold used by AutoStopWhenIdle.
+func (m *Manager) SetIdleThreshold(threshold IdleThreshold) {
+	m.idleThreshold = threshold
+}
+
+// AutoStopWhenIdle polls the container's resource usage and stops it once activity
+// has stayed below the idle threshold for the given duration.
+// It returns nil when the container stops (for any reason), or the context error
+// if the context is cancelled first.
+func (m *Manager) AutoStopWhenIdle(ctx context.Context, containerName string, idle time.Duration) error {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return err
+	}
+	if idle <= 0 {
+		return fmt.Errorf("idle duration must be positive, got %v", idle)
+	}
+
+	threshold := m.idleThreshold
+	if threshold == (IdleThreshold{}) {
+		threshold = DefaultIdleThreshold
+	}
+
+	// Poll at least a few times within the idle window
+	interval := idlePollInterval
+	if idle/4 < interval {
+		interval = idle / 4
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastIO uint64
+	var haveSample bool
+	idleSince := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		if !m.IsRunning(containerName) {
+			return nil
+		}
+
+		stats, err := m.Stats(containerName)
+		if err != nil {
+			// Transient stats failures shouldn't count as activity or idleness
+			continue
+		}
+
+		totalIO := stats.TotalIO()
+		active := stats.CPUPercent > threshold.CPUPercent
+		// Counters reset when the container restarts, which also counts as activity
+		if haveSample && (totalIO < lastIO || totalIO-lastIO > threshold.IOBytes) {
+			active = true
+		}
+		lastIO = totalIO
+		haveSample = true
+
+		if active {
+			idleSince = time.Now()
+			continue
+		}
+
+		if time.Since(idleSince) >= idle {
+			if err := m.Stop(containerName); err != nil {
+				return fmt.Errorf("failed to stop idle container: %w", err)
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrIDMapUnsupported is returned when IDMapWorkspace is set but the engine or
+// kernel cannot create idmapped mounts.
+var ErrIDMapUnsupported = errors.New("idmapped workspace mounts are not supported on this engine/kernel")
+
+// Idmapped mounts need a native Linux engine on kernel 5.12 or newer
+const (
+	idmapMinKernelMajor = 5
+	idmapMinKernelMinor = 12
+)
+
+// checkIDMapSupport verifies the engine can honor IDMapWorkspace.
+func (m *Manager) checkIDMapSupport() error {
+	info, err := m.engineInfo()
+	if err != nil {
+		return err
+	}
+	if kind := classifyEngine(info); kind != EngineNative {
+		return fmt.Errorf("%w: engine is %s, not a native Linux daemon", ErrIDMapUnsupported, kind)
+	}
+	if !kernelAtLeast(info.KernelVersion, idmapMinKernelMajor, idmapMinKernelMinor) {
+		return fmt.Errorf("%w: kernel %q is older than %d.%d", ErrIDMapUnsupported,
+			info.KernelVersion, idmapMinKernelMajor, idmapMinKernelMinor)
+	}
+	return nil
+}
+
+// kernelAtLeast reports whether a kernel version string like "6.8.0-45-generic"
+// is at least major.minor. Unparseable versions report false.
+func kernelAtLeast(version string, major, minor int) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	gotMajor, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	minorDigits := parts[1]
+	if idx := strings.IndexFunc(minorDigits, func(r rune) bool { return r < '0' || r > '9' }); idx >= 0 {
+		minorDigits = minorDigits[:idx]
+	}
+	gotMinor, err := strconv.Atoi(minorDigits)
+	if err != nil {
+		return false
+	}
+	return gotMajor > major || (gotMajor == major && gotMinor >= minor)
+}
+
+// idmapMountOptions returns the --mount options that map container root to the
+// invoking host user, so files created in the workspace are owned by them.
+func idmapMountOptions(uid, gid int) string {
+	return fmt.Sprintf(",bind-nonrecursive=true,idmap=uids=0-%d-1;gids=0-%d-1", uid, gid)
+}
+
+// workspaceMountOptions returns extra --mount options for workspace bind mounts.
+func workspaceMountOptions(config ContainerConfig) string {
+	if !config.IDMapWorkspace {
+		return ""
+	}
+	return idmapMountOptions(os.Getuid(), os.Getgid())
+}
@@ -0,0 +1,25 @@
+package docker
+
+import "testing"
+
+func TestKernelAtLeast(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"6.8.0-45-generic", true},
+		{"5.12.0", true},
+		{"5.15.153.1-microsoft-standard-WSL2", true},
+		{"5.11.22", false},
+		{"4.19.0-26-amd64", false},
+		{"5.12-rc1", true},
+		{"", false},
+		{"linuxkit", false},
+	}
+
+	for _, tt := range tests {
+		if got := kernelAtLeast(tt.version, 5, 12); got != tt.want {
+			t.Errorf("kernelAtLeast(%q, 5, 12) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
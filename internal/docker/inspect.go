@@ -0,0 +1,344 @@
+package docker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// Container labels applied by Start
+const (
+	// LabelManaged marks containers created by capsule.
+	LabelManaged = "capsule.managed"
+	// LabelRepo records the repository ID the container serves.
+	LabelRepo = "capsule.repo"
+	// LabelSession records the session or user that started the container.
+	LabelSession = "capsule.session"
+	// LabelWorkspace records where the workspace is mounted in the container.
+	LabelWorkspace = "capsule.workspace"
+)
+
+// ContainerNotFoundError is returned when a container does not exist.
+type ContainerNotFoundError struct {
+	Name string
+}
+
+func (e *ContainerNotFoundError) Error() string {
+	return fmt.Sprintf("container %s not found", e.Name)
+}
+
+// containerMount mirrors a single entry of .Mounts in `docker inspect` output.
+type containerMount struct {
+	Type        string `json:"Type"`
+	Name        string `json:"Name"`
+	Source      string `json:"Source"`
+	Destination string `json:"Destination"`
+	RW          bool   `json:"RW"`
+}
+
+// containerInspect mirrors the subset of `docker inspect` output capsule uses.
+type containerInspect struct {
+	Name   string `json:"Name"`
+	Config struct {
+		Image      string            `json:"Image"`
+		Labels     map[string]string `json:"Labels"`
+		Env        []string          `json:"Env"`
+		Entrypoint []string          `json:"Entrypoint"`
+		Cmd        []string          `json:"Cmd"`
+		WorkingDir string            `json:"WorkingDir"`
+	} `json:"Config"`
+	HostConfig struct {
+		Memory    int64 `json:"Memory"`
+		NanoCpus  int64 `json:"NanoCpus"`
+		LogConfig struct {
+			Type   string            `json:"Type"`
+			Config map[string]string `json:"Config"`
+		} `json:"LogConfig"`
+	} `json:"HostConfig"`
+	Mounts []containerMount `json:"Mounts"`
+	State  struct {
+		Status    string `json:"Status"`
+		Running   bool   `json:"Running"`
+		StartedAt string `json:"StartedAt"`
+	} `json:"State"`
+}
+
+// inspectContainer returns the parsed `docker inspect` output for a container.
+// Returns *ContainerNotFoundError if the container does not exist.
+func (m *Manager) inspectContainer(containerName string) (*containerInspect, error) {
+	output, err := m.getCommandOutputWithTimeout(m.timeouts.Command, "docker", "inspect", "--type", "container", containerName)
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && strings.Contains(string(exitErr.Stderr), "No such") {
+			return nil, &ContainerNotFoundError{Name: containerName}
+		}
+		return nil, fmt.Errorf("failed to inspect container %s: %w", containerName, err)
+	}
+
+	var results []containerInspect
+	if err := json.Unmarshal(output, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse inspect output for %s: %w", containerName, err)
+	}
+	if len(results) == 0 {
+		return nil, &ContainerNotFoundError{Name: containerName}
+	}
+	return &results[0], nil
+}
+
+// mountSource returns the host source of the mount at the given container path.
+func (c *containerInspect) mountSource(destination string) string {
+	for _, mount := range c.Mounts {
+		if mount.Destination == destination {
+			return mount.Source
+		}
+	}
+	return ""
+}
+
+// workspaceTarget returns where the container's workspace is mounted: the
+// capsule.workspace label, or for containers created before it, /workspace if
+// mounted there, else a bind mount at its own host path (MirrorHostPath).
+func (c *containerInspect) workspaceTarget() string {
+	if target := c.Config.Labels[LabelWorkspace]; target != "" {
+		return target
+	}
+	if c.mountSource(constants.ContainerWorkspacePath) != "" {
+		return constants.ContainerWorkspacePath
+	}
+	for _, mount := range c.Mounts {
+		if mount.Type == "bind" && mount.Destination != DockerSocketPath && filepath.Clean(mount.Source) == mount.Destination {
+			return mount.Destination
+		}
+	}
+	return constants.ContainerWorkspacePath
+}
+
+// containerWorkspaceTarget returns where the named container's workspace is
+// mounted, falling back to /workspace if it cannot be inspected.
+func (m *Manager) containerWorkspaceTarget(containerName string) string {
+	info, err := m.inspectContainer(containerName)
+	if err != nil {
+		return constants.ContainerWorkspacePath
+	}
+	return info.workspaceTarget()
+}
+
+// ReconstructRunArgs rebuilds the `docker run` arguments equivalent to how the
+// container was started, from its mounts, environment, entrypoint, working
+// directory, and labels. Environment variables inherited from the image are
+// included, since docker inspect does not distinguish them. The result starts
+// with "run", like buildRunArgs. Returns *ContainerNotFoundError if the container
+// does not exist.
+func (m *Manager) ReconstructRunArgs(containerName string) ([]string, error) {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := m.inspectContainer(containerName)
+	if err != nil {
+		return nil, err
+	}
+	return info.runArgs(), nil
+}
+
+// runArgs converts inspect output back into `docker run` arguments.
+func (c *containerInspect) runArgs() []string {
+	args := []string{"run", "-d", "--name", strings.TrimPrefix(c.Name, "/")}
+
+	for _, mount := range c.Mounts {
+		spec := "type=" + mount.Type
+		switch mount.Type {
+		case "volume":
+			spec += ",source=" + mount.Name
+		case "tmpfs":
+		default:
+			spec += ",source=" + mount.Source
+		}
+		spec += ",target=" + mount.Destination
+		if !mount.RW {
+			spec += ",readonly"
+		}
+		args = append(args, "--mount", spec)
+	}
+
+	if c.Config.WorkingDir != "" {
+		args = append(args, "-w", c.Config.WorkingDir)
+	}
+	for _, env := range c.Config.Env {
+		args = append(args, "-e", env)
+	}
+
+	labelKeys := make([]string, 0, len(c.Config.Labels))
+	for key := range c.Config.Labels {
+		labelKeys = append(labelKeys, key)
+	}
+	sort.Strings(labelKeys)
+	for _, key := range labelKeys {
+		args = append(args, "--label", key+"="+c.Config.Labels[key])
+	}
+
+	// --entrypoint takes a single executable; the rest of the entrypoint
+	// precedes the command as arguments
+	cmd := c.Config.Cmd
+	if len(c.Config.Entrypoint) > 0 {
+		args = append(args, "--entrypoint", c.Config.Entrypoint[0])
+		cmd = append(append([]string{}, c.Config.Entrypoint[1:]...), cmd...)
+	}
+	args = append(args, c.Config.Image)
+	return append(args, cmd...)
+}
+
+// MountMismatchError is returned when a container's mounts differ from its configuration.
+type MountMismatchError struct {
+	ContainerName string
+	Target        string // Mount destination inside the container
+	Want          string // Host source from the configuration
+	Got           string // Host source of the container's mount; empty if not mounted
+}
+
+func (e *MountMismatchError) Error() string {
+	if e.Got == "" {
+		return fmt.Sprintf("container %s has nothing mounted at %s (want %s); stop it to recreate", e.ContainerName, e.Target, e.Want)
+	}
+	return fmt.Sprintf("container %s mounts %s at %s, want %s; stop it to recreate", e.ContainerName, e.Got, e.Target, e.Want)
+}
+
+// VerifyMounts checks that the container's volume and workspace mounts come from
+// the host paths in config. It returns *MountMismatchError for the first mount that differs.
+func (m *Manager) VerifyMounts(containerName string, config ContainerConfig) error {
+	info, err := m.inspectContainer(containerName)
+	if err != nil {
+		return err
+	}
+
+	var expected [][2]string
+	if !config.Ephemeral {
+		expected = append(expected, [2]string{constants.ContainerVolumePath, config.VolumeMountPoint})
+	}
+	if len(config.Workspaces) > 0 {
+		for _, w := range config.Workspaces {
+			expected = append(expected, [2]string{w.ContainerPath(), w.HostPath})
+		}
+	} else {
+		expected = append(expected, [2]string{config.workspaceTarget(), config.WorkspacePath})
+	}
+
+	for _, e := range expected {
+		target, want := e[0], filepath.Clean(e[1])
+		got := info.mountSource(target)
+		if got == "" || filepath.Clean(got) != want {
+			return &MountMismatchError{ContainerName: containerName, Target: target, Want: want, Got: got}
+		}
+	}
+	return nil
+}
+
+// ResolveContext reports which repository and workspace a container serves,
+// based on its capsule.repo label and the source of its workspace mount.
+// Containers created before labels were introduced return an error.
+func (m *Manager) ResolveContext(containerName string) (repoID, workspace string, err error) {
+	containerName, err = ResolveContainerName(containerName)
+	if err != nil {
+		return "", "", err
+	}
+
+	info, err := m.inspectContainer(containerName)
+	if err != nil {
+		return "", "", err
+	}
+
+	repoID = info.Config.Labels[LabelRepo]
+	if repoID == "" {
+		return "", "", fmt.Errorf("container %s has no %s label (created by an older capsule version?)", containerName, LabelRepo)
+	}
+
+	return repoID, info.mountSource(info.workspaceTarget()), nil
+}
+
+// EntrypointOf returns the entrypoint and command a container was created with,
+// from Config.Entrypoint and Config.Cmd of `docker inspect`. For containers
+// started by capsule this is the tail keep-alive; for UseImageEntrypoint it is
+// whatever the image runs. Returns *ContainerNotFoundError if the container
+// does not exist.
+func (m *Manager) EntrypointOf(containerName string) (entrypoint []string, cmd []string, err error) {
+	containerName, err = ResolveContainerName(containerName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := m.inspectContainer(containerName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return info.Config.Entrypoint, info.Config.Cmd, nil
+}
+
+// ListCapsuleContainers returns the names of all containers (running or stopped)
+// carrying the capsule.managed label. When CAPSULE_NAME_PREFIX is set, only
+// containers under that prefix are returned, and when a session filter is set,
+// only that session's containers.
+func (m *Manager) ListCapsuleContainers() ([]string, error) {
+	prefix, err := NamePrefix()
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"ps", "-a", "--filter", "label=" + LabelManaged + "=true"}, m.sessionFilterArgs()...)
+	output, err := m.getCommandOutputWithTimeout(m.timeouts.Command, "docker", append(args, "--format", "{{.Names}}")...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list capsule containers: %w", err)
+	}
+
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if name := strings.TrimSpace(line); name != "" && matchesNamePrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// StopByRepo stops every capsule container whose capsule.repo label matches repoID,
+// regardless of its name, within CAPSULE_NAME_PREFIX and the session filter when set. It returns the names
+// of the containers stopped; errors from individual containers are collected so one
+// failure does not prevent the rest. Returns an empty slice when no container matches.
+func (m *Manager) StopByRepo(repoID string) ([]string, error) {
+	if repoID == "" {
+		return nil, fmt.Errorf("repo ID cannot be empty")
+	}
+	prefix, err := NamePrefix()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"ps", "-a",
+		"--filter", "label=" + LabelManaged + "=true",
+		"--filter", "label=" + LabelRepo + "=" + repoID,
+	}
+	args = append(args, m.sessionFilterArgs()...)
+	output, err := m.getCommandOutputWithTimeout(m.timeouts.Command, "docker", append(args, "--format", "{{.Names}}")...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers for repo %s: %w", repoID, err)
+	}
+
+	stopped := []string{}
+	var errs []error
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		name := strings.TrimSpace(line)
+		if name == "" || !matchesNamePrefix(name, prefix) {
+			continue
+		}
+		if err := m.Stop(name); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", name, err))
+			continue
+		}
+		stopped = append(stopped, name)
+	}
+	return stopped, errors.Join(errs...)
+}
@@ -0,0 +1,118 @@
+package docker
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestContainerInspectRunArgs(t *testing.T) {
+	output := `[{
+		"Name": "/claude-capsule-abc",
+		"Config": {
+			"Image": "claude-capsule:latest",
+			"Labels": {"capsule.repo": "abc", "capsule.managed": "true"},
+			"Env": ["PATH=/usr/bin", "HOME=/claude-env/home"],
+			"Entrypoint": ["tail", "-f"],
+			"Cmd": ["/dev/null"],
+			"WorkingDir": "/workspace"
+		},
+		"Mounts": [
+			{"Type": "bind", "Source": "/Volumes/Capsule-abc", "Destination": "/claude-env", "RW": true},
+			{"Type": "volume", "Name": "cache", "Source": "/var/lib/docker/volumes/cache/_data", "Destination": "/cache", "RW": false}
+		]
+	}]`
+
+	var results []containerInspect
+	if err := json.Unmarshal([]byte(output), &results); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"run", "-d", "--name", "claude-capsule-abc",
+		"--mount", "type=bind,source=/Volumes/Capsule-abc,target=/claude-env",
+		"--mount", "type=volume,source=cache,target=/cache,readonly",
+		"-w", "/workspace",
+		"-e", "PATH=/usr/bin",
+		"-e", "HOME=/claude-env/home",
+		"--label", "capsule.managed=true",
+		"--label", "capsule.repo=abc",
+		"--entrypoint", "tail",
+		"claude-capsule:latest",
+		"-f", "/dev/null",
+	}
+	if got := results[0].runArgs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("runArgs() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestContainerInspectDrift(t *testing.T) {
+	output := `[{
+		"Config": {
+			"Image": "claude-capsule:latest",
+			"Env": ["PATH=/usr/bin", "HOME=/claude-env/home"],
+			"WorkingDir": "/workspace"
+		},
+		"HostConfig": {"Memory": 4294967296, "NanoCpus": 0},
+		"Mounts": [
+			{"Type": "bind", "Source": "/Volumes/Capsule-abc", "Destination": "/claude-env", "RW": true},
+			{"Type": "bind", "Source": "/src/old", "Destination": "/workspace", "RW": true}
+		]
+	}]`
+	var results []containerInspect
+	if err := json.Unmarshal([]byte(output), &results); err != nil {
+		t.Fatal(err)
+	}
+
+	config := ContainerConfig{
+		ImageName:        "claude-capsule:latest",
+		VolumeMountPoint: "/Volumes/Capsule-abc",
+		WorkspacePath:    "/src/project",
+		ExtraArgs:        []string{"--memory=4g"},
+	}
+	var fields []string
+	for _, d := range results[0].drift(config) {
+		fields = append(fields, d.Field)
+	}
+	if want := []string{"mount /workspace"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("drift fields = %v, want %v", fields, want)
+	}
+
+	config.WorkspacePath = "/src/old"
+	config.ExtraArgs = []string{"--cpus", "2"}
+	fields = nil
+	for _, d := range results[0].drift(config) {
+		fields = append(fields, d.Field)
+	}
+	if want := []string{"memory", "cpus"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("drift fields = %v, want %v", fields, want)
+	}
+}
+
+func TestContainerInspectWorkspaceTarget(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		mounts []containerMount
+		want   string
+	}{
+		{"label", map[string]string{LabelWorkspace: "/Users/me/project"}, nil, "/Users/me/project"},
+		{"default mount", nil, []containerMount{
+			{Type: "bind", Source: "/Volumes/Capsule-abc", Destination: "/claude-env"},
+			{Type: "bind", Source: "/Users/me/project", Destination: "/workspace"},
+		}, "/workspace"},
+		{"mirrored mount without label", nil, []containerMount{
+			{Type: "bind", Source: "/Volumes/Capsule-abc", Destination: "/claude-env"},
+			{Type: "bind", Source: "/var/run/docker.sock", Destination: "/var/run/docker.sock"},
+			{Type: "bind", Source: "/Users/me/project", Destination: "/Users/me/project"},
+		}, "/Users/me/project"},
+		{"no workspace mount", nil, nil, "/workspace"},
+	}
+	for _, tt := range tests {
+		var info containerInspect
+		info.Config.Labels = tt.labels
+		info.Mounts = tt.mounts
+		if got := info.workspaceTarget(); got != tt.want {
+			t.Errorf("%s: workspaceTarget() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,10 +1,17 @@
 package docker
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
 )
 
 // validDockerNamePattern validates Docker container and image names.
@@ -31,6 +38,84 @@ func ValidateDockerName(name string) error {
 	return nil
 }
 
+// validImageTagPattern validates the tag portion of an image reference.
+var validImageTagPattern = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+
+// validEnvNamePattern validates environment variable names.
+var validEnvNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validImageDigestPattern validates the digest portion of a pinned image reference.
+var validImageDigestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// validRegistryHostPattern validates a registry host with an optional port (e.g. "registry.local:5000").
+var validRegistryHostPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9.-]*(:[0-9]+)?$`)
+
+// validImagePathComponentPattern validates a repository path component per Docker's
+// reference grammar: lowercase alphanumerics separated by ".", "_", "__", or hyphens.
+var validImagePathComponentPattern = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*$`)
+
+// ValidateImageName checks if a name is a valid image reference.
+// Unlike ValidateDockerName, it follows Docker's image reference rules:
+//   - An optional registry host prefix (e.g. "registry.local:5000/"), recognized
+//     when the first component contains "." or ":" or is "localhost".
+//   - Repository path components must be lowercase.
+//   - An optional tag of up to 128 characters from [a-zA-Z0-9_.-].
+//   - An optional "@sha256:<64 hex>" digest pinning the exact image.
+func ValidateImageName(name string) error {
+	if name == "" {
+		return fmt.Errorf("image name cannot be empty")
+	}
+
+	// Split off the digest, which is excluded from the length limit
+	if idx := strings.Index(name, "@"); idx >= 0 {
+		if digest := name[idx+1:]; !validImageDigestPattern.MatchString(digest) {
+			return fmt.Errorf("invalid image digest %q in %q: must be sha256:<64 lowercase hex>", digest, name)
+		}
+		name = name[:idx]
+		if name == "" {
+			return fmt.Errorf("image name cannot be empty")
+		}
+	}
+	if len(name) > 255 {
+		return fmt.Errorf("image name too long: %d characters (max 255)", len(name))
+	}
+
+	// Split off the tag, which follows the last colon after the last slash
+	repository := name
+	lastSlash := strings.LastIndex(name, "/")
+	if idx := strings.LastIndex(name, ":"); idx > lastSlash {
+		tag := name[idx+1:]
+		if !validImageTagPattern.MatchString(tag) {
+			return fmt.Errorf("invalid image tag %q in %q", tag, name)
+		}
+		repository = name[:idx]
+	}
+
+	components := strings.Split(repository, "/")
+	if len(components) > 1 && isRegistryHost(components[0]) {
+		if !validRegistryHostPattern.MatchString(components[0]) {
+			return fmt.Errorf("invalid registry host %q in %q", components[0], name)
+		}
+		components = components[1:]
+	}
+
+	for _, component := range components {
+		if strings.ToLower(component) != component {
+			return fmt.Errorf("invalid image name %q: repository name must be lowercase", name)
+		}
+		if !validImagePathComponentPattern.MatchString(component) {
+			return fmt.Errorf("invalid image name %q: path component %q must be lowercase alphanumerics separated by '.', '_', or '-'", name, component)
+		}
+	}
+	return nil
+}
+
+// isRegistryHost reports whether the first component of an image name is a
+// registry host rather than a repository path, using Docker's heuristic.
+func isRegistryHost(component string) bool {
+	return strings.ContainsAny(component, ".:") || component == "localhost"
+}
+
 // validatePath checks for path traversal attacks and validates the path is reasonable.
 func validatePath(path, fieldName string) error {
 	if path == "" {
@@ -48,18 +133,265 @@ func validatePath(path, fieldName string) error {
 	return nil
 }
 
+// PullPolicy controls whether `docker run` pulls the image, mirroring docker's --pull flag.
+type PullPolicy string
+
+const (
+	// PullNever fails if the image is not present locally (default).
+	PullNever PullPolicy = "never"
+	// PullMissing pulls the image only if it is not present locally.
+	PullMissing PullPolicy = "missing"
+	// PullAlways pulls the image before every run.
+	PullAlways PullPolicy = "always"
+)
+
+// Validate checks that the pull policy is one of the supported values.
+// The empty policy is valid and means PullNever.
+func (p PullPolicy) Validate() error {
+	switch p {
+	case "", PullNever, PullMissing, PullAlways:
+		return nil
+	default:
+		return fmt.Errorf("invalid pull policy %q: must be one of never, missing, always", string(p))
+	}
+}
+
+// orDefault returns the policy, substituting PullNever for the empty value.
+func (p PullPolicy) orDefault() PullPolicy {
+	if p == "" {
+		return PullNever
+	}
+	return p
+}
+
+// RestartPolicy is passed to `docker run --restart`.
+//
+// The container's entrypoint is `tail -f /dev/null`, which never exits on its
+// own, so RestartOnFailure has no practical effect. RestartUnlessStopped and
+// RestartAlways bring the container back after the Docker daemon restarts,
+// provided the volume is mounted at the same path again.
+type RestartPolicy string
+
+const (
+	// RestartNo never restarts the container (default).
+	RestartNo RestartPolicy = "no"
+	// RestartOnFailure restarts the container when it exits non-zero.
+	RestartOnFailure RestartPolicy = "on-failure"
+	// RestartUnlessStopped restarts the container unless it was stopped explicitly.
+	RestartUnlessStopped RestartPolicy = "unless-stopped"
+	// RestartAlways restarts the container whenever it stops.
+	RestartAlways RestartPolicy = "always"
+)
+
+// Validate checks that the restart policy is one of the supported values.
+// The empty policy is valid and means RestartNo.
+func (p RestartPolicy) Validate() error {
+	switch p {
+	case "", RestartNo, RestartOnFailure, RestartUnlessStopped, RestartAlways:
+		return nil
+	default:
+		return fmt.Errorf("invalid restart policy %q: must be one of no, on-failure, unless-stopped, always", string(p))
+	}
+}
+
+// orDefault returns the policy, substituting RestartNo for the empty value.
+func (p RestartPolicy) orDefault() RestartPolicy {
+	if p == "" {
+		return RestartNo
+	}
+	return p
+}
+
+// WorkspaceMount is a host directory mounted under /workspace/<ContainerSubdir>.
+type WorkspaceMount struct {
+	HostPath        string
+	ContainerSubdir string // Relative path under /workspace (e.g. "app" or "libs/shared")
+
+	// RepoID, if set, gets a _docs symlink inside this workspace.
+	RepoID string
+}
+
+// ContainerPath returns the path of the mount inside the container.
+func (w WorkspaceMount) ContainerPath() string {
+	return path.Join(constants.ContainerWorkspacePath, w.ContainerSubdir)
+}
+
+// ExtraMount is an additional host directory bind-mounted into the container.
+type ExtraMount struct {
+	HostPath      string
+	ContainerPath string // Absolute path inside the container
+	ReadOnly      bool
+
+	// BindPropagation is the mount's bind-propagation option (private,
+	// rprivate, shared, rshared, slave or rslave). Empty uses Docker's default.
+	BindPropagation string
+}
+
+// validBindPropagation lists the bind-propagation values docker accepts.
+var validBindPropagation = map[string]bool{
+	"private": true, "rprivate": true,
+	"shared": true, "rshared": true,
+	"slave": true, "rslave": true,
+}
+
 // ContainerConfig holds configuration for starting a container.
 type ContainerConfig struct {
 	ImageName        string
 	ContainerName    string
 	VolumeMountPoint string
 	WorkspacePath    string
+
+	// Workspaces mounts several host directories under /workspace instead of
+	// WorkspacePath. The working directory is the first workspace. When empty,
+	// WorkspacePath is mounted at /workspace.
+	Workspaces []WorkspaceMount
+
+	// WorkingDir is the shell's starting directory: either relative to /workspace
+	// or an absolute container path. It must lie within a mounted workspace.
+	// Defaults to /workspace, or the first workspace when Workspaces is set.
+	WorkingDir string
+
+	// MirrorHostPath mounts WorkspacePath at the same absolute path inside the
+	// container instead of /workspace, for tools that bake in absolute paths
+	// (debuggers, source maps). WorkingDir is then relative to that path. It
+	// cannot be combined with Workspaces.
+	MirrorHostPath bool
+
+	// Consistency is the --mount consistency for every bind mount: the volume,
+	// workspaces, ExtraMounts and secrets (delegated, cached, or consistent).
+	// Defaults to delegated; ignored outside macOS. See NormalizeConsistency.
+	Consistency string
+
+	// CreateWorkspaceIfMissing makes Start create missing workspace host
+	// directories with constants.DirPermissions. By default Start refuses to
+	// run with a workspace that does not exist.
+	CreateWorkspaceIfMissing bool
+
+	// SessionID is recorded in the capsule.session label so users sharing a host
+	// can scope operations to their own containers (see SetSessionFilter).
+	// Defaults to the OS username.
+	SessionID string
+
+	// RepoID is recorded in the capsule.repo label so the container can be
+	// mapped back to its repository. Optional.
+	RepoID string
+
+	// Hostname is passed as --hostname and must be an RFC 1123 DNS label.
+	// Defaults to one derived from the workspace directory name (e.g.
+	// "capsule-project") when RepoID is set, or Docker's random hostname when
+	// RepoID is empty.
+	Hostname string
+
+	// CgroupParent is passed as --cgroup-parent so hosts can account for and
+	// limit all capsules together, e.g. "capsules.slice" under systemd.
+	// Empty keeps Docker's default placement.
+	CgroupParent string
+
+	// Ephemeral runs without the encrypted volume: /claude-env is a tmpfs and
+	// HOME stays in the container, so nothing outlives the container.
+	// VolumeMountPoint must be empty. It implies EphemeralHome.
+	Ephemeral bool
+
+	// EphemeralHome keeps the image's default HOME, so nothing written there
+	// outlives the container. By default HOME is set to the encrypted volume so
+	// credentials and shell state survive container removal.
+	EphemeralHome bool
+
+	// PullPolicy is passed to `docker run --pull`. Defaults to PullNever, which
+	// requires the image to already exist locally.
+	PullPolicy PullPolicy
+
+	// Platform is the os/arch[/variant] to run and pull, e.g. "linux/amd64" to
+	// use an amd64-only image under emulation on Apple Silicon. Defaults to the
+	// host architecture; see ResolvePlatform.
+	Platform string
+
+	// RestartPolicy is passed to `docker run --restart`. Defaults to RestartNo.
+	// It does not apply to one-shot containers from RunOnce.
+	RestartPolicy RestartPolicy
+
+	// ReuseStoppedContainer makes Start restart an existing stopped container
+	// with `docker start`, keeping its writable layer, instead of removing and
+	// recreating it. Its mounts must still match the configuration.
+	ReuseStoppedContainer bool
+
+	// UseImageEntrypoint runs the image's own ENTRYPOINT and CMD, e.g. an init or
+	// supervisor, instead of overriding them with `tail -f /dev/null`. The image
+	// must then keep the container running by itself.
+	UseImageEntrypoint bool
+
+	// DNS and DNSSearch are passed as --dns and --dns-search. When empty,
+	// the container inherits Docker's DNS configuration.
+	DNS       []string
+	DNSSearch []string
+
+	// InheritEnv names host environment variables to pass into the container,
+	// e.g. HTTP_PROXY and NO_PROXY. Only the listed names are forwarded, and
+	// unset variables are skipped. Values are passed as `-e NAME` so they do not
+	// appear in process listings, though they are visible in `docker inspect`.
+	InheritEnv []string
+
+	// MountDockerSocket bind-mounts the Docker socket read-write so the container
+	// can run docker commands. This grants root-equivalent access to the host.
+	MountDockerSocket bool
+
+	// ExtraMounts are additional bind mounts. Their container paths must not
+	// overlap each other or the volume and workspace mounts.
+	ExtraMounts []ExtraMount
+
+	// AdditionalVolumes mounts further capsule volumes, e.g. a shared team docs
+	// volume, with the same consistency as the primary volume. Their container
+	// paths must not overlap /claude-env, /workspace, or any other mount. The
+	// _docs symlink always points into the primary volume.
+	AdditionalVolumes []VolumeMount
+
+	// PostStartCommands run in order via docker exec in RunPostStart, after the
+	// workspace symlink is set up. Each is an argv, not a shell string.
+	PostStartCommands [][]string
+	// PostStartContinueOnError runs every post-start command even if one fails.
+	PostStartContinueOnError bool
+
+	// IDMapWorkspace mounts the workspace with an idmapped mount that maps
+	// container root to the invoking host user, so files created by the container
+	// are not root-owned on the host. Requires a native Linux engine on kernel
+	// 5.12+; Start returns ErrIDMapUnsupported otherwise.
+	IDMapWorkspace bool
+
+	// SecretFiles maps container paths to host files that are bind-mounted
+	// read-only, so credentials reach the container without appearing in its
+	// environment. Processes in the container can read them, but unlike env vars
+	// they do not show up in `docker inspect` or process listings. Host files must
+	// be readable only by their owner (e.g. mode 0400).
+	SecretFiles map[string]string
+
+	// OOMScoreAdj is passed as --oom-score-adj (-1000 to 1000); higher values
+	// make the host's OOM killer pick the container first. Nil leaves Docker's
+	// default.
+	OOMScoreAdj *int
+	// OOMKillDisable passes --oom-kill-disable. Without a memory limit in
+	// ExtraArgs the container can then exhaust host memory; see Warnings.
+	OOMKillDisable bool
+
+	// BlkioWeight is passed as --blkio-weight (10 to 1000), the container's
+	// relative share of block IO. Nil leaves Docker's default.
+	BlkioWeight *uint16
+	// DeviceReadBps and DeviceWriteBps cap read and write bandwidth per host
+	// device, passed as --device-read-bps and --device-write-bps, so a
+	// disk-heavy session cannot starve the rest of the machine.
+	DeviceReadBps  []BlkioDeviceLimit
+	DeviceWriteBps []BlkioDeviceLimit
+
+	// ExtraArgs are passed to `docker run` after the managed flags and before the
+	// image. They are NOT validated beyond rejecting flags that would break
+	// capsule's own (--name, --entrypoint, --detach, --rm, --restart); use at
+	// your own risk.
+	ExtraArgs []string
 }
 
 // Validate checks that the container configuration is valid.
 func (c *ContainerConfig) Validate() error {
 	// Validate image name
-	if err := ValidateDockerName(c.ImageName); err != nil {
+	if err := ValidateImageName(c.ImageName); err != nil {
 		return fmt.Errorf("invalid image name: %w", err)
 	}
 	// Validate container name
@@ -67,42 +399,439 @@ func (c *ContainerConfig) Validate() error {
 		return fmt.Errorf("invalid container name: %w", err)
 	}
 	// Validate volume mount point
-	if err := validatePath(c.VolumeMountPoint, "volume mount point"); err != nil {
+	if c.Ephemeral {
+		if c.VolumeMountPoint != "" {
+			return fmt.Errorf("volume mount point must be empty in ephemeral mode")
+		}
+	} else if err := validatePath(c.VolumeMountPoint, "volume mount point"); err != nil {
+		return err
+	}
+	// Validate workspace path(s)
+	if len(c.Workspaces) > 0 {
+		if c.WorkspacePath != "" {
+			return fmt.Errorf("workspace path and workspaces are mutually exclusive")
+		}
+		if err := validateWorkspaceMounts(c.Workspaces); err != nil {
+			return err
+		}
+	} else if err := validatePath(c.WorkspacePath, "workspace path"); err != nil {
+		return err
+	}
+	// Validate session ID
+	if err := validateSessionID(c.SessionID); err != nil {
+		return err
+	}
+	// Validate hostname
+	if err := validateHostname(c.Hostname); err != nil {
+		return err
+	}
+	// Validate cgroup parent
+	if err := validateCgroupParent(c.CgroupParent); err != nil {
+		return err
+	}
+	// Validate mount consistency
+	if _, err := NormalizeConsistency(c.Consistency); err != nil {
+		return err
+	}
+	if err := c.validateMirrorHostPath(); err != nil {
+		return err
+	}
+	// Validate extra mounts, secret files, and additional volumes, which must
+	// not overlap each other
+	if err := validateSecretFiles(c.SecretFiles); err != nil {
+		return err
+	}
+	if err := validateAdditionalVolumes(c.AdditionalVolumes); err != nil {
 		return err
 	}
-	// Validate workspace path
-	if err := validatePath(c.WorkspacePath, "workspace path"); err != nil {
+	mounts := append(append([]ExtraMount{}, c.ExtraMounts...), c.secretMounts()...)
+	if err := validateExtraMountsAt(append(mounts, c.additionalVolumeMounts()...), c.workspaceTarget()); err != nil {
 		return err
 	}
+	// Validate extra args
+	if err := validateExtraArgs(c.ExtraArgs); err != nil {
+		return err
+	}
+	// Validate inherited environment variable names
+	for _, name := range c.InheritEnv {
+		if !validEnvNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid environment variable name %q", name)
+		}
+		if name == "HOME" && c.persistHome() {
+			return fmt.Errorf("cannot inherit HOME: it is set to the volume unless EphemeralHome is set")
+		}
+	}
+	// Validate post-start commands
+	for i, command := range c.PostStartCommands {
+		if len(command) == 0 || command[0] == "" {
+			return fmt.Errorf("post-start command %d is empty", i)
+		}
+	}
+	// Validate working directory
+	if err := c.validateWorkingDir(); err != nil {
+		return err
+	}
+	// Validate pull policy and platform
+	if err := c.PullPolicy.Validate(); err != nil {
+		return err
+	}
+	if err := validatePlatform(c.Platform); err != nil {
+		return err
+	}
+	if err := c.RestartPolicy.Validate(); err != nil {
+		return err
+	}
+	if c.OOMScoreAdj != nil && (*c.OOMScoreAdj < minOOMScoreAdj || *c.OOMScoreAdj > maxOOMScoreAdj) {
+		return fmt.Errorf("invalid OOM score adjustment %d: must be between %d and %d", *c.OOMScoreAdj, minOOMScoreAdj, maxOOMScoreAdj)
+	}
+	if err := c.validateBlkio(); err != nil {
+		return err
+	}
+	// Validate DNS settings
+	for _, server := range c.DNS {
+		if net.ParseIP(server) == nil {
+			return fmt.Errorf("invalid DNS server %q: must be an IP address", server)
+		}
+	}
+	for _, domain := range c.DNSSearch {
+		if strings.TrimSpace(domain) == "" {
+			return fmt.Errorf("DNS search domain cannot be empty")
+		}
+	}
+	return nil
+}
+
+// workspaceHostPaths returns the host directories mounted as workspaces.
+func (c *ContainerConfig) workspaceHostPaths() []string {
+	if len(c.Workspaces) == 0 {
+		return []string{c.WorkspacePath}
+	}
+	paths := make([]string, 0, len(c.Workspaces))
+	for _, w := range c.Workspaces {
+		paths = append(paths, w.HostPath)
+	}
+	return paths
+}
+
+// validateWorkspaceMounts checks host paths are absolute and container subdirs
+// are relative, unique, and not nested inside one another.
+func validateWorkspaceMounts(workspaces []WorkspaceMount) error {
+	subdirs := make([]string, 0, len(workspaces))
+	for i, w := range workspaces {
+		if err := validatePath(w.HostPath, fmt.Sprintf("workspace %d host path", i)); err != nil {
+			return err
+		}
+		subdir := path.Clean(w.ContainerSubdir)
+		if w.ContainerSubdir == "" || subdir == "." {
+			return fmt.Errorf("workspace %d container subdir is required", i)
+		}
+		if path.IsAbs(subdir) || subdir == ".." || strings.HasPrefix(subdir, "../") {
+			return fmt.Errorf("workspace %d container subdir must be relative to /workspace: %q", i, w.ContainerSubdir)
+		}
+		for _, other := range subdirs {
+			if subdir == other {
+				return fmt.Errorf("duplicate workspace container subdir %q", subdir)
+			}
+			if strings.HasPrefix(subdir, other+"/") || strings.HasPrefix(other, subdir+"/") {
+				return fmt.Errorf("workspace container subdirs %q and %q are nested", other, subdir)
+			}
+		}
+		subdirs = append(subdirs, subdir)
+	}
+	return nil
+}
+
+// validateExtraMounts checks extra mounts use absolute paths and that no container
+// path overlaps another extra mount or the managed mount targets. The one exception
+// is a read-only mount nested inside the workspace, which layers reference
+// material over the writable workspace (e.g. /workspace/.reference). Docker
+// creates the empty mount point directory in the host workspace.
+func validateExtraMounts(mounts []ExtraMount) error {
+	return validateExtraMountsAt(mounts, constants.ContainerWorkspacePath)
+}
+
+// validateExtraMountsAt is validateExtraMounts for a workspace mounted at workspaceTarget.
+func validateExtraMountsAt(mounts []ExtraMount, workspaceTarget string) error {
+	managed := []string{constants.ContainerVolumePath, workspaceTarget, DockerSocketPath}
+	var targets []string
+	for i, m := range mounts {
+		if err := validatePath(m.HostPath, fmt.Sprintf("extra mount %d host path", i)); err != nil {
+			return err
+		}
+		if err := validatePath(m.ContainerPath, fmt.Sprintf("extra mount %d container path", i)); err != nil {
+			return err
+		}
+		target := path.Clean(m.ContainerPath)
+		if target == "/" {
+			return fmt.Errorf("extra mount %d cannot target the container root", i)
+		}
+		if m.BindPropagation != "" && !validBindPropagation[m.BindPropagation] {
+			return fmt.Errorf("extra mount %d has invalid bind propagation %q: must be one of private, rprivate, shared, rshared, slave, rslave", i, m.BindPropagation)
+		}
+
+		inWorkspace := strings.HasPrefix(target, workspaceTarget+"/")
+		if inWorkspace && !m.ReadOnly {
+			return fmt.Errorf("extra mount %d target %q is inside the workspace and must be read-only", i, target)
+		}
+		for _, other := range managed {
+			if inWorkspace && other == workspaceTarget {
+				continue
+			}
+			if pathsOverlap(target, other) {
+				return fmt.Errorf("extra mount %d target %q overlaps %q", i, target, other)
+			}
+		}
+		for _, other := range targets {
+			if pathsOverlap(target, other) {
+				return fmt.Errorf("extra mount %d target %q overlaps %q", i, target, other)
+			}
+		}
+		targets = append(targets, target)
+	}
+	return nil
+}
+
+// reservedRunFlags are `docker run` flags capsule manages itself, mapped to
+// the ContainerConfig field to set instead, if any.
+var reservedRunFlags = map[string]string{
+	"--name":       "",
+	"--entrypoint": "",
+	"-d":           "",
+	"--detach":     "",
+	"--rm":         "",
+	"--restart":    "RestartPolicy",
+	"--platform":   "Platform",
+	"--pull":       "PullPolicy",
+}
+
+// validateCgroupParent checks that a cgroup parent is a path-like value such
+// as "capsules", "/capsules" or "capsules.slice". Empty is allowed.
+func validateCgroupParent(parent string) error {
+	if parent == "" {
+		return nil
+	}
+	if strings.TrimSpace(parent) == "" || strings.ContainsAny(parent, " \t\n,=") || strings.HasPrefix(parent, "-") {
+		return fmt.Errorf("invalid cgroup parent %q: must be a path without whitespace, ',' or '='", parent)
+	}
+	for _, part := range strings.Split(parent, "/") {
+		if part == ".." {
+			return fmt.Errorf("invalid cgroup parent %q: must not contain '..'", parent)
+		}
+	}
+	return nil
+}
+
+// validateExtraArgs rejects extra args that would override managed run flags.
+func validateExtraArgs(args []string) error {
+	for _, arg := range args {
+		flag, _, _ := strings.Cut(arg, "=")
+		field, reserved := reservedRunFlags[flag]
+		if !reserved {
+			continue
+		}
+		if field != "" {
+			return fmt.Errorf("extra arg %q is managed by capsule; set ContainerConfig.%s instead", arg, field)
+		}
+		return fmt.Errorf("extra arg %q is managed by capsule and cannot be overridden", arg)
+	}
 	return nil
 }
 
+// pathsOverlap reports whether two clean container paths are equal or nested.
+func pathsOverlap(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
+
+// WorkDir returns the container working directory for the configuration.
+func (c *ContainerConfig) WorkDir() string {
+	if c.WorkingDir != "" {
+		if path.IsAbs(c.WorkingDir) {
+			return path.Clean(c.WorkingDir)
+		}
+		return path.Join(c.workspaceTarget(), c.WorkingDir)
+	}
+	if len(c.Workspaces) > 0 {
+		return c.Workspaces[0].ContainerPath()
+	}
+	return c.workspaceTarget()
+}
+
+// validateWorkingDir checks that the working directory lies within a mounted workspace.
+func (c *ContainerConfig) validateWorkingDir() error {
+	if c.WorkingDir == "" {
+		return nil
+	}
+	dir := c.WorkDir()
+
+	targets := []string{c.workspaceTarget()}
+	if len(c.Workspaces) > 0 {
+		targets = targets[:0]
+		for _, w := range c.Workspaces {
+			targets = append(targets, w.ContainerPath())
+		}
+	}
+	for _, target := range targets {
+		if dir == target || strings.HasPrefix(dir, target+"/") {
+			return nil
+		}
+	}
+	return fmt.Errorf("working directory %q is not inside a mounted workspace", c.WorkingDir)
+}
+
 // DockerManager handles container operations.
 type DockerManager interface {
 	// Start creates and starts a container with the given configuration.
 	Start(config ContainerConfig) error
 
+	// RunOnce runs a command in a one-shot container that is removed on exit.
+	RunOnce(config ContainerConfig, cmd []string) (exitCode int, err error)
+
 	// Stop stops and removes the container.
 	Stop(containerName string) error
 
 	// IsRunning checks if a container with the given name is running.
 	IsRunning(containerName string) bool
 
+	// IsReady checks the container is running and passes the readiness probe, if any.
+	IsReady(containerName string) bool
+
 	// Exec runs an interactive shell in the container and waits for it to exit.
+	// It never stops the container.
 	Exec(containerName string) error
 
+	// ExecInDir is like Exec but opens the shell in the given container directory.
+	ExecInDir(containerName, workDir string) error
+
+	// ShellAvailable reports whether shellPath is executable in the container.
+	ShellAvailable(containerName, shellPath string) bool
+
+	// ExecWithCleanup is like ExecInDir but runs cleanup if capsule is interrupted.
+	ExecWithCleanup(containerName, workDir string, cleanup func()) error
+
 	// SetupWorkspaceSymlink creates the _docs symlink inside the container.
 	SetupWorkspaceSymlink(containerName, repoID string) error
 
+	// SetupWorkspaceSymlinks creates a _docs symlink in each configured workspace that has a RepoID.
+	SetupWorkspaceSymlinks(config ContainerConfig) error
+
+	// VerifySymlinkConsistency checks the host and in-container _docs symlinks both point at repos/<repoID>.
+	VerifySymlinkConsistency(containerName, workspacePath, volumeMountPoint, repoID string) error
+
+	// VerifyHome checks that HOME in the container is writable and on the mounted volume.
+	VerifyHome(containerName string) error
+
+	// DetachWorkspace removes the workspace's managed _docs symlink on the host and in the container.
+	DetachWorkspace(containerName, workspacePath, volumeMountPoint string) error
+
 	// RemoveContainer forcibly removes a container (running or stopped).
 	RemoveContainer(containerName string) error
 
 	// CheckTmpFileSharing verifies Docker Desktop is running and can access file mounts.
 	CheckTmpFileSharing() error
 
+	// ValidateMountChain checks each link from the host paths to a container, stopping at the first broken one.
+	ValidateMountChain(config ContainerConfig) ([]MountCheck, error)
+
 	// RefreshMountCache forces Docker Desktop to refresh its VirtioFS cache for a mount point.
 	RefreshMountCache(mountPoint string) error
 
 	// ClearVMCache drops the Linux VM's kernel cache to fix VirtioFS stale mount issues.
 	ClearVMCache() error
+
+	// Stats returns a single resource usage sample for the container.
+	Stats(containerName string) (*ContainerStats, error)
+
+	// TrackPeaks samples stats until ctx is cancelled and returns the highest values seen.
+	TrackPeaks(ctx context.Context, containerName string) (*Peaks, error)
+
+	// Processes lists the processes running in the container.
+	Processes(containerName string) ([]Process, error)
+
+	// VolumeSpaceInContainer reports the volume's total, used and free bytes as seen inside the container.
+	VolumeSpaceInContainer(containerName string) (total, used, free int64, err error)
+
+	// ListCapsuleContainers returns the names of all capsule-managed containers.
+	ListCapsuleContainers() ([]string, error)
+
+	// StopByRepo stops every capsule container serving the repository.
+	StopByRepo(repoID string) ([]string, error)
+
+	// PruneManagedDockerVolumes removes capsule-labeled Docker volumes no running
+	// container uses, returning those removed and those skipped as in use.
+	PruneManagedDockerVolumes() (removed, inUse []string, err error)
+
+	// WorkspaceDirty reports whether the container's workspace has uncommitted git changes.
+	WorkspaceDirty(containerName string) (bool, []string, error)
+
+	// Logs writes the container's output to stdout and stderr.
+	Logs(containerName string, opts LogsOptions) error
+
+	// TailFile follows a file inside the container until ctx is cancelled.
+	TailFile(ctx context.Context, containerName, filePath string, out io.Writer) error
+
+	// WaitForLogLine follows the container's logs until a line matches pattern.
+	WaitForLogLine(ctx context.Context, containerName string, pattern *regexp.Regexp) error
+
+	// VerifyMounts checks the container's mounts match the configuration.
+	VerifyMounts(containerName string, config ContainerConfig) error
+
+	// ConfigDrift lists the differences between config and the container as created.
+	ConfigDrift(containerName string, config ContainerConfig) ([]Drift, error)
+
+	// Events returns the stream of lifecycle events published by this manager.
+	Events() <-chan Event
+
+	// TrackPhase publishes the begin and end events of a caller's phase.
+	TrackPhase(phase EventPhase, container, repo string) func(errp *error)
+
+	// PublishProgress publishes a step within a phase begun with TrackPhase.
+	PublishProgress(phase EventPhase, container, repo, step string)
+
+	// ResolveContext reports which repository and workspace a container serves.
+	ResolveContext(containerName string) (repoID, workspace string, err error)
+
+	// ReconstructRunArgs rebuilds the `docker run` arguments of an existing container.
+	ReconstructRunArgs(containerName string) ([]string, error)
+
+	// EntrypointOf returns the entrypoint and command the container was created with.
+	EntrypointOf(containerName string) (entrypoint []string, cmd []string, err error)
+
+	// Engine classifies the Docker engine (Docker Desktop, other VM, or native).
+	Engine() (EngineKind, error)
+
+	// Capabilities reports which engine-specific workarounds apply to the engine.
+	Capabilities() EngineCapabilities
+
+	// CheckPrivilegedRun checks that the engine allows `docker run --privileged`.
+	CheckPrivilegedRun() error
+
+	// EngineResources returns the memory and CPUs available to the Docker engine.
+	EngineResources() (*EngineResources, error)
+
+	// ForceReset removes a container even when it is stuck in Created, Restarting, or Dead.
+	ForceReset(containerName string) error
+
+	// VerifyImageContract checks that an image provides the commands capsule runs in it.
+	VerifyImageContract(imageName string) error
+
+	// RunPostStart runs the configured post-start commands in the container.
+	RunPostStart(config ContainerConfig) error
+
+	// BenchmarkMount measures bind mount throughput and latency from a container.
+	BenchmarkMount(mountPoint string) (*MountBench, error)
+
+	// ClockSkew returns how far the container's clock is ahead of the host's.
+	ClockSkew(containerName string) (time.Duration, error)
+
+	// WaitDaemon blocks until the Docker daemon responds or ctx is done.
+	WaitDaemon(ctx context.Context) error
+
+	// NetCheck checks DNS, TCP and TLS reachability of targets from inside a container.
+	NetCheck(containerName string, targets []string) ([]NetResult, error)
+
+	// CheckAllMounts probes the bind mounts of every running capsule container.
+	CheckAllMounts() ([]MountHealth, error)
+
+	// AutoStopWhenIdle stops the container once it has been idle for the given duration.
+	AutoStopWhenIdle(ctx context.Context, containerName string, idle time.Duration) error
 }
@@ -0,0 +1,242 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateImageName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"claude-capsule:latest", false},
+		{"alpine", false},
+		{"library/alpine:3.19", false},
+		{"internal-registry.corp/alpine:3.19", false},
+		{"registry.local:5000/team/capsule:v1.2.3", false},
+		{"localhost/capsule", false},
+		{"alpine@sha256:" + strings.Repeat("a", 64), false},
+		{"alpine:3.19@sha256:" + strings.Repeat("0", 64), false},
+		{"alpine@sha256:" + strings.Repeat("A", 64), true},
+		{"alpine@sha256:abc", true},
+		{"alpine@md5:" + strings.Repeat("a", 32), true},
+		{"@sha256:" + strings.Repeat("a", 64), true},
+		{"Claude-Capsule:latest", true},
+		{"registry.local/Team/capsule", true},
+		{"capsule:bad tag", true},
+		{"capsule--:latest", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateImageName(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateImageName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestContainerConfigValidate_ImageName(t *testing.T) {
+	cfg := ContainerConfig{
+		ImageName:        "Claude-Capsule:latest",
+		ContainerName:    "claude-abc",
+		VolumeMountPoint: "/Volumes/Capsule-abc",
+		WorkspacePath:    "/Users/me/project",
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() accepted a mixed-case image name")
+	}
+
+	cfg.ImageName = DefaultImageName
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestContainerConfigValidate_SecretFiles(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(secret, []byte("token"), 0400); err != nil {
+		t.Fatal(err)
+	}
+	cfg := ContainerConfig{
+		ImageName:        DefaultImageName,
+		ContainerName:    "claude-abc",
+		VolumeMountPoint: "/Volumes/Capsule-abc",
+		WorkspacePath:    "/Users/me/project",
+		SecretFiles:      map[string]string{"/run/secrets/token": secret},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	cfg.SecretFiles = map[string]string{"run/secrets/token": secret}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() accepted a relative secret container path")
+	}
+
+	if err := os.Chmod(secret, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg.SecretFiles = map[string]string{"/run/secrets/token": secret}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() accepted a world-readable secret file")
+	}
+}
+
+func TestValidateExtraArgs(t *testing.T) {
+	valid := [][]string{
+		nil,
+		{"--memory", "4g"},
+		{"--cap-add=SYS_PTRACE"},
+	}
+	for _, args := range valid {
+		if err := validateExtraArgs(args); err != nil {
+			t.Errorf("validateExtraArgs(%q) error = %v", args, err)
+		}
+	}
+
+	invalid := [][]string{
+		{"--name", "other"},
+		{"--entrypoint=/bin/sh"},
+		{"-d"},
+		{"--rm"},
+		{"--platform", "linux/amd64"},
+		{"--pull=always"},
+	}
+	for _, args := range invalid {
+		if err := validateExtraArgs(args); err == nil {
+			t.Errorf("validateExtraArgs(%q) error = nil, want error", args)
+		}
+	}
+	if err := validateExtraArgs([]string{"--pull=always"}); err == nil || !strings.Contains(err.Error(), "ContainerConfig.PullPolicy") {
+		t.Errorf("validateExtraArgs(--pull) error = %v, want a pointer to PullPolicy", err)
+	}
+}
+
+func TestValidateExtraMounts_ReadOnlyInWorkspace(t *testing.T) {
+	readOnly := []ExtraMount{{HostPath: "/ref", ContainerPath: "/workspace/.reference", ReadOnly: true}}
+	if err := validateExtraMounts(readOnly); err != nil {
+		t.Errorf("read-only mount in workspace: error = %v", err)
+	}
+
+	writable := []ExtraMount{{HostPath: "/ref", ContainerPath: "/workspace/.reference"}}
+	if err := validateExtraMounts(writable); err == nil {
+		t.Error("writable mount in workspace: error = nil, want error")
+	}
+
+	overWorkspace := []ExtraMount{{HostPath: "/ref", ContainerPath: "/workspace", ReadOnly: true}}
+	if err := validateExtraMounts(overWorkspace); err == nil {
+		t.Error("mount over workspace: error = nil, want error")
+	}
+}
+
+func TestValidateExtraMounts_BindPropagation(t *testing.T) {
+	valid := []ExtraMount{{HostPath: "/ref", ContainerPath: "/ref", BindPropagation: "rslave"}}
+	if err := validateExtraMounts(valid); err != nil {
+		t.Errorf("rslave propagation: error = %v", err)
+	}
+	args := strings.Join(containerOptionArgs(ContainerConfig{ExtraMounts: valid}), " ")
+	if !strings.Contains(args, "target=/ref,bind-propagation=rslave") {
+		t.Errorf("args = %q, want bind-propagation option", args)
+	}
+
+	invalid := []ExtraMount{{HostPath: "/ref", ContainerPath: "/ref", BindPropagation: "recursive"}}
+	if err := validateExtraMounts(invalid); err == nil {
+		t.Error("unknown propagation: error = nil, want error")
+	}
+}
+
+func TestContainerConfigValidate_AdditionalVolumes(t *testing.T) {
+	cfg := ContainerConfig{
+		ImageName:         DefaultImageName,
+		ContainerName:     "claude-abc",
+		VolumeMountPoint:  "/Volumes/Capsule-abc",
+		WorkspacePath:     "/Users/me/project",
+		AdditionalVolumes: []VolumeMount{{MountPoint: "/Volumes/Capsule-team", ContainerPath: "/team-docs"}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if args := strings.Join(containerOptionArgs(cfg), " "); !strings.Contains(args, "source=/Volumes/Capsule-team,target=/team-docs") {
+		t.Errorf("args = %q, want additional volume mount", args)
+	}
+
+	for _, target := range []string{"/claude-env/team", "/workspace/team", "relative"} {
+		cfg.AdditionalVolumes = []VolumeMount{{MountPoint: "/Volumes/Capsule-team", ContainerPath: target}}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate() accepted additional volume at %q", target)
+		}
+	}
+
+	cfg.AdditionalVolumes = []VolumeMount{
+		{MountPoint: "/Volumes/Capsule-team", ContainerPath: "/shared"},
+		{MountPoint: "/Volumes/Capsule-other", ContainerPath: "/shared"},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() accepted duplicate additional volume targets")
+	}
+}
+
+func TestContainerConfigValidate_DigestImage(t *testing.T) {
+	image := "registry.local:5000/team/capsule@sha256:" + strings.Repeat("ab", 32)
+	cfg := ContainerConfig{
+		ImageName:        image,
+		ContainerName:    "claude-abc",
+		VolumeMountPoint: "/Volumes/Capsule-abc",
+		WorkspacePath:    "/Users/me/project",
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if args := buildRunArgs(cfg); !slices.Contains(args, image) {
+		t.Errorf("args = %q, want digest reference passed unchanged", args)
+	}
+}
+
+func TestSetDocsLinkName(t *testing.T) {
+	m := NewManager()
+	if err := m.SetDocsLinkName(".capsule-docs"); err != nil || m.docsLinkName() != ".capsule-docs" {
+		t.Errorf("SetDocsLinkName() = %v, name = %q", err, m.docsLinkName())
+	}
+	for _, name := range []string{"docs/sub", "..", "-rf", "a\\b"} {
+		if err := m.SetDocsLinkName(name); err == nil {
+			t.Errorf("SetDocsLinkName(%q) accepted an invalid name", name)
+		}
+	}
+	if m.SetDocsLinkName("") != nil || m.docsLinkName() != "_docs" {
+		t.Errorf("docsLinkName() = %q after reset, want _docs", m.docsLinkName())
+	}
+	if NewManager().docsLinkName() != "_docs" {
+		t.Error("a new manager does not use the default name")
+	}
+}
+
+func TestEvents(t *testing.T) {
+	m := NewManager()
+	if err := m.Stop("not a valid name"); err == nil {
+		t.Fatal("Stop() accepted an invalid name")
+	}
+
+	events := m.Events()
+	if events != m.Events() {
+		t.Error("Events() returned a different channel on the second call")
+	}
+	err := m.Stop("not a valid name")
+
+	begin, end := <-events, <-events
+	if begin.Phase != PhaseStop || begin.Kind != EventBegin || begin.Err != nil {
+		t.Errorf("first event = %v, want stop begin", begin)
+	}
+	if end.Kind != EventEnd || end.Err != err || end.Duration < 0 {
+		t.Errorf("second event = %v, want stop end with the error", end)
+	}
+	select {
+	case e := <-events:
+		t.Errorf("unexpected event %v; events before Events() should not be recorded", e)
+	default:
+	}
+}
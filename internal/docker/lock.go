@@ -0,0 +1,44 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+	"github.com/jeanhaley32/claude-capsule/internal/filelock"
+)
+
+// containerLockTimeout bounds how long to wait for another process's container lock.
+const containerLockTimeout = 5 * time.Second
+
+// ErrLocked is returned when another process holds the lock for a container.
+var ErrLocked = errors.New("container is locked by another capsule process")
+
+// lockDir returns the directory holding per-container lock files.
+func lockDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, constants.CapsuleConfigDir, constants.LocksSubdir), nil
+}
+
+// acquireContainerLock takes an exclusive flock on the container's lock file.
+// It retries until containerLockTimeout and then returns an error wrapping ErrLocked.
+func acquireContainerLock(containerName string) (*filelock.Lock, error) {
+	dir, err := lockDir()
+	if err != nil {
+		return nil, err
+	}
+
+	// Container names are validated before locking, so they are safe as file names
+	lockPath := filepath.Join(dir, constants.ContainerLockPrefix+containerName+".lock")
+	lock, err := filelock.Acquire(lockPath, containerLockTimeout)
+	if errors.Is(err, filelock.ErrTimeout) {
+		return nil, fmt.Errorf("%w: %s (waited %v)", ErrLocked, containerName, containerLockTimeout)
+	}
+	return lock, err
+}
@@ -0,0 +1,174 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+)
+
+// maxLogLineSize bounds the length of a log line WaitForLogLine can match.
+const maxLogLineSize = 1 << 20
+
+// LogsOptions controls which container output Logs shows.
+type LogsOptions struct {
+	// Follow keeps streaming new output until the container stops or the
+	// command is interrupted. Docker's json-file and local drivers keep
+	// following across log rotation, but output already rotated out is gone,
+	// so Logs prints a note to stderr when the container's logs rotate.
+	Follow bool
+
+	// SinceStart limits output to the current run of the container, using its
+	// State.StartedAt, so output from before a restart is skipped.
+	SinceStart bool
+}
+
+// Logs writes the container's output to stdout and stderr.
+func (m *Manager) Logs(containerName string, opts LogsOptions) error {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"logs"}
+	var info *containerInspect
+	if opts.SinceStart || opts.Follow {
+		if info, err = m.inspectContainer(containerName); err != nil {
+			return err
+		}
+	}
+	if opts.SinceStart {
+		if info.State.StartedAt == "" {
+			return fmt.Errorf("container %s has no start time", containerName)
+		}
+		args = append(args, "--since", info.State.StartedAt)
+	}
+	if opts.Follow {
+		args = append(args, "--follow")
+		if maxSize := info.logRotationSize(); maxSize != "" {
+			fmt.Fprintf(os.Stderr, "Note: %s logs rotate at %s; older output may already be gone.\n", containerName, maxSize)
+		}
+	}
+	args = append(args, containerName)
+
+	// No timeout: following runs until interrupted
+	cmd := exec.Command("docker", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to read logs for %s: %w", containerName, err)
+	}
+	return nil
+}
+
+// defaultLocalLogMaxSize is the max-size the local log driver rotates at
+// when none is configured.
+const defaultLocalLogMaxSize = "20m"
+
+// logRotationSize returns the size at which the container's logs rotate, or
+// "" if its log driver does not rotate. Only the json-file and local drivers,
+// the ones `docker logs` reads from disk, are considered.
+func (c *containerInspect) logRotationSize() string {
+	logConfig := c.HostConfig.LogConfig
+	switch logConfig.Type {
+	case "json-file":
+		return logConfig.Config["max-size"]
+	case "local":
+		if maxSize := logConfig.Config["max-size"]; maxSize != "" {
+			return maxSize
+		}
+		return defaultLocalLogMaxSize
+	}
+	return ""
+}
+
+// TailFile streams a file inside the container to out, following it across
+// rotation (tail -F) until ctx is cancelled. Returns *ContainerNotFoundError if
+// the container is not running.
+func (m *Manager) TailFile(ctx context.Context, containerName, filePath string, out io.Writer) error {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return err
+	}
+	if !path.IsAbs(filePath) {
+		return fmt.Errorf("file path must be an absolute container path: %q", filePath)
+	}
+	if !m.IsRunning(containerName) {
+		return &ContainerNotFoundError{Name: containerName}
+	}
+
+	cmd := exec.CommandContext(ctx, "docker", "exec", containerName, "tail", "-F", filePath)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil // Cancelled by the caller
+		}
+		return fmt.Errorf("failed to tail %s in %s: %w", filePath, containerName, err)
+	}
+	return nil
+}
+
+// WaitForLogLine follows the container's logs, stdout and stderr alike, and
+// returns nil once a line matches pattern, for images that announce readiness
+// in their output. Lines logged before the call count, so a container that is
+// already ready matches at once. It returns ctx's error if ctx ends first, and
+// an error if the logs end (the container stopped) without a match. The
+// `docker logs` process is killed before returning.
+func (m *Manager) WaitForLogLine(ctx context.Context, containerName string, pattern *regexp.Regexp) error {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return err
+	}
+	if !m.containerExists(containerName) {
+		return &ContainerNotFoundError{Name: containerName}
+	}
+
+	logsCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// One pipe for both streams; an *os.File means Wait doesn't depend on it being drained
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	cmd := exec.CommandContext(logsCtx, "docker", "logs", "--follow", containerName)
+	cmd.Stdout = w
+	cmd.Stderr = w
+	err = cmd.Start()
+	w.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read logs for %s: %w", containerName, err)
+	}
+
+	matched := false
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineSize)
+	for scanner.Scan() {
+		if pattern.MatchString(scanner.Text()) {
+			matched = true
+			break
+		}
+	}
+	scanErr := scanner.Err()
+	cancel()
+	waitErr := cmd.Wait()
+
+	switch {
+	case matched:
+		return nil
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case scanErr != nil:
+		return fmt.Errorf("failed to read logs for %s: %w", containerName, scanErr)
+	case waitErr != nil:
+		return fmt.Errorf("failed to read logs for %s: %w", containerName, waitErr)
+	default:
+		return fmt.Errorf("logs of %s ended without a line matching %q", containerName, pattern)
+	}
+}
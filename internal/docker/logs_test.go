@@ -0,0 +1,26 @@
+package docker
+
+import "testing"
+
+func TestContainerInspectLogRotationSize(t *testing.T) {
+	tests := []struct {
+		driver string
+		config map[string]string
+		want   string
+	}{
+		{"json-file", nil, ""},
+		{"json-file", map[string]string{"max-size": "10m", "max-file": "3"}, "10m"},
+		{"local", nil, defaultLocalLogMaxSize},
+		{"local", map[string]string{"max-size": "5m"}, "5m"},
+		{"syslog", map[string]string{"max-size": "10m"}, ""},
+		{"", nil, ""},
+	}
+	for _, tt := range tests {
+		var info containerInspect
+		info.HostConfig.LogConfig.Type = tt.driver
+		info.HostConfig.LogConfig.Config = tt.config
+		if got := info.logRotationSize(); got != tt.want {
+			t.Errorf("logRotationSize(%s, %v) = %q, want %q", tt.driver, tt.config, got, tt.want)
+		}
+	}
+}
@@ -5,16 +5,20 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
 	"github.com/jeanhaley32/claude-capsule/internal/embedded"
 )
 
-// Timeout configuration for Docker commands
+// Default timeouts for Docker commands (see Timeouts)
 const (
 	defaultCommandTimeout = 30 * time.Second
 	quickCommandTimeout   = 10 * time.Second // For fast operations like cache refresh
+	helperPullTimeout     = 2 * time.Minute  // For pulling a pinned helper image
 )
 
 // Retry configuration for container readiness
@@ -25,45 +29,152 @@ const (
 
 // Delay constants for Docker operations
 const (
-	MountReleaseDelay = 1 * time.Second // Wait for Docker to release mount references
-	CacheRefreshDelay = 2 * time.Second // Wait for Docker VirtioFS cache to refresh
+	MountReleaseDelay = constants.MountReleaseDelay // Wait for Docker to release mount references
+	CacheRefreshDelay = constants.CacheRefreshDelay // Wait for Docker VirtioFS cache to refresh
 )
 
 const (
 	DefaultImageName     = "claude-capsule:latest"
 	DefaultContainerName = "claude-capsule"
+
+	// DefaultShell is the interactive shell Exec runs in the container.
+	DefaultShell = "/usr/bin/fish"
+
+	// DefaultHelperImage is the image used for short-lived probe containers.
+	DefaultHelperImage = "alpine"
+
+	// DockerSocketPath is the Docker daemon socket, mounted at the same path
+	// inside the container when MountDockerSocket is set.
+	DockerSocketPath = "/var/run/docker.sock"
 )
 
 // Manager implements DockerManager using the Docker CLI.
-type Manager struct{}
+type Manager struct {
+	idleThreshold     IdleThreshold
+	helperImage       string
+	helperPullPolicy  PullPolicy
+	readinessProbe    []string
+	timeouts          Timeouts
+	recordPath        string
+	sessionFilter     string
+	symlinkAttempts   int
+	symlinkRetryDelay time.Duration
+	docsLink          string // See SetDocsLinkName
+
+	eventsMu sync.Mutex
+	events   chan Event // Created by Events
+
+	capsMu sync.Mutex
+	caps   *EngineCapabilities // Cached by Capabilities
+}
 
 // NewManager creates a new Docker manager.
 func NewManager() *Manager {
-	return &Manager{}
+	return &Manager{helperPullPolicy: PullMissing, timeouts: DefaultTimeouts()}
+}
+
+// SetHelperImage overrides the image used by CheckTmpFileSharing, RefreshMountCache,
+// ClearVMCache, and BenchmarkMount. Use this to point at an internal registry
+// mirror (e.g. "internal-registry/alpine:3.19") in locked-down environments, or pin it
+// by digest (e.g. "alpine@sha256:...") to control exactly which image runs
+// with --privileged in ClearVMCache.
+func (m *Manager) SetHelperImage(image string) error {
+	if err := ValidateImageName(image); err != nil {
+		return fmt.Errorf("invalid helper image: %w", err)
+	}
+	m.helperImage = image
+	return nil
+}
+
+// SetHelperPullPolicy controls whether a digest-pinned helper image may be pulled
+// when it is not present locally. The default, PullMissing, pulls it once;
+// PullNever (or the empty policy) makes the probes fail instead. Unpinned images are left to docker run.
+func (m *Manager) SetHelperPullPolicy(policy PullPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	m.helperPullPolicy = policy
+	return nil
 }
 
-func (m *Manager) Start(config ContainerConfig) error {
+// ensureHelperImage makes sure a digest-pinned helper image is present locally
+// before a probe runs, pulling it if the helper pull policy allows.
+func (m *Manager) ensureHelperImage() error {
+	image := m.helperImageName()
+	if !strings.Contains(image, "@") || embedded.ImageExists(image) {
+		return nil
+	}
+	if m.helperPullPolicy.orDefault() == PullNever {
+		return fmt.Errorf("pinned helper image %s is not present locally and pulling is disabled", image)
+	}
+	if err := m.runCommandWithTimeout(helperPullTimeout, "docker", "pull", image); err != nil {
+		return fmt.Errorf("failed to pull pinned helper image %s: %w", image, err)
+	}
+	return nil
+}
+
+// helperImageName returns the configured helper image, or DefaultHelperImage.
+func (m *Manager) helperImageName() string {
+	if m.helperImage == "" {
+		return DefaultHelperImage
+	}
+	return m.helperImage
+}
+
+func (m *Manager) Start(config ContainerConfig) (err error) {
+	defer m.track(PhaseStart, config.ContainerName, config.RepoID)(&err)
+
+	// Resolve the container name the same way every other method does
+	name, err := ResolveContainerName(config.ContainerName)
+	if err != nil {
+		return fmt.Errorf("invalid container config: %w", err)
+	}
+	config.ContainerName = name
+
 	// Validate configuration
 	if err := config.Validate(); err != nil {
 		return fmt.Errorf("invalid container config: %w", err)
 	}
 
+	// Serialize with other capsule processes operating on this container
+	lock, err := acquireContainerLock(config.ContainerName)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Check if Docker is running
 	if err := m.checkDockerRunning(); err != nil {
 		return err
 	}
 
-	// Check if image exists
-	if !embedded.ImageExists(config.ImageName) {
-		return fmt.Errorf("docker image '%s' not found. Build it with: docker build -t %s .",
-			config.ImageName, config.ImageName)
+	// Docker refuses to bind-mount a missing source, so fail (or create it) up front
+	if err := prepareWorkspaces(config); err != nil {
+		return err
+	}
+
+	// Check if image exists (unless docker is allowed to pull it during run)
+	if err := checkImageAvailable(config); err != nil {
+		return err
+	}
+
+	if config.IDMapWorkspace {
+		if err := m.checkIDMapSupport(); err != nil {
+			return err
+		}
 	}
 
 	// Check if container already exists
 	if m.containerExists(config.ContainerName) {
 		if m.IsRunning(config.ContainerName) {
-			// Already running, nothing to do
-			return nil
+			// Already running; only reuse it if it serves the same volume and workspace
+			if err := m.VerifyMounts(config.ContainerName, config); err != nil {
+				return err
+			}
+			return m.checkReusable(config)
+		}
+		if config.ReuseStoppedContainer {
+			return m.startExisting(config)
 		}
 		// Exists but not running, remove it
 		if err := m.RemoveContainer(config.ContainerName); err != nil {
@@ -71,29 +182,24 @@ func (m *Manager) Start(config ContainerConfig) error {
 		}
 	}
 
+	// The default image is built from the embedded Dockerfile; custom images
+	// may not provide everything capsule runs in the container
+	if config.ImageName != DefaultImageName {
+		if err := m.verifyImageContract(config.ImageName, config.requiredCommands()); err != nil {
+			return err
+		}
+	}
+
 	// Create and start container with timeout
 	// Override entrypoint since Dockerfile uses /bin/bash which doesn't work with tail command
-	// Set HOME to encrypted volume so credentials and user data persist
-	startTimeout := 30 * time.Second
+	// Set HOME to encrypted volume so credentials and user data persist (unless disabled)
+	startTimeout := m.timeouts.Start
 	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
 	defer cancel()
 
-	// Use --mount with consistency=delegated to reduce Docker Desktop caching issues
-	// delegated mode gives container authority over filesystem state
-	volumeMount := fmt.Sprintf("type=bind,source=%s,target=/claude-env,consistency=delegated", config.VolumeMountPoint)
-	workspaceMount := fmt.Sprintf("type=bind,source=%s,target=/workspace,consistency=delegated", config.WorkspacePath)
+	args := buildRunArgs(config)
 
-	cmd := exec.CommandContext(ctx, "docker", "run",
-		"-d",
-		"--name", config.ContainerName,
-		"--mount", volumeMount,
-		"--mount", workspaceMount,
-		"-w", "/workspace",
-		"-e", "HOME=/claude-env/home",
-		"--entrypoint", "tail",
-		config.ImageName,
-		"-f", "/dev/null", // Keep container running
-	)
+	cmd := exec.CommandContext(ctx, "docker", args...)
 
 	// Capture stderr to include in error message for retry logic
 	output, err := cmd.CombinedOutput()
@@ -107,26 +213,243 @@ func (m *Manager) Start(config ContainerConfig) error {
 	return nil
 }
 
-func (m *Manager) Stop(containerName string) error {
-	if containerName == "" {
-		containerName = DefaultContainerName
+// startExisting restarts a stopped container after checking that it still
+// serves the configured volume and workspace, preserving its writable layer.
+func (m *Manager) startExisting(config ContainerConfig) error {
+	if err := m.VerifyMounts(config.ContainerName, config); err != nil {
+		return fmt.Errorf("cannot reuse stopped container: %w", err)
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeouts.Command)
+	defer cancel()
 
-	// Validate container name
-	if err := ValidateDockerName(containerName); err != nil {
-		return fmt.Errorf("invalid container name: %w", err)
+	output, err := exec.CommandContext(ctx, "docker", "start", config.ContainerName).CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("container restart timed out after %v", m.timeouts.Command)
+		}
+		return fmt.Errorf("failed to restart container: %w: %s", err, strings.TrimSpace(string(output)))
 	}
+	return nil
+}
+
+// checkImageAvailable returns an error if the pull policy does not allow
+// docker to pull the image and it is missing or built for a platform other
+// than ResolvePlatform(config).
+func checkImageAvailable(config ContainerConfig) error {
+	if config.PullPolicy.orDefault() != PullNever {
+		return nil
+	}
+	if !embedded.ImageExists(config.ImageName) {
+		return fmt.Errorf("docker image '%s' not found. Build it with: docker build -t %s .",
+			config.ImageName, config.ImageName)
+	}
+	want := ResolvePlatform(config)
+	if got, err := imagePlatform(config.ImageName); err == nil && !platformMatches(want, got) {
+		return fmt.Errorf("docker image '%s' is built for %s, not %s; rebuild it for %s or set the platform to %s",
+			config.ImageName, got, want, want, got)
+	}
+	return nil
+}
+
+// RunOnce runs cmd in a fresh container with the configuration's mounts and
+// environment, streaming its output, and removes the container when it exits.
+// Unlike Start, no persistent container is kept. The command's exit code is
+// returned; err is only set if the command could not be run.
+func (m *Manager) RunOnce(config ContainerConfig, cmd []string) (exitCode int, err error) {
+	if len(cmd) == 0 || cmd[0] == "" {
+		return -1, fmt.Errorf("command is required")
+	}
+	name, err := ResolveContainerName(config.ContainerName)
+	if err != nil {
+		return -1, fmt.Errorf("invalid container config: %w", err)
+	}
+	config.ContainerName = name
+	if err := config.Validate(); err != nil {
+		return -1, fmt.Errorf("invalid container config: %w", err)
+	}
+	if err := m.checkDockerRunning(); err != nil {
+		return -1, err
+	}
+	if err := checkImageAvailable(config); err != nil {
+		return -1, err
+	}
+
+	// No timeout: batch commands run as long as they need
+	run := exec.Command("docker", buildRunOnceArgs(config, cmd)...)
+	run.Stdout = os.Stdout
+	run.Stderr = os.Stderr
+	if err := run.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode(), nil
+		}
+		return -1, fmt.Errorf("failed to run container: %w", err)
+	}
+	return 0, nil
+}
+
+// prepareWorkspaces checks that each workspace host directory exists, creating
+// missing ones when config.CreateWorkspaceIfMissing is set.
+func prepareWorkspaces(config ContainerConfig) error {
+	for _, hostPath := range config.workspaceHostPaths() {
+		info, err := os.Stat(hostPath)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("workspace path is not a directory: %s", hostPath)
+			}
+			continue
+		}
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to stat workspace %s: %w", hostPath, err)
+		}
+		if !config.CreateWorkspaceIfMissing {
+			return fmt.Errorf("workspace path does not exist: %s", hostPath)
+		}
+		if err := os.MkdirAll(hostPath, constants.DirPermissions); err != nil {
+			return fmt.Errorf("failed to create workspace %s: %w", hostPath, err)
+		}
+	}
+	return nil
+}
+
+// buildRunArgs returns the `docker run` arguments for the persistent container.
+func buildRunArgs(config ContainerConfig) []string {
+	args := []string{"run",
+		"-d",
+		"--name", config.ContainerName,
+		"--restart", string(config.RestartPolicy.orDefault()),
+	}
+	args = append(args, config.oomArgs()...)
+	args = append(args, config.blkioArgs()...)
+	args = append(args, containerOptionArgs(config)...)
+	if config.UseImageEntrypoint {
+		return append(args, config.ImageName)
+	}
+	args = append(args,
+		"--entrypoint", "tail",
+		config.ImageName,
+		"-f", "/dev/null", // Keep container running
+	)
+
+	return args
+}
+
+// buildRunOnceArgs returns the `docker run` arguments for a one-shot container
+// that runs cmd and is removed when it exits.
+func buildRunOnceArgs(config ContainerConfig, cmd []string) []string {
+	args := []string{"run", "--rm"}
+	args = append(args, containerOptionArgs(config)...)
+	args = append(args, "--entrypoint", cmd[0], config.ImageName)
+	return append(args, cmd[1:]...)
+}
+
+// containerOptionArgs returns the mount, environment, label, and network options
+// shared by every `docker run` for the configuration.
+func containerOptionArgs(config ContainerConfig) []string {
+	// Use --mount with consistency=delegated (by default) to reduce Docker Desktop caching issues
+	// delegated mode gives container authority over filesystem state
+	consistency := consistencyOption(config.Consistency)
+	volumeMount := fmt.Sprintf("type=bind,source=%s,target=/claude-env", config.VolumeMountPoint) + consistency
+	if config.Ephemeral {
+		// World-writable like /tmp so the image's non-root user can create repos/
+		volumeMount = "type=tmpfs,target=/claude-env,tmpfs-mode=1777"
+	}
+
+	args := []string{"--mount", volumeMount}
+	if len(config.Workspaces) > 0 {
+		for _, w := range config.Workspaces {
+			args = append(args, "--mount",
+				fmt.Sprintf("type=bind,source=%s,target=%s", w.HostPath, w.ContainerPath())+consistency+workspaceMountOptions(config))
+		}
+	} else {
+		args = append(args, "--mount",
+			fmt.Sprintf("type=bind,source=%s,target=%s", config.WorkspacePath, config.workspaceTarget())+consistency+workspaceMountOptions(config))
+	}
+	for _, v := range config.AdditionalVolumes {
+		args = append(args, "--mount",
+			fmt.Sprintf("type=bind,source=%s,target=%s", v.MountPoint, path.Clean(v.ContainerPath))+consistency)
+	}
+	args = append(args,
+		"-w", config.WorkDir(),
+		"--pull", string(config.PullPolicy.orDefault()),
+		"--platform", ResolvePlatform(config),
+		"--label", LabelManaged+"=true",
+	)
+	if config.RepoID != "" {
+		args = append(args, "--label", LabelRepo+"="+config.RepoID)
+	}
+	if len(config.Workspaces) == 0 {
+		args = append(args, "--label", LabelWorkspace+"="+config.workspaceTarget())
+	}
+	if session := config.sessionLabel(); session != "" {
+		args = append(args, "--label", LabelSession+"="+session)
+	}
+	if hostname := config.hostname(); hostname != "" {
+		args = append(args, "--hostname", hostname)
+	}
+	if config.CgroupParent != "" {
+		args = append(args, "--cgroup-parent", config.CgroupParent)
+	}
+	if config.persistHome() {
+		args = append(args, "-e", "HOME="+constants.ContainerHomePath)
+	}
+	for _, name := range config.InheritEnv {
+		// docker run copies the value of a bare -e NAME from its own environment
+		if _, ok := os.LookupEnv(name); ok {
+			args = append(args, "-e", name)
+		}
+	}
+	for _, server := range config.DNS {
+		args = append(args, "--dns", server)
+	}
+	for _, domain := range config.DNSSearch {
+		args = append(args, "--dns-search", domain)
+	}
+	for _, m := range append(append([]ExtraMount{}, config.ExtraMounts...), config.secretMounts()...) {
+		mount := fmt.Sprintf("type=bind,source=%s,target=%s", m.HostPath, path.Clean(m.ContainerPath)) + consistency
+		if m.ReadOnly {
+			mount += ",readonly"
+		}
+		if m.BindPropagation != "" {
+			mount += ",bind-propagation=" + m.BindPropagation
+		}
+		args = append(args, "--mount", mount)
+	}
+	if config.MountDockerSocket {
+		args = append(args, "--mount",
+			fmt.Sprintf("type=bind,source=%s,target=%s", DockerSocketPath, DockerSocketPath))
+	}
+	// Unvalidated escape hatch; must come last so the image follows it
+	args = append(args, config.ExtraArgs...)
+
+	return args
+}
+
+func (m *Manager) Stop(containerName string) (err error) {
+	defer m.track(PhaseStop, containerName, "")(&err)
+
+	containerName, err = ResolveContainerName(containerName)
+	if err != nil {
+		return err
+	}
+
+	// Serialize with other capsule processes operating on this container
+	lock, err := acquireContainerLock(containerName)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
 
 	// Check if container exists
 	if !m.containerExists(containerName) {
 		return nil // Nothing to stop
 	}
 
-	// Stop container with timeout
-	if err := m.runCommandWithTimeout(defaultCommandTimeout, "docker", "stop", containerName); err != nil {
+	// Stop container, allowing the grace period on top of the command timeout
+	stopTimeout := m.timeouts.StopGrace + m.timeouts.Command
+	if err := m.runCommandWithTimeout(stopTimeout, "docker", "stop", "-t", m.timeouts.stopGraceSeconds(), containerName); err != nil {
 		// Try to force stop - log but don't fail if kill also fails
 		// The container may have already stopped between the stop and kill commands
-		if killErr := m.runCommandWithTimeout(defaultCommandTimeout, "docker", "kill", containerName); killErr != nil {
+		if killErr := m.runCommandWithTimeout(m.timeouts.Command, "docker", "kill", containerName); killErr != nil {
 			// Only return error if container still exists after both attempts
 			if m.containerExists(containerName) && m.IsRunning(containerName) {
 				return fmt.Errorf("failed to stop container: stop error: %v, kill error: %v", err, killErr)
@@ -139,8 +462,9 @@ func (m *Manager) Stop(containerName string) error {
 }
 
 func (m *Manager) IsRunning(containerName string) bool {
-	if containerName == "" {
-		containerName = DefaultContainerName
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return false
 	}
 
 	cmd := exec.Command("docker", "inspect", "-f", "{{.State.Running}}", containerName)
@@ -153,57 +477,128 @@ func (m *Manager) IsRunning(containerName string) bool {
 }
 
 // Exec runs an interactive shell in the container and waits for it to exit.
-// This allows cleanup to happen after the user exits the shell.
+// Exec never stops the container; callers decide whether to call Stop
+// afterwards or leave the container running for quick re-entry.
 func (m *Manager) Exec(containerName string) error {
-	if containerName == "" {
-		containerName = DefaultContainerName
+	return m.ExecInDir(containerName, "")
+}
+
+// ExecInDir is like Exec but opens the shell in workDir (an absolute container path).
+// An empty workDir uses the container's working directory.
+func (m *Manager) ExecInDir(containerName, workDir string) (err error) {
+	defer m.track(PhaseExec, containerName, "")(&err)
+
+	cmd, err := m.execShellCommand(containerName, workDir)
+	if err != nil {
+		return err
 	}
 
-	cmd := exec.Command("docker", "exec", "-it", containerName, "/usr/bin/fish")
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	defer forwardWindowResize(cmd.Process)()
+
+	// Wait for user to exit
+	return cmd.Wait()
+}
+
+// ShellAvailable reports whether shellPath exists and is executable in the container.
+func (m *Manager) ShellAvailable(containerName, shellPath string) bool {
+	return m.runCommandWithTimeout(m.timeouts.Quick, "docker", "exec", containerName, "test", "-x", shellPath) == nil
+}
+
+// execShellCommand builds the interactive `docker exec` command for a shell in workDir,
+// after checking the shell exists so a missing binary gets a clear error.
+func (m *Manager) execShellCommand(containerName, workDir string) (*exec.Cmd, error) {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return nil, err
+	}
+	if !m.ShellAvailable(containerName, DefaultShell) {
+		if !m.IsRunning(containerName) {
+			return nil, fmt.Errorf("container %s is not running", containerName)
+		}
+		return nil, fmt.Errorf("shell %s not found in image; rebuild the image or use one that provides it", DefaultShell)
+	}
+
+	args := append([]string{"exec", "-it"}, terminalSizeArgs()...)
+	if workDir != "" {
+		if !path.IsAbs(workDir) {
+			return nil, fmt.Errorf("exec working directory must be an absolute container path: %q", workDir)
+		}
+		args = append(args, "-w", workDir)
+	}
+	args = append(args, containerName, DefaultShell)
+
+	name, args := m.dockerCommandName(args)
+	cmd := exec.Command(name, args...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-
-	// Run and wait for user to exit
-	return cmd.Run()
+	return cmd, nil
 }
 
-// SetupWorkspaceSymlink creates the _docs symlink inside the container.
-// It waits for the container to be ready and then runs the setup script.
+// SetupWorkspaceSymlink creates the _docs symlink in the container's
+// workspace mount. It waits for the container to be ready and then runs the
+// setup script.
 func (m *Manager) SetupWorkspaceSymlink(containerName, repoID string) error {
-	if containerName == "" {
-		containerName = DefaultContainerName
+	return m.setupWorkspaceSymlinkAt(containerName, repoID, m.containerWorkspaceTarget(containerName))
+}
+
+// SetupWorkspaceSymlinks creates a _docs symlink in each workspace of a
+// multi-workspace configuration that has a RepoID. For single-workspace
+// configurations it links the workspace mount using config.RepoID.
+func (m *Manager) SetupWorkspaceSymlinks(config ContainerConfig) error {
+	if len(config.Workspaces) == 0 {
+		return m.setupWorkspaceSymlinkAt(config.ContainerName, config.RepoID, config.workspaceTarget())
+	}
+	for _, w := range config.Workspaces {
+		if w.RepoID == "" {
+			continue
+		}
+		if err := m.setupWorkspaceSymlinkAt(config.ContainerName, w.RepoID, w.ContainerPath()); err != nil {
+			return fmt.Errorf("workspace %s: %w", w.ContainerSubdir, err)
+		}
+	}
+	return nil
+}
+
+// setupWorkspaceSymlinkAt runs the setup script for the workspace at the given container path.
+func (m *Manager) setupWorkspaceSymlinkAt(containerName, repoID, containerWorkspace string) (err error) {
+	defer m.track(PhaseSetup, containerName, repoID)(&err)
+
+	containerName, err = ResolveContainerName(containerName)
+	if err != nil {
+		return err
 	}
 	if repoID == "" {
 		return fmt.Errorf("repoID is required")
 	}
 
-	// Wait for container to be running with retry
-	for i := 0; i < containerReadyMaxRetries; i++ {
-		if m.IsRunning(containerName) {
-			break
-		}
-		if i == containerReadyMaxRetries-1 {
-			return fmt.Errorf("container %s not running after %d retries", containerName, containerReadyMaxRetries)
-		}
-		time.Sleep(containerReadyRetryDelay)
+	// Wait for container to be ready (running, and passing the readiness probe if set)
+	if err := m.waitReady(containerName); err != nil {
+		return err
 	}
 
-	// Run the setup script inside the container
-	ctx, cancel := context.WithTimeout(context.Background(), defaultCommandTimeout)
+	// Run the setup script inside the container, retrying while the volume
+	// mount settles
+	return m.retrySymlinkScript(func() (string, error) {
+		return m.runSymlinkScript(containerName, repoID, containerWorkspace)
+	})
+}
+
+// runSymlinkScript runs setup-workspace-symlink.sh once and returns its output.
+func (m *Manager) runSymlinkScript(containerName, repoID, containerWorkspace string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeouts.Command)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "docker", "exec", containerName,
-		"setup-workspace-symlink.sh", repoID)
+		"setup-workspace-symlink.sh", repoID, containerWorkspace, m.docsLinkName())
 	output, err := cmd.CombinedOutput()
-	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return fmt.Errorf("symlink setup timed out after %v", defaultCommandTimeout)
-		}
-		return fmt.Errorf("failed to setup workspace symlink: %w\nOutput: %s", err, string(output))
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("symlink setup timed out after %v", m.timeouts.Command)
 	}
-
-	return nil
+	return string(output), err
 }
 
 // runCommandWithTimeout runs a command with a timeout.
@@ -238,7 +633,7 @@ func (m *Manager) getCommandOutputWithTimeout(timeout time.Duration, name string
 
 // checkDockerRunning verifies Docker daemon is running.
 func (m *Manager) checkDockerRunning() error {
-	if err := m.runCommandWithTimeout(defaultCommandTimeout, "docker", "info"); err != nil {
+	if err := m.runCommandWithTimeout(m.timeouts.Command, "docker", "info"); err != nil {
 		return fmt.Errorf("Docker is not running. Please start Docker Desktop: %w", err)
 	}
 	return nil
@@ -250,24 +645,20 @@ func (m *Manager) CheckTmpFileSharing() error {
 	// Just verify Docker is running and can do basic file mounts
 	// We can't test /Volumes directly (protected by macOS), but hdiutil can mount there
 	// Test with /tmp to verify Docker's file sharing is working in general
-	ctx, cancel := context.WithTimeout(context.Background(), quickCommandTimeout)
+	if err := m.ensureHelperImage(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeouts.Quick)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "docker", "run", "--rm",
 		"-v", "/tmp:/test:ro",
-		"alpine", "test", "-d", "/test")
+		m.helperImageName(), "test", "-d", "/test")
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf(`Docker cannot access host filesystem for file sharing.
-
-Please ensure Docker Desktop is running and file sharing is enabled:
-  1. Open Docker Desktop
-  2. Go to Settings (gear icon) → Resources → File sharing
-  3. Verify file sharing is enabled
-  4. Click "Apply & Restart" if you make changes
-
-Error: %s`, strings.TrimSpace(string(output)))
+		return fmt.Errorf("Docker cannot access host filesystem for file sharing.\n\n%s\n\nError: %s",
+			m.Capabilities().FileSharingGuidance, strings.TrimSpace(string(output)))
 	}
 
 	// If we got output, the mount worked
@@ -279,14 +670,22 @@ Error: %s`, strings.TrimSpace(string(output)))
 // This is necessary because Docker Desktop's VirtioFS layer caches mount information,
 // and encrypted volumes that appear/disappear can cause stale cache entries.
 // By running a container that mounts the specific path, we force VirtioFS to re-scan.
+// It is a no-op on native engines, which bind mount host paths directly (see
+// Capabilities).
 func (m *Manager) RefreshMountCache(mountPoint string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), quickCommandTimeout)
+	if !m.Capabilities().RefreshMountCache {
+		return nil
+	}
+	if err := m.ensureHelperImage(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeouts.Quick)
 	defer cancel()
 
 	// Mount the actual path we'll be using - this forces VirtioFS to refresh its view
 	cmd := exec.CommandContext(ctx, "docker", "run", "--rm",
 		"-v", mountPoint+":/refresh-check:ro",
-		"alpine", "ls", "/refresh-check")
+		m.helperImageName(), "ls", "/refresh-check")
 
 	_, err := cmd.CombinedOutput()
 	// We don't care about the output, just that Docker accessed the path
@@ -303,13 +702,21 @@ func (m *Manager) RefreshMountCache(mountPoint string) error {
 // ClearVMCache drops the Linux VM's kernel cache to release VirtioFS file handles.
 // This clears page cache, dentries, and inodes which may hold stale references
 // to mount points that have been unmounted and remounted.
+// It only runs on Docker Desktop; elsewhere it would drop the caches of a VM
+// without VirtioFS, or of the host itself, so it is a no-op (see Capabilities).
 func (m *Manager) ClearVMCache() error {
-	ctx, cancel := context.WithTimeout(context.Background(), quickCommandTimeout)
+	if !m.Capabilities().DropCaches {
+		return nil
+	}
+	if err := m.ensureHelperImage(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeouts.Quick)
 	defer cancel()
 
 	// echo 3 drops page cache, dentries, and inodes
 	cmd := exec.CommandContext(ctx, "docker", "run", "--privileged", "--rm",
-		"alpine", "sh", "-c", "echo 3 > /proc/sys/vm/drop_caches")
+		m.helperImageName(), "sh", "-c", "echo 3 > /proc/sys/vm/drop_caches")
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -320,7 +727,7 @@ func (m *Manager) ClearVMCache() error {
 
 // containerExists checks if a container exists (running or stopped).
 func (m *Manager) containerExists(containerName string) bool {
-	output, err := m.getCommandOutputWithTimeout(defaultCommandTimeout, "docker", "ps", "-a", "-q", "-f", "name=^"+containerName+"$")
+	output, err := m.getCommandOutputWithTimeout(m.timeouts.Command, "docker", "ps", "-a", "-q", "-f", "name=^"+containerName+"$")
 	if err != nil {
 		return false
 	}
@@ -329,5 +736,5 @@ func (m *Manager) containerExists(containerName string) bool {
 
 // RemoveContainer forcibly removes a container (running or stopped).
 func (m *Manager) RemoveContainer(containerName string) error {
-	return m.runCommandWithTimeout(defaultCommandTimeout, "docker", "rm", "-f", containerName)
+	return m.runCommandWithTimeout(m.timeouts.Command, "docker", "rm", "-f", containerName)
 }
@@ -0,0 +1,323 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestBuildRunOnceArgs(t *testing.T) {
+	config := ContainerConfig{
+		ImageName:        DefaultImageName,
+		ContainerName:    DefaultContainerName,
+		VolumeMountPoint: "/Volumes/Capsule-abc",
+		WorkspacePath:    "/src/project",
+	}
+	args := strings.Join(buildRunOnceArgs(config, []string{"make", "test"}), " ")
+
+	if !strings.HasPrefix(args, "run --rm ") {
+		t.Errorf("args = %q, want run --rm prefix", args)
+	}
+	if strings.Contains(args, " -d ") || strings.Contains(args, "--name") {
+		t.Errorf("args = %q, want no -d or --name", args)
+	}
+	if !strings.Contains(args, "target=/workspace") {
+		t.Errorf("args = %q, want workspace mount", args)
+	}
+	if !strings.HasSuffix(args, "--entrypoint make "+DefaultImageName+" test") {
+		t.Errorf("args = %q, want command as entrypoint", args)
+	}
+}
+
+func TestRunOnce_ResolvesContainerName(t *testing.T) {
+	t.Setenv(NamePrefixEnv, "")
+	config := ContainerConfig{
+		ImageName:        "capsule-test-missing:none",
+		VolumeMountPoint: "/Volumes/Capsule-abc",
+		WorkspacePath:    "/src/project",
+	}
+	// An unset name is resolved to the default, not rejected by Validate
+	if _, err := NewManager().RunOnce(config, []string{"true"}); err != nil && strings.Contains(err.Error(), "invalid container config") {
+		t.Errorf("RunOnce() without a container name error = %v", err)
+	}
+}
+
+func TestPrepareWorkspaces(t *testing.T) {
+	workspace := filepath.Join(t.TempDir(), "new-project")
+	config := ContainerConfig{WorkspacePath: workspace}
+
+	if err := prepareWorkspaces(config); err == nil {
+		t.Error("prepareWorkspaces() accepted a missing workspace")
+	}
+
+	config.CreateWorkspaceIfMissing = true
+	if err := prepareWorkspaces(config); err != nil {
+		t.Fatalf("prepareWorkspaces() error = %v", err)
+	}
+	if info, err := os.Stat(workspace); err != nil || !info.IsDir() {
+		t.Errorf("workspace not created: %v", err)
+	}
+}
+
+func TestContainerOptionArgs_InheritEnv(t *testing.T) {
+	t.Setenv("CAPSULE_TEST_PROXY", "http://proxy:3128")
+	config := ContainerConfig{
+		VolumeMountPoint: "/Volumes/Capsule-abc",
+		WorkspacePath:    "/src/project",
+		InheritEnv:       []string{"CAPSULE_TEST_PROXY", "CAPSULE_TEST_UNSET"},
+	}
+	args := strings.Join(containerOptionArgs(config), " ")
+
+	if !strings.Contains(args, "-e CAPSULE_TEST_PROXY") {
+		t.Errorf("args = %q, want inherited variable", args)
+	}
+	if strings.Contains(args, "proxy:3128") || strings.Contains(args, "CAPSULE_TEST_UNSET") {
+		t.Errorf("args = %q, want name only and unset variables skipped", args)
+	}
+}
+
+func TestContainerOptionArgs_Ephemeral(t *testing.T) {
+	config := ContainerConfig{
+		ImageName:     DefaultImageName,
+		ContainerName: "claude-abc",
+		WorkspacePath: "/src/project",
+		Ephemeral:     true,
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	args := strings.Join(containerOptionArgs(config), " ")
+	if !strings.Contains(args, "type=tmpfs,target=/claude-env") || strings.Contains(args, "HOME=") {
+		t.Errorf("args = %q, want tmpfs volume and default HOME", args)
+	}
+
+	config.VolumeMountPoint = "/Volumes/Capsule-abc"
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() accepted a volume mount point in ephemeral mode")
+	}
+	config.Ephemeral = false
+	config.VolumeMountPoint = ""
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() accepted an empty volume mount point")
+	}
+
+	// HOME persists on the volume unless a caller opts out
+	config.VolumeMountPoint = "/Volumes/Capsule-abc"
+	if args := strings.Join(containerOptionArgs(config), " "); !strings.Contains(args, "HOME=/claude-env/home") {
+		t.Errorf("args = %q, want HOME on the volume by default", args)
+	}
+	config.EphemeralHome = true
+	if args := strings.Join(containerOptionArgs(config), " "); strings.Contains(args, "HOME=") {
+		t.Errorf("args = %q, want default HOME with EphemeralHome", args)
+	}
+}
+
+func TestBuildRunArgs_RestartPolicy(t *testing.T) {
+	config := ContainerConfig{
+		ImageName:        DefaultImageName,
+		ContainerName:    "claude-abc",
+		VolumeMountPoint: "/Volumes/Capsule-abc",
+		WorkspacePath:    "/src/project",
+	}
+	if args := strings.Join(buildRunArgs(config), " "); !strings.Contains(args, "--restart no") {
+		t.Errorf("args = %q, want default --restart no", args)
+	}
+
+	config.RestartPolicy = RestartUnlessStopped
+	if args := strings.Join(buildRunArgs(config), " "); !strings.Contains(args, "--restart unless-stopped") {
+		t.Errorf("args = %q, want --restart unless-stopped", args)
+	}
+	if args := strings.Join(buildRunOnceArgs(config, []string{"true"}), " "); strings.Contains(args, "--restart") {
+		t.Errorf("run-once args = %q, want no --restart alongside --rm", args)
+	}
+
+	config.RestartPolicy = "sometimes"
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() accepted an unknown restart policy")
+	}
+}
+
+func TestBuildRunArgs_OOM(t *testing.T) {
+	score := 500
+	config := ContainerConfig{
+		ImageName:        DefaultImageName,
+		ContainerName:    "claude-abc",
+		VolumeMountPoint: "/Volumes/Capsule-abc",
+		WorkspacePath:    "/src/project",
+		OOMScoreAdj:      &score,
+		OOMKillDisable:   true,
+	}
+	args := strings.Join(buildRunArgs(config), " ")
+	if !strings.Contains(args, "--oom-score-adj 500") || !strings.Contains(args, "--oom-kill-disable") {
+		t.Errorf("args = %q, want OOM flags", args)
+	}
+	if len(config.Warnings()) != 1 {
+		t.Errorf("Warnings() = %v, want a warning without a memory limit", config.Warnings())
+	}
+	config.ExtraArgs = []string{"--memory=4g"}
+	if len(config.Warnings()) != 0 {
+		t.Errorf("Warnings() = %v, want none with a memory limit", config.Warnings())
+	}
+
+	score = 1001
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() accepted an out-of-range OOM score")
+	}
+}
+
+func TestBuildRunArgs_Blkio(t *testing.T) {
+	weight := uint16(300)
+	config := ContainerConfig{
+		ImageName:        DefaultImageName,
+		ContainerName:    "claude-abc",
+		VolumeMountPoint: "/Volumes/Capsule-abc",
+		WorkspacePath:    "/src/project",
+		BlkioWeight:      &weight,
+		DeviceWriteBps:   []BlkioDeviceLimit{{Device: "/dev/sda", Rate: "50m"}},
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	args := strings.Join(buildRunArgs(config), " ")
+	if !strings.Contains(args, "--blkio-weight 300") || !strings.Contains(args, "--device-write-bps /dev/sda:50m") {
+		t.Errorf("args = %q, want block IO flags", args)
+	}
+
+	weight = 5
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() accepted an out-of-range block IO weight")
+	}
+	weight = 300
+	config.DeviceReadBps = []BlkioDeviceLimit{{Device: "/dev/sda", Rate: "fast"}}
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() accepted an unparseable rate")
+	}
+}
+
+func TestResolvePlatform(t *testing.T) {
+	defer func(arch func() (string, error)) { engineArch = arch; detectArchOnce = sync.Once{} }(engineArch)
+	// An amd64 binary under Rosetta talking to an Apple Silicon engine
+	engineArch = func() (string, error) { return "arm64", nil }
+	detectArchOnce = sync.Once{}
+
+	config := ContainerConfig{
+		ImageName:        DefaultImageName,
+		ContainerName:    "claude-abc",
+		VolumeMountPoint: "/Volumes/Capsule-abc",
+		WorkspacePath:    "/src/project",
+		PullPolicy:       PullMissing,
+	}
+	if got := ResolvePlatform(config); got != "linux/arm64" {
+		t.Errorf("ResolvePlatform() = %q, want the host architecture", got)
+	}
+
+	// An amd64-only image is pulled and run as amd64, not the host arch
+	config.Platform = "linux/amd64"
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	args := strings.Join(buildRunArgs(config), " ")
+	if !strings.Contains(args, "--pull missing --platform linux/amd64") || strings.Contains(args, "arm64") {
+		t.Errorf("args = %q, want pull and run for linux/amd64", args)
+	}
+	if !platformMatches("linux/arm64", "linux/arm64/v8") || platformMatches("linux/amd64", "linux/arm64") {
+		t.Error("platformMatches() compares platforms incorrectly")
+	}
+
+	config.Platform = "amd64"
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() accepted a platform without an OS")
+	}
+}
+
+func TestContainerOptionArgs_CgroupParent(t *testing.T) {
+	config := ContainerConfig{
+		ImageName:        DefaultImageName,
+		ContainerName:    "claude-abc",
+		VolumeMountPoint: "/Volumes/Capsule-abc",
+		WorkspacePath:    "/src/project",
+		CgroupParent:     "capsules.slice",
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if args := strings.Join(containerOptionArgs(config), " "); !strings.Contains(args, "--cgroup-parent capsules.slice") {
+		t.Errorf("args = %q, want --cgroup-parent", args)
+	}
+
+	for _, parent := range []string{" ", "a b", "--privileged", "/capsules/../root"} {
+		config.CgroupParent = parent
+		if err := config.Validate(); err == nil {
+			t.Errorf("Validate() accepted cgroup parent %q", parent)
+		}
+	}
+}
+
+func TestContainerOptionArgs_MirrorHostPath(t *testing.T) {
+	config := ContainerConfig{
+		ImageName:        DefaultImageName,
+		ContainerName:    "claude-abc",
+		VolumeMountPoint: "/Volumes/Capsule-abc",
+		WorkspacePath:    "/Users/me/src/project",
+		WorkingDir:       "cmd",
+		MirrorHostPath:   true,
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	args := strings.Join(containerOptionArgs(config), " ")
+	if !strings.Contains(args, "target=/Users/me/src/project") || !strings.Contains(args, "-w /Users/me/src/project/cmd") {
+		t.Errorf("args = %q, want workspace mirrored at its host path", args)
+	}
+
+	config.WorkspacePath = "/claude-env/project"
+	if err := config.Validate(); err == nil {
+		t.Error("Validate() accepted a mirrored path overlapping the volume")
+	}
+}
+
+func TestParsePorcelain(t *testing.T) {
+	output := " M cmd/main.go\n?? notes.md\nR  old.go -> new.go\n"
+	got := strings.Join(parsePorcelain(output), ",")
+	if got != "cmd/main.go,notes.md,new.go" {
+		t.Errorf("parsePorcelain() = %q", got)
+	}
+	if paths := parsePorcelain(""); len(paths) != 0 {
+		t.Errorf("parsePorcelain(\"\") = %v, want none", paths)
+	}
+}
+
+func TestValidateMountChain_MissingHostPath(t *testing.T) {
+	config := ContainerConfig{
+		WorkspacePath: filepath.Join(t.TempDir(), "missing"),
+		Ephemeral:     true,
+	}
+	checks, err := NewManager().ValidateMountChain(config)
+	if err == nil || !strings.Contains(err.Error(), "workspace exists") {
+		t.Fatalf("ValidateMountChain() error = %v, want broken at workspace exists", err)
+	}
+	if checks[0].Err == nil || checks[0].OK() {
+		t.Errorf("first check = %+v, want failed", checks[0])
+	}
+	for _, c := range checks[1:] {
+		if !c.Skipped || strings.HasPrefix(c.Name, "volume ") {
+			t.Errorf("check %+v, want skipped workspace step", c)
+		}
+	}
+}
+
+func TestBuildRunArgs_UseImageEntrypoint(t *testing.T) {
+	config := ContainerConfig{
+		ImageName:          DefaultImageName,
+		ContainerName:      "claude-abc",
+		VolumeMountPoint:   "/Volumes/Capsule-abc",
+		WorkspacePath:      "/src/project",
+		UseImageEntrypoint: true,
+	}
+	args := buildRunArgs(config)
+	if strings.Contains(strings.Join(args, " "), "--entrypoint") || args[len(args)-1] != DefaultImageName {
+		t.Errorf("args = %q, want the image's own entrypoint", args)
+	}
+}
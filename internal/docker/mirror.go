@@ -0,0 +1,38 @@
+package docker
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// workspaceTarget returns where WorkspacePath is mounted in the container:
+// /workspace, or the host path itself when MirrorHostPath is set.
+func (c *ContainerConfig) workspaceTarget() string {
+	if c.MirrorHostPath && c.WorkspacePath != "" {
+		return path.Clean(c.WorkspacePath)
+	}
+	return constants.ContainerWorkspacePath
+}
+
+// validateMirrorHostPath checks that a mirrored workspace path can be used
+// as a container mount target.
+func (c *ContainerConfig) validateMirrorHostPath() error {
+	if !c.MirrorHostPath {
+		return nil
+	}
+	if len(c.Workspaces) > 0 {
+		return fmt.Errorf("MirrorHostPath cannot be combined with multiple workspaces")
+	}
+	target := c.workspaceTarget()
+	if !path.IsAbs(target) || target == "/" {
+		return fmt.Errorf("mirrored workspace path must be an absolute path below the root: %q", c.WorkspacePath)
+	}
+	for _, reserved := range []string{constants.ContainerVolumePath, DockerSocketPath} {
+		if pathsOverlap(target, reserved) {
+			return fmt.Errorf("mirrored workspace path %q overlaps %q", target, reserved)
+		}
+	}
+	return nil
+}
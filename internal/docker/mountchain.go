@@ -0,0 +1,141 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// mountProbePrefix names the files ValidateMountChain writes and removes.
+const mountProbePrefix = ".capsule-probe-"
+
+// MountCheck is the result of one step of ValidateMountChain.
+type MountCheck struct {
+	Name    string // Step, e.g. "volume accessible"
+	Path    string // Host path the step checked
+	Err     error  // Why the step failed; nil if it passed or was skipped
+	Skipped bool   // An earlier step failed, so this one did not run
+}
+
+// OK reports whether the step ran and passed.
+func (c MountCheck) OK() bool { return !c.Skipped && c.Err == nil }
+
+// ValidateMountChain checks, in order, every link between the host paths in
+// config and a container using them: the paths exist on the host, Docker can
+// see the volume and the workspace, Docker's view of the volume is fresh (a
+// file just written on the host is visible, which catches a stale VirtioFS
+// cache), and both are writable from a container. Steps after the first
+// failure are skipped. The error names the broken link; the results say which
+// steps ran.
+func (m *Manager) ValidateMountChain(config ContainerConfig) ([]MountCheck, error) {
+	type step struct {
+		name, path string
+		run        func() error
+	}
+	volume, workspace := config.VolumeMountPoint, config.WorkspacePath
+	steps := []step{
+		{"volume exists", volume, func() error { return hostDirExists(volume) }},
+		{"workspace exists", workspace, func() error { return hostDirExists(workspace) }},
+		{"volume accessible", volume, func() error { return m.probeHostPath(volume, "ls", "/probe") }},
+		{"workspace accessible", workspace, func() error { return m.probeHostPath(workspace, "ls", "/probe") }},
+		{"volume fresh", volume, func() error { return m.probeFreshness(volume) }},
+		{"volume writable", volume, func() error { return m.probeWritable(volume) }},
+		{"workspace writable", workspace, func() error { return m.probeWritable(workspace) }},
+	}
+	if config.Ephemeral {
+		// The volume is a tmpfs inside the container; there is no host path to check
+		steps = slices.DeleteFunc(steps, func(s step) bool { return strings.HasPrefix(s.name, "volume ") })
+	}
+
+	checks := make([]MountCheck, len(steps))
+	var broken error
+	for i, s := range steps {
+		checks[i] = MountCheck{Name: s.name, Path: s.path}
+		if broken != nil {
+			checks[i].Skipped = true
+			continue
+		}
+		if err := s.run(); err != nil {
+			checks[i].Err = err
+			broken = fmt.Errorf("mount chain broken at %s (%s): %w", s.name, s.path, err)
+		}
+	}
+	return checks, broken
+}
+
+// hostDirExists checks that path is an existing directory on the host.
+func hostDirExists(path string) error {
+	if path == "" {
+		return errors.New("path is empty")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	return nil
+}
+
+// probeHostPath runs command in the helper image with hostPath mounted at /probe.
+func (m *Manager) probeHostPath(hostPath string, command ...string) error {
+	_, err := m.probeHostPathOutput(hostPath, command...)
+	return err
+}
+
+// probeHostPathOutput is probeHostPath, returning the command's output.
+func (m *Manager) probeHostPathOutput(hostPath string, command ...string) (string, error) {
+	if err := m.ensureHelperImage(); err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeouts.Quick)
+	defer cancel()
+
+	args := append([]string{"run", "--rm", "-v", hostPath + ":/probe", m.helperImageName()}, command...)
+	output, err := exec.CommandContext(ctx, "docker", args...).CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("probe timed out after %v", m.timeouts.Quick)
+		}
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+// probeFreshness writes a file on the host and checks a container sees it.
+func (m *Manager) probeFreshness(hostPath string) error {
+	f, err := os.CreateTemp(hostPath, mountProbePrefix+"*")
+	if err != nil {
+		return fmt.Errorf("failed to write probe file: %w", err)
+	}
+	name := filepath.Base(f.Name())
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(name)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write probe file: %w", err)
+	}
+
+	output, err := m.probeHostPathOutput(hostPath, "cat", "/probe/"+name)
+	if err != nil {
+		return fmt.Errorf("Docker's view of the mount is stale: %w", err)
+	}
+	if strings.TrimSpace(output) != name {
+		return errors.New("Docker's view of the mount is stale: probe file contents differ")
+	}
+	return nil
+}
+
+// probeWritable creates and removes a file from inside a container.
+func (m *Manager) probeWritable(hostPath string) error {
+	probe := "/probe/" + mountProbePrefix + "write"
+	return m.probeHostPath(hostPath, "sh", "-c", "touch "+probe+" && rm "+probe)
+}
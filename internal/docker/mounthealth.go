@@ -0,0 +1,103 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// staleMountErrors are `ls` errors seen when a VirtioFS share has gone stale,
+// e.g. after the volume behind it was remounted.
+var staleMountErrors = []string{
+	"Stale file handle",
+	"No such file or directory",
+	"Transport endpoint is not connected",
+	"Input/output error",
+}
+
+// MountHealth is the result of probing one bind mount of a running container.
+type MountHealth struct {
+	Container string
+	Target    string // Container path of the mount
+	Source    string // Host path of the mount
+	OK        bool
+	// Stale is set when the mount failed in a way that matches Docker Desktop's
+	// stale VirtioFS state; Remount the volume and restart the container.
+	Stale bool
+	Error string
+}
+
+// CheckAllMounts lists each bind mount of every running capsule container
+// (within CAPSULE_NAME_PREFIX and the session filter) and checks it can be read
+// from inside the container. Failures are only classified as Stale on Docker
+// Desktop, where the VirtioFS cache is the likely cause; on other engines they
+// are reported as plain errors. Containers that stop mid-check are skipped.
+func (m *Manager) CheckAllMounts() ([]MountHealth, error) {
+	names, err := m.ListCapsuleContainers()
+	if err != nil {
+		return nil, err
+	}
+	caps := m.Capabilities()
+
+	var results []MountHealth
+	for _, name := range names {
+		info, err := m.inspectContainer(name)
+		if err != nil {
+			var notFound *ContainerNotFoundError
+			if errors.As(err, &notFound) {
+				continue
+			}
+			return results, err
+		}
+		if !info.State.Running {
+			continue
+		}
+		for _, mount := range info.Mounts {
+			if mount.Type != "bind" {
+				continue
+			}
+			results = append(results, m.probeMount(name, mount, caps))
+		}
+	}
+	return results, nil
+}
+
+// probeMount lists a mount's target inside the container.
+func (m *Manager) probeMount(containerName string, mount containerMount, caps EngineCapabilities) MountHealth {
+	health := MountHealth{Container: containerName, Target: mount.Destination, Source: mount.Source}
+	_, err := m.getCommandOutputWithTimeout(m.timeouts.Quick, "docker", "exec", containerName, "ls", "-A", mount.Destination)
+	if err == nil {
+		health.OK = true
+		return health
+	}
+
+	msg := err.Error()
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		msg = strings.TrimSpace(string(exitErr.Stderr))
+	}
+	health.Error = msg
+	health.Stale = caps.StaleMountDetection && isStaleMountError(msg)
+	return health
+}
+
+// isStaleMountError reports whether an error message matches a stale share.
+func isStaleMountError(msg string) bool {
+	for _, pattern := range staleMountErrors {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h MountHealth) String() string {
+	switch {
+	case h.OK:
+		return fmt.Sprintf("%s %s: ok", h.Container, h.Target)
+	case h.Stale:
+		return fmt.Sprintf("%s %s: stale (%s)", h.Container, h.Target, h.Error)
+	default:
+		return fmt.Sprintf("%s %s: %s", h.Container, h.Target, h.Error)
+	}
+}
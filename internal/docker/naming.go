@@ -0,0 +1,94 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// maxDockerNameLength is Docker's limit for container names, enforced by ValidateDockerName.
+const maxDockerNameLength = 128
+
+// NamePrefixEnv is the environment variable that namespaces capsule containers,
+// e.g. per CI job on a shared runner.
+const NamePrefixEnv = "CAPSULE_NAME_PREFIX"
+
+// invalidNameCharRegex matches characters not permitted in Docker container names.
+var invalidNameCharRegex = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// NamePrefix returns the container name prefix set by CAPSULE_NAME_PREFIX, or ""
+// when unset. The prefix must be a valid container name.
+func NamePrefix() (string, error) {
+	prefix := os.Getenv(NamePrefixEnv)
+	if prefix == "" {
+		return "", nil
+	}
+	if strings.Contains(prefix, ":") {
+		return "", fmt.Errorf("invalid %s %q: must not contain ':'", NamePrefixEnv, prefix)
+	}
+	if err := ValidateDockerName(prefix); err != nil {
+		return "", fmt.Errorf("invalid %s: %w", NamePrefixEnv, err)
+	}
+	return prefix, nil
+}
+
+// ApplyNamePrefix namespaces a container name with CAPSULE_NAME_PREFIX, if
+// set, as "<prefix>-<name>", so concurrent jobs on a shared host don't collide.
+// It is the one rule for prefixed names; GenerateContainerName and
+// ResolveContainerName("") apply it too.
+func ApplyNamePrefix(name string) (string, error) {
+	prefix, err := NamePrefix()
+	if err != nil {
+		return "", err
+	}
+	if prefix == "" {
+		return name, nil
+	}
+	return prefix + "-" + name, nil
+}
+
+// matchesNamePrefix reports whether name belongs to the configured name prefix.
+// Every name matches when no prefix is set.
+func matchesNamePrefix(name, prefix string) bool {
+	return prefix == "" || name == prefix || strings.HasPrefix(name, prefix+"-")
+}
+
+// GenerateContainerName builds a container name from a repository ID.
+// The result is DefaultContainerName followed by "-<repoID>", with invalid
+// characters replaced, under the name prefix (see ApplyNamePrefix), truncated
+// to Docker's length limit, and trimmed of trailing separators. It always
+// passes ValidateDockerName; if the repoID has no usable characters, the
+// prefixed DefaultContainerName is returned. An invalid prefix is ignored
+// here; ResolveContainerName reports it.
+func GenerateContainerName(repoID string) string {
+	base, err := ApplyNamePrefix(DefaultContainerName)
+	if err != nil {
+		base = DefaultContainerName
+	}
+	suffix := invalidNameCharRegex.ReplaceAllString(repoID, "-")
+	suffix = strings.Trim(suffix, "-_.")
+	if suffix == "" {
+		return base
+	}
+
+	name := base + "-" + suffix
+	if len(name) > maxDockerNameLength {
+		name = name[:maxDockerNameLength]
+	}
+	return strings.TrimRight(name, "-_.")
+}
+
+// ResolveContainerName returns the container name every Manager method uses for
+// name: DefaultContainerName under the name prefix (see ApplyNamePrefix) when
+// empty, otherwise name itself after validation. External tooling should call
+// it too so it agrees with Start and the detector.
+func ResolveContainerName(name string) (string, error) {
+	if name == "" {
+		return ApplyNamePrefix(DefaultContainerName)
+	}
+	if err := ValidateDockerName(name); err != nil {
+		return "", fmt.Errorf("invalid container name: %w", err)
+	}
+	return name, nil
+}
@@ -0,0 +1,84 @@
+package docker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateContainerName(t *testing.T) {
+	t.Setenv(NamePrefixEnv, "")
+	tests := []struct {
+		repoID string
+		want   string
+	}{
+		{"github.com-user-repo", "claude-capsule-github.com-user-repo"},
+		{"123-starts-with-digits", "claude-capsule-123-starts-with-digits"},
+		{"---", DefaultContainerName},
+		{"", DefaultContainerName},
+		{"has spaces/and:colons", "claude-capsule-has-spaces-and-colons"},
+	}
+
+	for _, tt := range tests {
+		got := GenerateContainerName(tt.repoID)
+		if got != tt.want {
+			t.Errorf("GenerateContainerName(%q) = %q, want %q", tt.repoID, got, tt.want)
+		}
+		if err := ValidateDockerName(got); err != nil {
+			t.Errorf("GenerateContainerName(%q) = %q fails validation: %v", tt.repoID, got, err)
+		}
+	}
+}
+
+func TestGenerateContainerName_Truncates(t *testing.T) {
+	t.Setenv(NamePrefixEnv, "")
+	// Place a separator right at the truncation boundary
+	repoID := strings.Repeat("a", maxDockerNameLength-len(DefaultContainerName)-2) + "-bbbb"
+
+	got := GenerateContainerName(repoID)
+	if len(got) > maxDockerNameLength {
+		t.Errorf("GenerateContainerName() length = %d, want <= %d", len(got), maxDockerNameLength)
+	}
+	if strings.HasSuffix(got, "-") {
+		t.Errorf("GenerateContainerName() = %q ends with a separator", got)
+	}
+	if err := ValidateDockerName(got); err != nil {
+		t.Errorf("GenerateContainerName() = %q fails validation: %v", got, err)
+	}
+}
+
+func TestResolveContainerName(t *testing.T) {
+	t.Setenv(NamePrefixEnv, "")
+	if got, err := ResolveContainerName(""); err != nil || got != DefaultContainerName {
+		t.Errorf("ResolveContainerName(\"\") = %q, %v, want %q", got, err, DefaultContainerName)
+	}
+	if got, err := ResolveContainerName("claude-abc123"); err != nil || got != "claude-abc123" {
+		t.Errorf("ResolveContainerName(%q) = %q, %v", "claude-abc123", got, err)
+	}
+	if _, err := ResolveContainerName("bad name!"); err == nil {
+		t.Error("ResolveContainerName(invalid) error = nil, want error")
+	}
+}
+
+func TestNamePrefix(t *testing.T) {
+	t.Setenv(NamePrefixEnv, "ci-job1")
+
+	if got := GenerateContainerName("github.com-org-repo"); got != "ci-job1-claude-capsule-github.com-org-repo" {
+		t.Errorf("GenerateContainerName() = %q, want prefix ci-job1", got)
+	}
+	if got, err := ResolveContainerName(""); err != nil || got != "ci-job1-claude-capsule" {
+		t.Errorf("ResolveContainerName(\"\") = %q, %v, want %q", got, err, "ci-job1-claude-capsule")
+	}
+	if got, err := ApplyNamePrefix("claude-abc123"); err != nil || got != "ci-job1-claude-abc123" {
+		t.Errorf("ApplyNamePrefix() = %q, %v, want %q", got, err, "ci-job1-claude-abc123")
+	}
+	if !matchesNamePrefix("ci-job1-abc", "ci-job1") || matchesNamePrefix("ci-job10-abc", "ci-job1") {
+		t.Error("matchesNamePrefix() does not respect the prefix boundary")
+	}
+
+	for _, invalid := range []string{"-job", "job:1", "bad prefix"} {
+		t.Setenv(NamePrefixEnv, invalid)
+		if _, err := ResolveContainerName(""); err == nil {
+			t.Errorf("ResolveContainerName(\"\") with prefix %q: want error", invalid)
+		}
+	}
+}
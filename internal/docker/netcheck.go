@@ -0,0 +1,134 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultNetCheckTargets are checked by NetCheck when no targets are given.
+var DefaultNetCheckTargets = []string{"api.anthropic.com:443"}
+
+// netCheckTimeoutPerTarget bounds each target's DNS, connect and TLS steps.
+const netCheckTimeoutPerTarget = 20 * time.Second
+
+// NetStage identifies the step at which a NetCheck target failed.
+type NetStage string
+
+const (
+	NetStageDNS     NetStage = "dns"
+	NetStageConnect NetStage = "connect"
+	NetStageTLS     NetStage = "tls"
+)
+
+// NetResult is the outcome of checking one target from inside the container.
+type NetResult struct {
+	Target   string   `json:"target"`
+	Address  string   `json:"address,omitempty"`   // Resolved IP address
+	FailedAt NetStage `json:"failed_at,omitempty"` // Empty if every step succeeded
+	Error    string   `json:"error,omitempty"`
+}
+
+// OK reports whether the target was reachable.
+func (r NetResult) OK() bool {
+	return r.FailedAt == ""
+}
+
+// netCheckScript resolves and connects to each host:port argument, doing a TLS
+// handshake on port 443, and prints one JSON result per line. It uses node,
+// which the capsule image needs for Claude anyway.
+const netCheckScript = `const dns=require('dns'),net=require('net'),tls=require('tls');
+const T=5000;
+function check(t){return new Promise(r=>{
+const i=t.lastIndexOf(':');const host=i>0?t.slice(0,i):t;const port=i>0?Number(t.slice(i+1)):443;
+const out={target:t};let done=false;
+const finish=()=>{if(!done){done=true;r(out)}};
+const fail=(s,e)=>{if(!done){out.failed_at=s;out.error=String(e&&e.message||e);finish()}};
+dns.lookup(host,(e,addr)=>{
+if(e)return fail('dns',e);
+out.address=addr;
+const s=net.connect({host:addr,port});
+s.setTimeout(T,()=>{s.destroy();fail('connect','timed out')});
+s.once('error',e=>fail('connect',e));
+s.once('connect',()=>{
+s.setTimeout(0);s.removeAllListeners('error');
+if(port!==443){s.destroy();return finish()}
+const ts=tls.connect({socket:s,servername:host});
+ts.setTimeout(T,()=>{ts.destroy();fail('tls','timed out')});
+ts.once('error',e=>fail('tls',e));
+ts.once('secureConnect',()=>{ts.destroy();finish()});
+});
+});
+})}
+(async()=>{for(const t of process.argv.slice(1))console.log(JSON.stringify(await check(t)))})();`
+
+// NetCheck checks from inside a running container that each target
+// ("host" or "host:port", port defaulting to 443) resolves, accepts a TCP
+// connection and, on port 443, completes a TLS handshake. Results are returned
+// in target order; a failing target is a result, not an error. Uses
+// DefaultNetCheckTargets when targets is empty. Returns
+// *ContainerNotFoundError if the container does not exist.
+func (m *Manager) NetCheck(containerName string, targets []string) ([]NetResult, error) {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		targets = DefaultNetCheckTargets
+	}
+	for _, target := range targets {
+		if err := validateNetTarget(target); err != nil {
+			return nil, err
+		}
+	}
+	if !m.containerExists(containerName) {
+		return nil, &ContainerNotFoundError{Name: containerName}
+	}
+
+	args := append([]string{"exec", containerName, "node", "-e", netCheckScript}, targets...)
+	timeout := netCheckTimeoutPerTarget * time.Duration(len(targets))
+	output, err := m.getCommandOutputWithTimeout(timeout, "docker", args...)
+	if err != nil {
+		return nil, fmt.Errorf("network check failed: %w", err)
+	}
+	return parseNetCheckOutput(string(output), targets)
+}
+
+// validateNetTarget checks a "host" or "host:port" target.
+func validateNetTarget(target string) error {
+	host, port := target, ""
+	if idx := strings.LastIndex(target, ":"); idx >= 0 {
+		host, port = target[:idx], target[idx+1:]
+	}
+	if host == "" || strings.ContainsAny(host, " \t\n/") {
+		return fmt.Errorf("invalid network check target %q: want host or host:port", target)
+	}
+	if port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("invalid port in network check target %q", target)
+		}
+	}
+	return nil
+}
+
+// parseNetCheckOutput decodes the script's JSON lines into one result per target.
+func parseNetCheckOutput(output string, targets []string) ([]NetResult, error) {
+	var results []NetResult
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var r NetResult
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			return nil, fmt.Errorf("unexpected network check output %q: %w", line, err)
+		}
+		results = append(results, r)
+	}
+	if len(results) != len(targets) {
+		return nil, fmt.Errorf("network check returned %d results for %d targets", len(results), len(targets))
+	}
+	return results, nil
+}
@@ -0,0 +1,36 @@
+package docker
+
+import "testing"
+
+func TestParseNetCheckOutput(t *testing.T) {
+	output := `{"target":"api.anthropic.com:443","address":"160.79.104.10"}
+{"target":"internal:8080","failed_at":"dns","error":"getaddrinfo ENOTFOUND internal"}
+`
+	results, err := parseNetCheckOutput(output, []string{"api.anthropic.com:443", "internal:8080"})
+	if err != nil {
+		t.Fatalf("parseNetCheckOutput() error = %v", err)
+	}
+	if !results[0].OK() || results[0].Address != "160.79.104.10" {
+		t.Errorf("results[0] = %+v, want OK with address", results[0])
+	}
+	if results[1].OK() || results[1].FailedAt != NetStageDNS {
+		t.Errorf("results[1] = %+v, want DNS failure", results[1])
+	}
+
+	if _, err := parseNetCheckOutput(output, []string{"one"}); err == nil {
+		t.Error("parseNetCheckOutput() accepted a result count mismatch")
+	}
+}
+
+func TestValidateNetTarget(t *testing.T) {
+	for _, target := range []string{"api.anthropic.com", "api.anthropic.com:443", "10.0.0.1:8080"} {
+		if err := validateNetTarget(target); err != nil {
+			t.Errorf("validateNetTarget(%q) error = %v", target, err)
+		}
+	}
+	for _, target := range []string{"", ":443", "host:0", "host:https", "https://host"} {
+		if err := validateNetTarget(target); err == nil {
+			t.Errorf("validateNetTarget(%q) = nil, want error", target)
+		}
+	}
+}
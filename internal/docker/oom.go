@@ -0,0 +1,45 @@
+package docker
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Range of --oom-score-adj accepted by the kernel
+const (
+	minOOMScoreAdj = -1000
+	maxOOMScoreAdj = 1000
+)
+
+// oomArgs returns the OOM killer flags for `docker run`.
+func (c *ContainerConfig) oomArgs() []string {
+	var args []string
+	if c.OOMScoreAdj != nil {
+		args = append(args, "--oom-score-adj", strconv.Itoa(*c.OOMScoreAdj))
+	}
+	if c.OOMKillDisable {
+		args = append(args, "--oom-kill-disable")
+	}
+	return args
+}
+
+// hasMemoryLimit reports whether ExtraArgs set a memory limit.
+func (c *ContainerConfig) hasMemoryLimit() bool {
+	for _, arg := range c.ExtraArgs {
+		flag, _, _ := strings.Cut(arg, "=")
+		if flag == "--memory" || flag == "-m" {
+			return true
+		}
+	}
+	return false
+}
+
+// Warnings returns advice about valid but risky settings, for callers to show
+// before starting the container.
+func (c *ContainerConfig) Warnings() []string {
+	var warnings []string
+	if c.OOMKillDisable && !c.hasMemoryLimit() {
+		warnings = append(warnings, "OOM kill is disabled without a memory limit (--memory); the container can exhaust host memory and hang the system")
+	}
+	return warnings
+}
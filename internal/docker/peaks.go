@@ -0,0 +1,58 @@
+package docker
+
+import (
+	"context"
+	"time"
+)
+
+// peakPollInterval is how often TrackPeaks samples container stats.
+const peakPollInterval = 2 * time.Second
+
+// Peaks holds the highest resource usage observed by TrackPeaks.
+type Peaks struct {
+	CPUPercent    float64
+	MemoryPercent float64
+	MemoryUsage   uint64 // Bytes
+	IOBytes       uint64 // Combined network and block I/O, cumulative since container start
+	PIDs          int
+	Samples       int // Number of successful samples the peaks are based on
+}
+
+// observe folds a stats sample into the peaks.
+func (p *Peaks) observe(s *ContainerStats) {
+	p.Samples++
+	p.CPUPercent = max(p.CPUPercent, s.CPUPercent)
+	p.MemoryPercent = max(p.MemoryPercent, s.MemoryPercent)
+	p.MemoryUsage = max(p.MemoryUsage, s.MemoryUsage)
+	p.IOBytes = max(p.IOBytes, s.TotalIO())
+	p.PIDs = max(p.PIDs, s.PIDs)
+}
+
+// TrackPeaks samples the container's stats until ctx is cancelled or the
+// container stops, and returns the highest values observed. Cancellation is
+// the normal way to end tracking and is not an error; failed samples are
+// skipped, so check Peaks.Samples before reporting.
+func (m *Manager) TrackPeaks(ctx context.Context, containerName string) (*Peaks, error) {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return nil, err
+	}
+
+	peaks := &Peaks{}
+	ticker := time.NewTicker(peakPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if stats, err := m.Stats(containerName); err == nil {
+			peaks.observe(stats)
+		} else if !m.IsRunning(containerName) {
+			return peaks, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return peaks, nil
+		case <-ticker.C:
+		}
+	}
+}
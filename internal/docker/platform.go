@@ -0,0 +1,93 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// validPlatformPattern matches docker's os/arch[/variant] platform strings.
+var validPlatformPattern = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$`)
+
+// engineArch returns the Docker engine's architecture; tests override it.
+var engineArch = func() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), quickCommandTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "docker", "version", "--format", "{{.Server.Arch}}").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to query docker engine architecture: %w", err)
+	}
+	arch := strings.TrimSpace(string(output))
+	if arch == "" {
+		return "", fmt.Errorf("docker engine reported no architecture")
+	}
+	return arch, nil
+}
+
+var (
+	detectArchOnce sync.Once
+	detectedArch   string
+)
+
+// DetectArch returns the Docker engine's CPU architecture in docker's naming
+// (e.g. "arm64" on Apple Silicon, "amd64" on Intel), as reported by
+// `docker version`. The engine, not this binary, decides what runs natively:
+// an amd64 build of capsule under Rosetta still talks to an arm64 engine. If
+// the engine cannot be queried the binary's architecture is used. The result
+// is cached for the life of the process.
+func DetectArch() string {
+	detectArchOnce.Do(func() {
+		arch, err := engineArch()
+		if err != nil {
+			arch = runtime.GOARCH
+		}
+		detectedArch = arch
+	})
+	return detectedArch
+}
+
+// ResolvePlatform returns the platform capsule runs and pulls the image for.
+// Precedence: ContainerConfig.Platform if set, otherwise linux/DetectArch().
+// Every `docker run` (and so the pull its --pull policy triggers) and the
+// local image check use it, so the image pulled is always the one run.
+func ResolvePlatform(config ContainerConfig) string {
+	if config.Platform != "" {
+		return config.Platform
+	}
+	return "linux/" + DetectArch()
+}
+
+// validatePlatform checks a platform override is os/arch[/variant].
+func validatePlatform(platform string) error {
+	if platform != "" && !validPlatformPattern.MatchString(platform) {
+		return fmt.Errorf("invalid platform %q: must be os/arch[/variant], e.g. linux/amd64", platform)
+	}
+	return nil
+}
+
+// platformMatches reports whether an image built for got can run as want. The
+// variant is only compared when both specify one, since docker often omits it.
+func platformMatches(want, got string) bool {
+	wantParts, gotParts := strings.Split(want, "/"), strings.Split(got, "/")
+	if len(wantParts) < 2 || len(gotParts) < 2 {
+		return false
+	}
+	if wantParts[0] != gotParts[0] || wantParts[1] != gotParts[1] {
+		return false
+	}
+	return len(wantParts) < 3 || len(gotParts) < 3 || wantParts[2] == gotParts[2]
+}
+
+// imagePlatform returns the os/arch[/variant] of a local image.
+func imagePlatform(imageName string) (string, error) {
+	format := "{{.Os}}/{{.Architecture}}{{if .Variant}}/{{.Variant}}{{end}}"
+	output, err := exec.Command("docker", "image", "inspect", "--format", format, imageName).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect image %s: %w", imageName, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,70 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// PostStartError reports a post-start command that failed, with its combined output.
+type PostStartError struct {
+	Command  []string
+	ExitCode int // -1 if the command could not be run
+	Output   string
+	Err      error
+}
+
+func (e *PostStartError) Error() string {
+	msg := fmt.Sprintf("post-start command %q failed", strings.Join(e.Command, " "))
+	if e.ExitCode >= 0 {
+		msg += fmt.Sprintf(" with exit code %d", e.ExitCode)
+	} else if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	if output := strings.TrimSpace(e.Output); output != "" {
+		msg += ":\n" + output
+	}
+	return msg
+}
+
+func (e *PostStartError) Unwrap() error { return e.Err }
+
+// RunPostStart runs config.PostStartCommands in order inside the running
+// container, in the configured working directory. Call it after
+// SetupWorkspaceSymlink. It stops at the first failure unless
+// config.PostStartContinueOnError is set, in which case every command runs and
+// the failures are joined. Each failure is a *PostStartError.
+func (m *Manager) RunPostStart(config ContainerConfig) error {
+	containerName, err := ResolveContainerName(config.ContainerName)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, command := range config.PostStartCommands {
+		if err := m.runPostStartCommand(containerName, config.WorkDir(), command); err != nil {
+			if !config.PostStartContinueOnError {
+				return err
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runPostStartCommand execs a single command, capturing its output on failure.
+// Setup steps such as installing dependencies can be slow, so there is no timeout.
+func (m *Manager) runPostStartCommand(containerName, workDir string, command []string) error {
+	args := append([]string{"exec", "-w", workDir, containerName}, command...)
+	output, err := exec.Command("docker", args...).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+
+	postErr := &PostStartError{Command: command, ExitCode: -1, Output: string(output), Err: err}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		postErr.ExitCode = exitErr.ExitCode()
+	}
+	return postErr
+}
@@ -0,0 +1,67 @@
+package docker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Process is a process running in a container, as reported by `docker top`.
+type Process struct {
+	PID        int // PID on the Docker host (or VM), not inside the container
+	User       string
+	CPUPercent float64
+	Command    string // Full command line
+}
+
+// Processes lists the processes running in the container using `docker top`,
+// which works without exec'ing into it. Returns *ContainerNotFoundError if
+// the container does not exist.
+func (m *Manager) Processes(containerName string) ([]Process, error) {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return nil, err
+	}
+	if !m.containerExists(containerName) {
+		return nil, &ContainerNotFoundError{Name: containerName}
+	}
+
+	output, err := m.getCommandOutputWithTimeout(m.timeouts.Command,
+		"docker", "top", containerName, "-eo", "pid,user,pcpu,args")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list container processes: %w", err)
+	}
+	return parseTop(string(output))
+}
+
+// parseTop parses `docker top ... -eo pid,user,pcpu,args` output. The header
+// line is skipped; the command is everything after the third column.
+func parseTop(output string) ([]Process, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		return nil, nil
+	}
+
+	var processes []Process
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid PID in %q: %w", line, err)
+		}
+		cpu, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU usage in %q: %w", line, err)
+		}
+		processes = append(processes, Process{
+			PID:        pid,
+			User:       fields[1],
+			CPUPercent: cpu,
+			Command:    strings.Join(fields[3:], " "),
+		})
+	}
+	return processes, nil
+}
@@ -0,0 +1,70 @@
+package docker
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetReadinessProbe sets a command run with `docker exec` that must exit 0 for
+// the container to count as ready, e.g. {"test", "-f", "/tmp/init-done"}. Image
+// authors can use it when "running" is not enough. Nil restores the default,
+// where a running container is ready.
+func (m *Manager) SetReadinessProbe(cmd []string) error {
+	if len(cmd) > 0 && cmd[0] == "" {
+		return fmt.Errorf("readiness probe command cannot be empty")
+	}
+	m.readinessProbe = append([]string(nil), cmd...)
+	return nil
+}
+
+// IsReady reports whether the container is running and, if a readiness probe
+// is configured, whether the probe succeeds.
+func (m *Manager) IsReady(containerName string) bool {
+	if !m.IsRunning(containerName) {
+		return false
+	}
+	if len(m.readinessProbe) == 0 {
+		return true
+	}
+	args := append([]string{"exec", containerName}, m.readinessProbe...)
+	return m.runCommandWithTimeout(m.timeouts.Quick, "docker", args...) == nil
+}
+
+// SetReadinessTimeout sets how long SetupWorkspaceSymlink waits for the
+// container to become ready before running the setup script. It is separate
+// from the script's own command timeout. It is the same setting as
+// Timeouts.Readiness; the default is 5 seconds.
+func (m *Manager) SetReadinessTimeout(timeout time.Duration) error {
+	if timeout <= 0 {
+		return fmt.Errorf("readiness timeout must be positive, got %v", timeout)
+	}
+	m.timeouts.Readiness = timeout
+	return nil
+}
+
+// readinessRetries converts the readiness timeout into a poll count and
+// delay, polling every containerReadyRetryDelay (or faster for short timeouts).
+func (m *Manager) readinessRetries() (retries int, delay time.Duration, timeout time.Duration) {
+	timeout = m.timeouts.Readiness
+	if timeout <= 0 {
+		timeout = defaultReadinessTimeout
+	}
+	delay = min(containerReadyRetryDelay, timeout)
+	retries = int((timeout + delay - 1) / delay)
+	return retries, delay, timeout
+}
+
+// waitReady polls IsReady until it succeeds or the readiness timeout passes.
+func (m *Manager) waitReady(containerName string) error {
+	retries, delay, timeout := m.readinessRetries()
+	for i := 0; i < retries; i++ {
+		if m.IsReady(containerName) {
+			return nil
+		}
+		time.Sleep(delay)
+	}
+	if len(m.readinessProbe) > 0 && m.IsRunning(containerName) {
+		return fmt.Errorf("container %s readiness probe did not succeed within %v", containerName, timeout)
+	}
+	return fmt.Errorf("container %s not running within %v", containerName, timeout)
+}
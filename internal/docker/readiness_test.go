@@ -0,0 +1,31 @@
+package docker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReadinessRetries(t *testing.T) {
+	m := NewManager()
+	if retries, delay, timeout := m.readinessRetries(); retries != containerReadyMaxRetries || delay != containerReadyRetryDelay || timeout != 5*time.Second {
+		t.Errorf("default readinessRetries() = %d, %v, %v", retries, delay, timeout)
+	}
+
+	if err := m.SetReadinessTimeout(1200 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if retries, delay, _ := m.readinessRetries(); retries != 3 || delay != containerReadyRetryDelay {
+		t.Errorf("readinessRetries() = %d, %v, want 3 polls covering 1.2s", retries, delay)
+	}
+
+	if err := m.SetReadinessTimeout(100 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if retries, delay, _ := m.readinessRetries(); retries != 1 || delay != 100*time.Millisecond {
+		t.Errorf("readinessRetries() = %d, %v, want one short poll", retries, delay)
+	}
+
+	if err := m.SetReadinessTimeout(0); err == nil {
+		t.Error("SetReadinessTimeout(0) succeeded")
+	}
+}
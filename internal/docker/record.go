@@ -0,0 +1,73 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// SetRecordPath records interactive sessions started by Exec, ExecInDir, and
+// ExecWithCleanup to a transcript at recordPath on the host, using script(1).
+// script allocates a terminal matching the current size and follows resizes;
+// output is flushed as it is written so the transcript is complete even if the
+// session ends by signal. An existing file is overwritten. The transcript can
+// hold secrets typed in the session, so it is created private up front rather
+// than with script's umask-based mode. An empty path disables recording (the
+// default).
+func (m *Manager) SetRecordPath(recordPath string) error {
+	if recordPath == "" {
+		m.recordPath = ""
+		return nil
+	}
+	if !filepath.IsAbs(recordPath) {
+		return fmt.Errorf("record path must be absolute: %q", recordPath)
+	}
+	if info, err := os.Stat(filepath.Dir(recordPath)); err != nil || !info.IsDir() {
+		return fmt.Errorf("record path directory does not exist: %s", filepath.Dir(recordPath))
+	}
+	file, err := os.OpenFile(recordPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, constants.FilePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to create record file %s: %w", recordPath, err)
+	}
+	file.Close()
+	// OpenFile does not change the mode of an existing file
+	if err := os.Chmod(recordPath, constants.FilePermissions); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", recordPath, err)
+	}
+	m.recordPath = recordPath
+	return nil
+}
+
+// recordingCommand wraps a docker invocation in script(1) writing to recordPath.
+// BSD script (macOS) takes the command as arguments; util-linux script takes it
+// as a single shell string and needs -e to return the command's exit status.
+func recordingCommand(goos, recordPath string, dockerArgs []string) (string, []string) {
+	if goos == "darwin" {
+		return "script", append([]string{"-q", "-F", recordPath, "docker"}, dockerArgs...)
+	}
+
+	quoted := make([]string, 0, len(dockerArgs)+1)
+	quoted = append(quoted, "docker")
+	for _, arg := range dockerArgs {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	return "script", []string{"-q", "-f", "-e", "-c", strings.Join(quoted, " "), recordPath}
+}
+
+// shellQuote single-quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// dockerCommandName returns the program and arguments that run docker with
+// args, wrapped in a recorder when a record path is set.
+func (m *Manager) dockerCommandName(args []string) (string, []string) {
+	if m.recordPath == "" {
+		return "docker", args
+	}
+	return recordingCommand(runtime.GOOS, m.recordPath, args)
+}
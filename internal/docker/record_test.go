@@ -0,0 +1,51 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+func TestRecordingCommand(t *testing.T) {
+	dockerArgs := []string{"exec", "-it", "-w", "/workspace/it's", "claude-capsule", DefaultShell}
+
+	name, args := recordingCommand("darwin", "/tmp/session.log", dockerArgs)
+	want := append([]string{"-q", "-F", "/tmp/session.log", "docker"}, dockerArgs...)
+	if name != "script" || !reflect.DeepEqual(args, want) {
+		t.Errorf("darwin: %s %v, want script %v", name, args, want)
+	}
+
+	name, args = recordingCommand("linux", "/tmp/session.log", dockerArgs)
+	want = []string{"-q", "-f", "-e", "-c",
+		`docker 'exec' '-it' '-w' '/workspace/it'\''s' 'claude-capsule' '/usr/bin/fish'`,
+		"/tmp/session.log"}
+	if name != "script" || !reflect.DeepEqual(args, want) {
+		t.Errorf("linux: %s %v, want script %v", name, args, want)
+	}
+}
+
+func TestSetRecordPath_PrivateTranscript(t *testing.T) {
+	recordPath := filepath.Join(t.TempDir(), "session.log")
+	if err := os.WriteFile(recordPath, []byte("old session"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager()
+	if err := m.SetRecordPath(recordPath); err != nil {
+		t.Fatalf("SetRecordPath() error = %v", err)
+	}
+	info, err := os.Stat(recordPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != constants.FilePermissions || info.Size() != 0 {
+		t.Errorf("transcript mode = %v, size = %d, want %v and empty", info.Mode().Perm(), info.Size(), constants.FilePermissions)
+	}
+
+	if err := m.SetRecordPath("relative.log"); err == nil {
+		t.Error("SetRecordPath() accepted a relative path")
+	}
+}
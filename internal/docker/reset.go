@@ -0,0 +1,56 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+)
+
+// stuckStates are container states that `docker stop` cannot resolve: the
+// container never started, is looping under a restart policy, or is dead.
+var stuckStates = map[string]bool{
+	"created":    true,
+	"restarting": true,
+	"dead":       true,
+}
+
+// ForceReset removes a container regardless of its state. Containers stuck in
+// Created, Restarting, or Dead go straight to `docker rm -f`, after disabling
+// the restart policy to break a restart loop; other states use Stop. Returns
+// nil if the container does not exist.
+func (m *Manager) ForceReset(containerName string) error {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return err
+	}
+
+	info, err := m.inspectContainer(containerName)
+	if err != nil {
+		var notFound *ContainerNotFoundError
+		if errors.As(err, &notFound) {
+			return nil
+		}
+		return err
+	}
+	if !stuckStates[info.State.Status] {
+		return m.Stop(containerName)
+	}
+
+	// Serialize with other capsule processes operating on this container
+	lock, err := acquireContainerLock(containerName)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	if info.State.Status == "restarting" {
+		// Best effort: rm -f usually wins the race even if this fails
+		_ = m.runCommandWithTimeout(m.timeouts.Command, "docker", "update", "--restart=no", containerName)
+	}
+	if err := m.RemoveContainer(containerName); err != nil {
+		return fmt.Errorf("container %s is stuck in state %q and could not be removed: %w", containerName, info.State.Status, err)
+	}
+	if m.containerExists(containerName) {
+		return fmt.Errorf("container %s is stuck in state %q and still exists after removal", containerName, info.State.Status)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package docker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EngineResources is the memory and CPU available to the Docker engine. On
+// Docker Desktop and other VM-backed engines this is the VM's allocation, not
+// the host's, and caps every container regardless of its own limits.
+type EngineResources struct {
+	MemoryBytes uint64 // Zero if unknown
+	CPUs        int    // Zero if unknown
+	Note        string // Why a value is unknown, if one is
+}
+
+// EngineResources reads the engine's total memory and CPU count from
+// `docker info`. Values the engine doesn't report are left zero and
+// explained in Note rather than returned as an error.
+func (m *Manager) EngineResources() (*EngineResources, error) {
+	info, err := m.engineInfo()
+	if err != nil {
+		return nil, err
+	}
+	return engineResources(info), nil
+}
+
+// engineResources extracts EngineResources from docker info.
+func engineResources(info engineInfo) *EngineResources {
+	res := &EngineResources{CPUs: info.NCPU}
+	if info.MemTotal > 0 {
+		res.MemoryBytes = uint64(info.MemTotal)
+	}
+	var missing []string
+	if res.MemoryBytes == 0 {
+		missing = append(missing, "memory")
+	}
+	if res.CPUs == 0 {
+		missing = append(missing, "CPU count")
+	}
+	if len(missing) > 0 {
+		res.Note = fmt.Sprintf("docker info did not report engine %s", strings.Join(missing, " or "))
+	}
+	return res
+}
+
+// ResourceWarnings returns advice when the memory (--memory) or CPU (--cpus)
+// limits in ExtraArgs exceed what the engine has, since the engine's own
+// allocation then applies instead.
+func (c *ContainerConfig) ResourceWarnings(res *EngineResources) []string {
+	if res == nil {
+		return nil
+	}
+	var warnings []string
+	if value, ok := c.extraArgValue("--memory", "-m"); ok && res.MemoryBytes > 0 {
+		if limit, err := parseDockerMemory(value); err == nil && limit > res.MemoryBytes {
+			warnings = append(warnings, fmt.Sprintf("memory limit %s exceeds the %d MiB available to the Docker engine; raise the engine's memory allocation",
+				value, res.MemoryBytes>>20))
+		}
+	}
+	if value, ok := c.extraArgValue("--cpus"); ok && res.CPUs > 0 {
+		if cpus, err := strconv.ParseFloat(value, 64); err == nil && cpus > float64(res.CPUs) {
+			warnings = append(warnings, fmt.Sprintf("CPU limit %s exceeds the %d CPUs available to the Docker engine", value, res.CPUs))
+		}
+	}
+	return warnings
+}
+
+// extraArgValue returns the value of the first of flags in ExtraArgs, given
+// either as --flag=value or as --flag value.
+func (c *ContainerConfig) extraArgValue(flags ...string) (string, bool) {
+	for i, arg := range c.ExtraArgs {
+		flag, value, hasValue := strings.Cut(arg, "=")
+		for _, f := range flags {
+			if flag != f {
+				continue
+			}
+			if hasValue {
+				return value, true
+			}
+			if i+1 < len(c.ExtraArgs) {
+				return c.ExtraArgs[i+1], true
+			}
+		}
+	}
+	return "", false
+}
+
+// dockerMemoryUnits are the binary unit suffixes docker accepts for --memory.
+var dockerMemoryUnits = map[byte]uint64{'b': 1, 'k': 1 << 10, 'm': 1 << 20, 'g': 1 << 30}
+
+// parseDockerMemory parses a --memory value such as "512m" or "4g". A value
+// without a unit is in bytes.
+func parseDockerMemory(s string) (uint64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	multiplier := uint64(1)
+	if s != "" {
+		if m, ok := dockerMemoryUnits[s[len(s)-1]]; ok {
+			multiplier = m
+			s = s[:len(s)-1]
+		}
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid memory size %q", s)
+	}
+	return uint64(value * float64(multiplier)), nil
+}
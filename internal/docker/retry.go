@@ -0,0 +1,50 @@
+package docker
+
+import "strings"
+
+// permanentErrorPatterns identify docker failures that retrying cannot fix.
+// These are checked first so a message matching both lists is never retried.
+var permanentErrorPatterns = []string{
+	"invalid reference format",
+	"no such image",
+	"pull access denied",
+	"manifest unknown",
+	"repository does not exist",
+	"invalid mount config",
+}
+
+// transientErrorPatterns identify docker failures that typically succeed on retry.
+var transientErrorPatterns = []string{
+	// Docker Desktop VirtioFS mount cache is stale
+	"file exists",
+	"error while creating mount source path",
+	// Daemon still starting or briefly unavailable
+	"cannot connect to the docker daemon",
+	"is the docker daemon running",
+	"connection refused",
+	// Network hiccups talking to the daemon or registry
+	"i/o timeout",
+	"tls handshake timeout",
+	"context deadline exceeded",
+	"resource temporarily unavailable",
+}
+
+// IsTransientDockerError reports whether docker stderr output describes a
+// transient failure worth retrying, such as a stale VirtioFS mount or a daemon
+// that is still starting. Permanent failures like an invalid image reference
+// always return false. Matching is case-insensitive.
+func IsTransientDockerError(stderr string) bool {
+	msg := strings.ToLower(stderr)
+
+	for _, pattern := range permanentErrorPatterns {
+		if strings.Contains(msg, pattern) {
+			return false
+		}
+	}
+	for _, pattern := range transientErrorPatterns {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
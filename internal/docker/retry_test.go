@@ -0,0 +1,60 @@
+package docker
+
+import "testing"
+
+func TestIsTransientDockerError(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   bool
+	}{
+		{
+			name:   "virtiofs file exists",
+			stderr: `docker: Error response from daemon: error while creating mount source path '/Volumes/Capsule-abc123': mkdir /Volumes/Capsule-abc123: file exists.`,
+			want:   true,
+		},
+		{
+			name:   "daemon not running",
+			stderr: "Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?",
+			want:   true,
+		},
+		{
+			name:   "registry i/o timeout",
+			stderr: `Error response from daemon: Get "https://registry-1.docker.io/v2/": dial tcp: lookup registry-1.docker.io: i/o timeout`,
+			want:   true,
+		},
+		{
+			name:   "invalid reference",
+			stderr: "docker: invalid reference format: repository name must be lowercase.",
+			want:   false,
+		},
+		{
+			name:   "missing image",
+			stderr: "Error response from daemon: No such image: claude-capsule:latest",
+			want:   false,
+		},
+		{
+			name:   "pull denied with timeout noise",
+			stderr: "pull access denied for private/image, repository does not exist or may require 'docker login': i/o timeout",
+			want:   false,
+		},
+		{
+			name:   "unrelated failure",
+			stderr: "docker: Error response from daemon: Conflict. The container name \"/claude-abc\" is already in use.",
+			want:   false,
+		},
+		{
+			name:   "empty",
+			stderr: "",
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransientDockerError(tt.stderr); got != tt.want {
+				t.Errorf("IsTransientDockerError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
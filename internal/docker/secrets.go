@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+)
+
+// secretMounts returns SecretFiles as read-only mounts, sorted by container path
+// so the generated arguments are stable.
+func (c *ContainerConfig) secretMounts() []ExtraMount {
+	targets := make([]string, 0, len(c.SecretFiles))
+	for target := range c.SecretFiles {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	mounts := make([]ExtraMount, 0, len(targets))
+	for _, target := range targets {
+		mounts = append(mounts, ExtraMount{HostPath: c.SecretFiles[target], ContainerPath: target, ReadOnly: true})
+	}
+	return mounts
+}
+
+// validateSecretFiles checks each secret's container path is absolute and its
+// host file exists, is a regular file, and is readable only by its owner.
+// Bind mounts keep host permissions, so that is what the container sees.
+func validateSecretFiles(secrets map[string]string) error {
+	for target, hostPath := range secrets {
+		if !path.IsAbs(target) {
+			return fmt.Errorf("secret container path must be absolute: %q", target)
+		}
+		if err := validatePath(hostPath, "secret host path"); err != nil {
+			return err
+		}
+		info, err := os.Stat(hostPath)
+		if err != nil {
+			return fmt.Errorf("secret file for %s: %w", target, err)
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("secret file %s is not a regular file", hostPath)
+		}
+		if perm := info.Mode().Perm(); perm&0o077 != 0 {
+			return fmt.Errorf("secret file %s has mode %#o; restrict it to its owner (e.g. chmod 400)", hostPath, perm)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+)
+
+// DefaultSessionID returns the OS username, used to label containers when
+// ContainerConfig.SessionID is empty. Returns "" if it cannot be determined.
+func DefaultSessionID() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// validateSessionID rejects session IDs that cannot be used in a label filter.
+func validateSessionID(id string) error {
+	if strings.ContainsAny(id, "=,\n") {
+		return fmt.Errorf("invalid session ID %q: must not contain '=', ',' or newlines", id)
+	}
+	return nil
+}
+
+// SetSessionFilter scopes ListCapsuleContainers and StopByRepo (and so purge
+// and stop-all built on them) to containers labeled with sessionID, so users
+// on a shared host only touch their own capsules. Empty removes the filter.
+func (m *Manager) SetSessionFilter(sessionID string) error {
+	if err := validateSessionID(sessionID); err != nil {
+		return err
+	}
+	m.sessionFilter = sessionID
+	return nil
+}
+
+// sessionFilterArgs returns the `docker ps` filter for the session filter, if set.
+func (m *Manager) sessionFilterArgs() []string {
+	if m.sessionFilter == "" {
+		return nil
+	}
+	return []string{"--filter", "label=" + LabelSession + "=" + m.sessionFilter}
+}
+
+// sessionLabel returns the session label value for a configuration.
+func (c *ContainerConfig) sessionLabel() string {
+	if c.SessionID != "" {
+		return c.SessionID
+	}
+	return DefaultSessionID()
+}
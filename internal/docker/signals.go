@@ -0,0 +1,108 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/term"
+)
+
+// ErrInterrupted is returned by ExecWithCleanup when the session ended because
+// capsule received SIGINT or SIGTERM.
+var ErrInterrupted = errors.New("exec session interrupted")
+
+// ExecWithCleanup is like ExecInDir, but if capsule receives SIGINT or SIGTERM
+// while the shell is open, the signal is forwarded to the docker exec process and,
+// once it exits, cleanup runs before returning an error wrapping ErrInterrupted.
+// On a normal exit cleanup is not run. Signal handling is restored on return.
+func (m *Manager) ExecWithCleanup(containerName, workDir string, cleanup func()) (err error) {
+	defer m.track(PhaseExec, containerName, "")(&err)
+
+	cmd, err := m.execShellCommand(containerName, workDir)
+	if err != nil {
+		return err
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start exec session: %w", err)
+	}
+	defer forwardWindowResize(cmd.Process)()
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		return err
+	case sig := <-sigChan:
+		_ = cmd.Process.Signal(sig)
+		<-done
+		if cleanup != nil {
+			cleanup()
+		}
+		return fmt.Errorf("%w by %v", ErrInterrupted, sig)
+	}
+}
+
+// forwardWindowResize relays terminal resizes (SIGWINCH) to the docker exec
+// process, which resizes the container pty in response. The initial size is
+// passed up front by terminalSizeArgs. It does nothing if stdout is not a
+// terminal. Call the returned func to stop.
+func forwardWindowResize(process *os.Process) (stop func()) {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return func() {}
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGWINCH)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-sigChan:
+			case <-done:
+				return
+			}
+			_ = process.Signal(syscall.SIGWINCH)
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigChan)
+		close(done)
+	}
+}
+
+// terminalSizeArgs returns docker exec -e arguments setting COLUMNS and LINES
+// to the size of the terminal on stdout. docker exec can apply its initial
+// size before the pty exists, leaving full-screen programs at 80x24 until the
+// first resize; the shell picks these up at once instead. It returns nil if
+// stdout is not a terminal.
+func terminalSizeArgs() []string {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return nil
+	}
+	width, height, err := term.GetSize(fd)
+	if err != nil {
+		return nil
+	}
+	return sizeEnvArgs(width, height)
+}
+
+// sizeEnvArgs formats a terminal size as docker exec -e arguments. It returns
+// nil for a size that is not positive.
+func sizeEnvArgs(width, height int) []string {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	return []string{"-e", "COLUMNS=" + strconv.Itoa(width), "-e", "LINES=" + strconv.Itoa(height)}
+}
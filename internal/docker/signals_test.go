@@ -0,0 +1,18 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSizeEnvArgs(t *testing.T) {
+	want := []string{"-e", "COLUMNS=120", "-e", "LINES=40"}
+	if got := sizeEnvArgs(120, 40); !reflect.DeepEqual(got, want) {
+		t.Errorf("sizeEnvArgs(120, 40) = %q, want %q", got, want)
+	}
+	for _, size := range [][2]int{{0, 40}, {120, 0}, {-1, -1}} {
+		if got := sizeEnvArgs(size[0], size[1]); got != nil {
+			t.Errorf("sizeEnvArgs(%d, %d) = %q, want nil", size[0], size[1], got)
+		}
+	}
+}
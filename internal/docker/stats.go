@@ -0,0 +1,152 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ContainerStats is a point-in-time resource usage sample for a container.
+type ContainerStats struct {
+	CPUPercent    float64
+	MemoryPercent float64
+	MemoryUsage   uint64 // Bytes
+	NetInput      uint64 // Cumulative bytes received
+	NetOutput     uint64 // Cumulative bytes sent
+	BlockRead     uint64 // Cumulative bytes read from block devices
+	BlockWrite    uint64 // Cumulative bytes written to block devices
+	PIDs          int
+}
+
+// TotalIO returns the combined network and block I/O byte count.
+func (s *ContainerStats) TotalIO() uint64 {
+	return s.NetInput + s.NetOutput + s.BlockRead + s.BlockWrite
+}
+
+// dockerStatsJSON mirrors the fields emitted by `docker stats --format '{{json .}}'`.
+type dockerStatsJSON struct {
+	CPUPerc  string `json:"CPUPerc"`
+	MemPerc  string `json:"MemPerc"`
+	MemUsage string `json:"MemUsage"`
+	NetIO    string `json:"NetIO"`
+	BlockIO  string `json:"BlockIO"`
+	PIDs     string `json:"PIDs"`
+}
+
+// Stats returns a single resource usage sample for the container.
+func (m *Manager) Stats(containerName string) (*ContainerStats, error) {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := m.getCommandOutputWithTimeout(m.timeouts.Command,
+		"docker", "stats", "--no-stream", "--format", "{{json .}}", containerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container stats: %w", err)
+	}
+
+	return parseStats(output)
+}
+
+// parseStats converts docker stats JSON output into a ContainerStats.
+func parseStats(output []byte) (*ContainerStats, error) {
+	var raw dockerStatsJSON
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(output))), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse container stats: %w", err)
+	}
+
+	stats := &ContainerStats{}
+	var err error
+
+	if stats.CPUPercent, err = parsePercent(raw.CPUPerc); err != nil {
+		return nil, fmt.Errorf("invalid CPU percentage: %w", err)
+	}
+	if stats.MemoryPercent, err = parsePercent(raw.MemPerc); err != nil {
+		return nil, fmt.Errorf("invalid memory percentage: %w", err)
+	}
+	usage, _, err := parseIOPair(raw.MemUsage)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memory usage: %w", err)
+	}
+	stats.MemoryUsage = usage
+	if stats.NetInput, stats.NetOutput, err = parseIOPair(raw.NetIO); err != nil {
+		return nil, fmt.Errorf("invalid network I/O: %w", err)
+	}
+	if stats.BlockRead, stats.BlockWrite, err = parseIOPair(raw.BlockIO); err != nil {
+		return nil, fmt.Errorf("invalid block I/O: %w", err)
+	}
+	if raw.PIDs != "" && raw.PIDs != "--" {
+		if stats.PIDs, err = strconv.Atoi(raw.PIDs); err != nil {
+			return nil, fmt.Errorf("invalid PID count: %w", err)
+		}
+	}
+
+	return stats, nil
+}
+
+// parsePercent parses a docker percentage string such as "12.34%".
+// Docker reports "--" for stopped containers, which is treated as zero.
+func parsePercent(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "--" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+}
+
+// parseIOPair parses a docker "in / out" pair such as "1.2kB / 3.4MB".
+func parseIOPair(s string) (uint64, uint64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "--" {
+		return 0, 0, nil
+	}
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected 'a / b', got %q", s)
+	}
+	first, err := parseByteSize(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	second, err := parseByteSize(parts[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return first, second, nil
+}
+
+// byteUnits maps docker's size suffixes to their multipliers.
+// Docker uses decimal units for I/O and binary units for memory.
+var byteUnits = []struct {
+	suffix     string
+	multiplier float64
+}{
+	// Longer suffixes first so "KiB" is not matched as "B"
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"TiB", 1 << 40},
+	{"kB", 1e3},
+	{"KB", 1e3},
+	{"MB", 1e6},
+	{"GB", 1e9},
+	{"TB", 1e12},
+	{"B", 1},
+}
+
+// parseByteSize parses a human-readable docker size such as "1.5MiB" or "20kB".
+func parseByteSize(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	for _, unit := range byteUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			value, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, unit.suffix)), 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid size %q: %w", s, err)
+			}
+			return uint64(value * unit.multiplier), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid size %q: unknown unit", s)
+}
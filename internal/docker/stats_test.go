@@ -0,0 +1,99 @@
+package docker
+
+import "testing"
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  uint64
+	}{
+		{"0B", 0},
+		{"512B", 512},
+		{"1.5kB", 1500},
+		{"2MB", 2000000},
+		{"1KiB", 1024},
+		{"3.5MiB", 3670016},
+		{"1GiB", 1 << 30},
+	}
+
+	for _, tt := range tests {
+		got, err := parseByteSize(tt.input)
+		if err != nil {
+			t.Errorf("parseByteSize(%q) error = %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseByteSize(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := parseByteSize("12XB"); err == nil {
+		t.Error("parseByteSize(\"12XB\") expected error, got nil")
+	}
+}
+
+func TestParseStats(t *testing.T) {
+	output := []byte(`{"BlockIO":"4.1kB / 0B","CPUPerc":"12.50%","MemPerc":"0.04%","MemUsage":"3MiB / 7.7GiB","NetIO":"1kB / 2kB","PIDs":"3"}`)
+
+	stats, err := parseStats(output)
+	if err != nil {
+		t.Fatalf("parseStats() error = %v", err)
+	}
+	if stats.CPUPercent != 12.5 {
+		t.Errorf("CPUPercent = %v, want 12.5", stats.CPUPercent)
+	}
+	if stats.MemoryUsage != 3<<20 {
+		t.Errorf("MemoryUsage = %d, want %d", stats.MemoryUsage, 3<<20)
+	}
+	if stats.TotalIO() != 7100 {
+		t.Errorf("TotalIO() = %d, want 7100", stats.TotalIO())
+	}
+	if stats.PIDs != 3 {
+		t.Errorf("PIDs = %d, want 3", stats.PIDs)
+	}
+}
+
+func TestPeaksObserve(t *testing.T) {
+	var p Peaks
+	p.observe(&ContainerStats{CPUPercent: 80, MemoryUsage: 1 << 30, NetInput: 10, PIDs: 4})
+	p.observe(&ContainerStats{CPUPercent: 20, MemoryUsage: 3 << 30, NetInput: 50, PIDs: 2})
+
+	want := Peaks{CPUPercent: 80, MemoryUsage: 3 << 30, IOBytes: 50, PIDs: 4, Samples: 2}
+	if p != want {
+		t.Errorf("peaks = %+v, want %+v", p, want)
+	}
+}
+
+func TestParseTop(t *testing.T) {
+	output := "PID                 USER                %CPU                COMMAND\n" +
+		"4121                root                0.0                 tail -f /dev/null\n" +
+		"4388                node                87.5                node /usr/local/bin/claude --resume\n"
+
+	processes, err := parseTop(output)
+	if err != nil {
+		t.Fatalf("parseTop() error = %v", err)
+	}
+	if len(processes) != 2 {
+		t.Fatalf("parseTop() returned %d processes, want 2", len(processes))
+	}
+	want := Process{PID: 4388, User: "node", CPUPercent: 87.5, Command: "node /usr/local/bin/claude --resume"}
+	if processes[1] != want {
+		t.Errorf("process = %+v, want %+v", processes[1], want)
+	}
+}
+
+func TestParseDF(t *testing.T) {
+	output := "Filesystem     1024-blocks    Used Available Capacity Mounted on\n" +
+		"/run/host_mark/Volumes 2097152 1572864 524288 75% /claude-env\n"
+
+	total, used, free, err := parseDF(output)
+	if err != nil {
+		t.Fatalf("parseDF() error = %v", err)
+	}
+	if total != 2<<30 || used != 1536<<20 || free != 512<<20 {
+		t.Errorf("parseDF() = %d, %d, %d", total, used, free)
+	}
+	if _, _, _, err := parseDF("df: /claude-env: No such file or directory\n"); err == nil {
+		t.Error("parseDF() accepted output without a filesystem line")
+	}
+}
@@ -0,0 +1,81 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// SymlinkMismatchError reports a _docs symlink that does not point at the
+// expected repos/<repoID> directory.
+type SymlinkMismatchError struct {
+	Side   string // "host" or "container"
+	Path   string // Location of the symlink
+	Target string // Current target; empty if the symlink is missing
+	RepoID string
+}
+
+func (e *SymlinkMismatchError) Error() string {
+	if e.Target == "" {
+		return fmt.Sprintf("%s symlink %s is missing (want repos/%s)", e.Side, e.Path, e.RepoID)
+	}
+	return fmt.Sprintf("%s symlink %s points at %s, want repos/%s", e.Side, e.Path, e.Target, e.RepoID)
+}
+
+// VerifySymlinkConsistency checks that the workspace's _docs symlink points at
+// repos/<repoID> both as seen on the host and as seen inside the container,
+// which can diverge after a repo ID change. A host target under
+// volumeMountPoint or /claude-env counts, as setup-workspace-symlink.sh
+// creates links to /claude-env/repos/<repoID>. Each mismatch is returned as a
+// *SymlinkMismatchError, joined if both sides disagree.
+func (m *Manager) VerifySymlinkConsistency(containerName, workspacePath, volumeMountPoint, repoID string) error {
+	containerName, err := ResolveContainerName(containerName)
+	if err != nil {
+		return err
+	}
+	if repoID == "" {
+		return fmt.Errorf("repoID is required")
+	}
+	if !m.containerExists(containerName) {
+		return &ContainerNotFoundError{Name: containerName}
+	}
+
+	want := []string{path.Join(constants.ContainerVolumePath, constants.ReposDirName, repoID)}
+	if volumeMountPoint != "" {
+		want = append(want, filepath.Join(volumeMountPoint, constants.ReposDirName, repoID))
+	}
+	matches := func(target string) bool {
+		for _, w := range want {
+			if path.Clean(target) == w {
+				return true
+			}
+		}
+		return false
+	}
+
+	var errs []error
+
+	hostLink := filepath.Join(workspacePath, m.docsLinkName())
+	hostTarget, err := os.Readlink(hostLink)
+	if err != nil && !os.IsNotExist(err) {
+		errs = append(errs, fmt.Errorf("failed to read host symlink: %w", err))
+	} else if !matches(hostTarget) {
+		errs = append(errs, &SymlinkMismatchError{Side: "host", Path: hostLink, Target: hostTarget, RepoID: repoID})
+	}
+
+	containerLink := path.Join(m.containerWorkspaceTarget(containerName), m.docsLinkName())
+	output, err := m.getCommandOutputWithTimeout(m.timeouts.Quick, "docker", "exec", containerName, "readlink", containerLink)
+	containerTarget := strings.TrimSpace(string(output))
+	if err != nil && !m.IsRunning(containerName) {
+		errs = append(errs, fmt.Errorf("container %s is not running", containerName))
+	} else if !matches(containerTarget) {
+		errs = append(errs, &SymlinkMismatchError{Side: "container", Path: containerLink, Target: containerTarget, RepoID: repoID})
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,81 @@
+package docker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Defaults for retrying setup-workspace-symlink.sh; see SetSymlinkRetry.
+const (
+	defaultSymlinkAttempts   = 3
+	defaultSymlinkRetryDelay = time.Second
+)
+
+// transientSymlinkErrors are setup script failures caused by the /claude-env
+// mount not having settled yet, which are worth retrying. Anything else (such
+// as a usage error for a bad repo ID) fails immediately.
+var transientSymlinkErrors = []string{
+	"Transport endpoint is not connected",
+	"Stale file handle",
+	"Input/output error",
+	"Device or resource busy",
+	"Read-only file system",
+	"No such file or directory",
+}
+
+// SetSymlinkRetry sets how many times SetupWorkspaceSymlink runs the setup
+// script when it fails with a transient mount error, and how long it waits
+// between attempts. The default is 3 attempts one second apart.
+func (m *Manager) SetSymlinkRetry(attempts int, delay time.Duration) error {
+	if attempts < 1 {
+		return fmt.Errorf("symlink setup attempts must be at least 1, got %d", attempts)
+	}
+	if delay < 0 {
+		return fmt.Errorf("symlink retry delay cannot be negative")
+	}
+	m.symlinkAttempts = attempts
+	m.symlinkRetryDelay = delay
+	return nil
+}
+
+// symlinkRetry returns the configured attempts and delay, or the defaults.
+func (m *Manager) symlinkRetry() (int, time.Duration) {
+	if m.symlinkAttempts == 0 {
+		return defaultSymlinkAttempts, defaultSymlinkRetryDelay
+	}
+	return m.symlinkAttempts, m.symlinkRetryDelay
+}
+
+// retrySymlinkScript calls run, which runs the setup script once and returns
+// its output, until it succeeds, fails with a non-transient error, or the
+// configured attempts are used up, waiting the configured delay in between.
+// A failure with no output (a timeout, or docker exec itself failing) is
+// returned as is.
+func (m *Manager) retrySymlinkScript(run func() (string, error)) error {
+	attempts, delay := m.symlinkRetry()
+	for attempt := 1; ; attempt++ {
+		output, err := run()
+		if err == nil {
+			return nil
+		}
+		if output == "" {
+			return err
+		}
+		if attempt >= attempts || !isTransientSymlinkError(output) {
+			return fmt.Errorf("failed to setup workspace symlink (attempt %d of %d): %w\nOutput: %s", attempt, attempts, err, output)
+		}
+		time.Sleep(delay)
+	}
+}
+
+// isTransientSymlinkError reports whether setup script output indicates a
+// mount that was not ready yet.
+func isTransientSymlinkError(output string) bool {
+	for _, pattern := range transientSymlinkErrors {
+		if strings.Contains(output, pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package docker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// scriptResult is one run of the setup script.
+type scriptResult struct {
+	output string
+	err    error
+}
+
+// scriptRuns returns a run func for retrySymlinkScript that replays results
+// in order, and a pointer to how many times it was called.
+func scriptRuns(results ...scriptResult) (func() (string, error), *int) {
+	calls := 0
+	return func() (string, error) {
+		r := results[calls]
+		calls++
+		return r.output, r.err
+	}, &calls
+}
+
+func TestRetrySymlinkScript(t *testing.T) {
+	m := NewManager()
+	if err := m.SetSymlinkRetry(3, 0); err != nil {
+		t.Fatal(err)
+	}
+	exitErr := errors.New("exit status 1")
+	transient := scriptResult{"ln: /claude-env/repos: Transport endpoint is not connected", exitErr}
+
+	// Fails while the mount settles, then succeeds
+	run, calls := scriptRuns(transient, transient, scriptResult{})
+	if err := m.retrySymlinkScript(run); err != nil || *calls != 3 {
+		t.Errorf("fail-then-succeed: error = %v after %d calls, want nil after 3", err, *calls)
+	}
+
+	// Transient failures stop after the configured attempts
+	run, calls = scriptRuns(transient, transient, transient)
+	if err := m.retrySymlinkScript(run); !errors.Is(err, exitErr) || !strings.Contains(err.Error(), "attempt 3 of 3") || *calls != 3 {
+		t.Errorf("exhausted: error = %v after %d calls", err, *calls)
+	}
+
+	// Other failures are not retried
+	run, calls = scriptRuns(scriptResult{"usage: setup-workspace-symlink.sh <repo-id>", exitErr})
+	if err := m.retrySymlinkScript(run); !errors.Is(err, exitErr) || *calls != 1 {
+		t.Errorf("non-transient: error = %v after %d calls, want one call", err, *calls)
+	}
+
+	// A failure with no output is returned as is
+	timeout := errors.New("symlink setup timed out")
+	run, calls = scriptRuns(scriptResult{"", timeout})
+	if err := m.retrySymlinkScript(run); err != timeout || *calls != 1 {
+		t.Errorf("no output: error = %v after %d calls, want the timeout after one call", err, *calls)
+	}
+}
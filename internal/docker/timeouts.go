@@ -0,0 +1,75 @@
+package docker
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// Default timeouts, used for any Timeouts field left zero
+const (
+	defaultStartTimeout     = 30 * time.Second
+	defaultStopGrace        = 10 * time.Second // Docker's own default for docker stop
+	defaultReadinessTimeout = containerReadyMaxRetries * containerReadyRetryDelay
+)
+
+// Timeouts bounds how long a Manager waits for each kind of operation. Zero
+// fields use the defaults; see DefaultTimeouts.
+type Timeouts struct {
+	Command   time.Duration // Most docker commands: inspect, ps, info, rm, setup scripts
+	Quick     time.Duration // Fast probes: readlink and test in the container, helper containers
+	Start     time.Duration // Creating and starting the persistent container
+	StopGrace time.Duration // How long docker stop lets the container exit before killing it
+	Readiness time.Duration // How long symlink setup waits for the container to be ready
+}
+
+// DefaultTimeouts returns the timeouts NewManager uses.
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Command:   defaultCommandTimeout,
+		Quick:     quickCommandTimeout,
+		Start:     defaultStartTimeout,
+		StopGrace: defaultStopGrace,
+		Readiness: defaultReadinessTimeout,
+	}
+}
+
+// withDefaults fills zero or negative fields from DefaultTimeouts.
+func (t Timeouts) withDefaults() Timeouts {
+	d := DefaultTimeouts()
+	if t.Command <= 0 {
+		t.Command = d.Command
+	}
+	if t.Quick <= 0 {
+		t.Quick = d.Quick
+	}
+	if t.Start <= 0 {
+		t.Start = d.Start
+	}
+	if t.StopGrace <= 0 {
+		t.StopGrace = d.StopGrace
+	}
+	if t.Readiness <= 0 {
+		t.Readiness = d.Readiness
+	}
+	return t
+}
+
+// NewManagerWithTimeouts creates a Docker manager with the given timeouts,
+// e.g. shorter ones for CI or longer ones for slow cold starts. Fields left
+// zero keep their defaults.
+func NewManagerWithTimeouts(timeouts Timeouts) *Manager {
+	m := NewManager()
+	m.timeouts = timeouts.withDefaults()
+	return m
+}
+
+// Timeouts returns the timeouts the manager uses.
+func (m *Manager) Timeouts() Timeouts {
+	return m.timeouts
+}
+
+// stopGraceSeconds returns StopGrace as whole seconds for docker stop -t.
+func (t Timeouts) stopGraceSeconds() string {
+	return strconv.Itoa(int(math.Ceil(t.StopGrace.Seconds())))
+}
@@ -0,0 +1,21 @@
+package docker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewManagerWithTimeouts(t *testing.T) {
+	m := NewManagerWithTimeouts(Timeouts{Start: 2 * time.Minute})
+	want := DefaultTimeouts()
+	want.Start = 2 * time.Minute
+	if got := m.Timeouts(); got != want {
+		t.Errorf("Timeouts() = %+v, want %+v", got, want)
+	}
+	if got := NewManager().Timeouts(); got != DefaultTimeouts() {
+		t.Errorf("NewManager().Timeouts() = %+v, want defaults", got)
+	}
+	if got := (Timeouts{StopGrace: 1500 * time.Millisecond}).stopGraceSeconds(); got != "2" {
+		t.Errorf("stopGraceSeconds() = %q, want whole seconds rounded up", got)
+	}
+}
@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// VolumeSpaceInContainer runs `df` against the volume mount inside the
+// container and returns its total, used and free space in bytes. It works
+// where the host cannot easily stat the mount, such as from a status display
+// that wants to warn before the volume fills. Returns *ContainerNotFoundError
+// if the container does not exist.
+func (m *Manager) VolumeSpaceInContainer(containerName string) (total, used, free int64, err error) {
+	containerName, err = ResolveContainerName(containerName)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if !m.containerExists(containerName) {
+		return 0, 0, 0, &ContainerNotFoundError{Name: containerName}
+	}
+	if !m.IsRunning(containerName) {
+		return 0, 0, 0, fmt.Errorf("container %s is not running", containerName)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeouts.Quick)
+	defer cancel()
+
+	// -P keeps each filesystem on one line; -k fixes the unit across df implementations
+	cmd := exec.CommandContext(ctx, "docker", "exec", containerName, "df", "-P", "-k", constants.ContainerVolumePath)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return 0, 0, 0, fmt.Errorf("df timed out after %v", m.timeouts.Quick)
+		}
+		return 0, 0, 0, fmt.Errorf("df failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return parseDF(string(output))
+}
+
+// parseDF parses the last line of `df -P -k` output, whose second to fourth
+// columns are the total, used and available 1024-byte blocks.
+func parseDF(output string) (total, used, free int64, err error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return 0, 0, 0, fmt.Errorf("unexpected df output: %q", output)
+	}
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return 0, 0, 0, fmt.Errorf("unexpected df output: %q", output)
+	}
+
+	var blocks [3]int64
+	for i := range blocks {
+		blocks[i], err = strconv.ParseInt(fields[i+1], 10, 64)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid df value %q: %w", fields[i+1], err)
+		}
+	}
+	return blocks[0] * 1024, blocks[1] * 1024, blocks[2] * 1024, nil
+}
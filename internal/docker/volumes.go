@@ -0,0 +1,34 @@
+package docker
+
+import "fmt"
+
+// VolumeMount is an additional mounted capsule volume, such as a shared team
+// docs volume, bind-mounted alongside the primary volume.
+type VolumeMount struct {
+	MountPoint    string // Host mount point of the volume, e.g. /Volumes/Capsule-team
+	ContainerPath string // Absolute path inside the container
+}
+
+// additionalVolumeMounts returns AdditionalVolumes as writable extra mounts,
+// so they are checked for overlaps together with ExtraMounts and SecretFiles.
+func (c *ContainerConfig) additionalVolumeMounts() []ExtraMount {
+	mounts := make([]ExtraMount, 0, len(c.AdditionalVolumes))
+	for _, v := range c.AdditionalVolumes {
+		mounts = append(mounts, ExtraMount{HostPath: v.MountPoint, ContainerPath: v.ContainerPath})
+	}
+	return mounts
+}
+
+// validateAdditionalVolumes checks that each additional volume has absolute paths.
+// Overlaps are checked by validateExtraMounts.
+func validateAdditionalVolumes(volumes []VolumeMount) error {
+	for i, v := range volumes {
+		if err := validatePath(v.MountPoint, fmt.Sprintf("additional volume %d mount point", i)); err != nil {
+			return err
+		}
+		if err := validatePath(v.ContainerPath, fmt.Sprintf("additional volume %d container path", i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
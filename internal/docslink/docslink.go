@@ -0,0 +1,63 @@
+// Package docslink names the workspace symlink into a volume's repos/<id>
+// directory. The name is passed explicitly to each component that creates or
+// checks the link; an empty name means constants.DocsSymlinkName.
+package docslink
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// Name returns name, or constants.DocsSymlinkName if name is empty.
+func Name(name string) string {
+	if name == "" {
+		return constants.DocsSymlinkName
+	}
+	return name
+}
+
+// Validate checks that name is a single, ordinary path element, e.g.
+// ".capsule-docs" for projects that already have a _docs directory. Empty is
+// allowed and selects the default.
+func Validate(name string) error {
+	switch {
+	case name == "":
+		return nil
+	case name == "." || name == "..":
+		return fmt.Errorf("invalid docs link name %q", name)
+	case strings.ContainsAny(name, `/\`):
+		return fmt.Errorf("invalid docs link name %q: must not contain path separators", name)
+	case strings.HasPrefix(name, "-"):
+		return fmt.Errorf("invalid docs link name %q: must not start with '-'", name)
+	case strings.ContainsFunc(name, func(r rune) bool { return r < 0x20 || r == 0x7f }):
+		return fmt.Errorf("invalid docs link name %q: must not contain control characters", name)
+	}
+	return nil
+}
+
+// IsManagedTarget reports whether a docs symlink target points at a repo
+// directory under repos/ in the volume, i.e. whether capsule created the link.
+// Both /claude-env/repos/<id> (as created in the container) and
+// <volumeMountPoint>/repos/<id> count. A relative target is resolved against
+// workspacePath.
+func IsManagedTarget(target, workspacePath, volumeMountPoint string) bool {
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(workspacePath, target)
+	}
+	target = filepath.Clean(target)
+
+	roots := []string{path.Join(constants.ContainerVolumePath, constants.ReposDirName)}
+	if volumeMountPoint != "" {
+		roots = append(roots, filepath.Join(filepath.Clean(volumeMountPoint), constants.ReposDirName))
+	}
+	for _, root := range roots {
+		if strings.HasPrefix(target, root+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -6,6 +6,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/jeanhaley32/claude-capsule/internal/constants"
 )
@@ -13,9 +16,47 @@ import (
 //go:embed Dockerfile
 var Dockerfile []byte
 
+// validSecretIDPattern restricts BuildKit secret IDs to safe characters. The
+// leading alphanumeric rules out "." and "..", which would escape the
+// secrets directory the IDs are used as file names in.
+var validSecretIDPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
 // BuildImage builds the Docker image from the embedded Dockerfile.
+// Secrets are passed to BuildKit as `--secret id=<name>,src=<file>` so they are
+// available during the build without being stored in image layers. The Dockerfile
+// must consume them with `RUN --mount=type=secret,id=<name>`. Build args are
+// passed as `--build-arg KEY=VALUE` to set Dockerfile ARGs. Both maps may be nil.
+// The image is built for platform (see docker.ResolvePlatform), or for the
+// engine's default when it is empty.
 // Returns nil if successful, error otherwise.
-func BuildImage(imageName string) error {
+func BuildImage(imageName, platform string, secrets, buildArgs map[string]string) error {
+	return buildImage(imageName, platform, secrets, buildArgs, nil)
+}
+
+// BuildImageWithProgress is like BuildImage but reports progress as structured
+// events instead of writing docker's output to the terminal. It runs BuildKit with
+// --progress=rawjson; lines that cannot be decoded are forwarded as raw events.
+// The events channel is closed when the build finishes; the caller must drain
+// it. A nil channel reports nothing, and the build runs like BuildImage.
+func BuildImageWithProgress(imageName, platform string, secrets, buildArgs map[string]string, events chan<- ProgressEvent) error {
+	if events != nil {
+		defer close(events)
+	}
+	return buildImage(imageName, platform, secrets, buildArgs, events)
+}
+
+// buildImage builds the embedded Dockerfile, streaming progress to events if non-nil.
+func buildImage(imageName, platform string, secrets, buildArgs map[string]string, events chan<- ProgressEvent) error {
+	// Validate build args before doing any work
+	buildArgKeys := make([]string, 0, len(buildArgs))
+	for key := range buildArgs {
+		if err := validateBuildArgKey(key); err != nil {
+			return err
+		}
+		buildArgKeys = append(buildArgKeys, key)
+	}
+	sort.Strings(buildArgKeys)
+
 	// Create temp directory for build context
 	tempDir, err := os.MkdirTemp("", "capsule-build-*")
 	if err != nil {
@@ -29,11 +70,55 @@ func BuildImage(imageName string) error {
 		return fmt.Errorf("failed to write Dockerfile: %w", err)
 	}
 
+	args := []string{"build", "-t", imageName, "--label", LabelDockerfileHash + "=" + DockerfileHash()}
+	if platform != "" {
+		args = append(args, "--platform", platform)
+	}
+	for _, key := range buildArgKeys {
+		args = append(args, "--build-arg", key+"="+buildArgs[key])
+	}
+
+	// Write secrets outside the build context so they can never be COPY'd into a layer
+	if len(secrets) > 0 {
+		secretsDir, err := os.MkdirTemp("", "capsule-secrets-*")
+		if err != nil {
+			return fmt.Errorf("failed to create secrets directory: %w", err)
+		}
+		defer os.RemoveAll(secretsDir)
+
+		for id, value := range secrets {
+			if !validSecretIDPattern.MatchString(id) {
+				return fmt.Errorf("invalid secret id %q: must start with a letter or digit and contain only [a-zA-Z0-9_.-]", id)
+			}
+			secretPath := filepath.Join(secretsDir, id)
+			if err := os.WriteFile(secretPath, []byte(value), constants.FilePermissions); err != nil {
+				return fmt.Errorf("failed to write secret %s: %w", id, err)
+			}
+			args = append(args, "--secret", fmt.Sprintf("id=%s,src=%s", id, secretPath))
+		}
+	}
+
+	if events != nil {
+		args = append(args, "--progress=rawjson")
+	}
+	args = append(args, tempDir)
+
 	// Build the image
-	cmd := exec.Command("docker", "build", "-t", imageName, tempDir)
+	cmd := exec.Command("docker", args...)
+	if len(secrets) > 0 || events != nil || platform != "" {
+		// Secrets, structured progress, and --platform require BuildKit
+		cmd.Env = append(os.Environ(), "DOCKER_BUILDKIT=1")
+	}
+
+	if events != nil {
+		if err := runWithProgress(cmd, events, parseBuildProgressLine); err != nil {
+			return fmt.Errorf("failed to build Docker image: %w", err)
+		}
+		return nil
+	}
+
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to build Docker image: %w", err)
 	}
@@ -41,8 +126,29 @@ func BuildImage(imageName string) error {
 	return nil
 }
 
-// ImageExists checks if a Docker image exists locally.
+// validateBuildArgKey checks that a build arg name can be passed as KEY=VALUE.
+func validateBuildArgKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("build arg name cannot be empty")
+	}
+	if strings.ContainsAny(key, "= \t\r\n") {
+		return fmt.Errorf("invalid build arg name %q: must not contain '=' or whitespace", key)
+	}
+	return nil
+}
+
+// ImageExists checks if a Docker image exists locally. A digest-pinned
+// reference (name@sha256:...) matches an image pulled by that digest, or a
+// local image whose ID is the digest, such as one loaded with docker load.
 func ImageExists(imageName string) bool {
 	cmd := exec.Command("docker", "image", "inspect", imageName)
-	return cmd.Run() == nil
+	if cmd.Run() == nil {
+		return true
+	}
+	_, digest, pinned := strings.Cut(imageName, "@")
+	if !pinned {
+		return false
+	}
+	output, err := exec.Command("docker", "image", "inspect", "--format", "{{.Id}}", digest).Output()
+	return err == nil && strings.TrimSpace(string(output)) == digest
 }
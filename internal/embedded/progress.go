@@ -0,0 +1,149 @@
+package embedded
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// ProgressEvent is a single progress update from a docker build or pull.
+type ProgressEvent struct {
+	ID      string // Build step digest or image layer ID
+	Status  string // Human-readable status (e.g. step name, "Downloading", "Pull complete")
+	Current int64  // Bytes completed, if known
+	Total   int64  // Total bytes, if known
+	Done    bool   // The step or layer has finished
+	Error   string // Error reported for this step, if any
+
+	// Raw holds the original output line when it could not be decoded.
+	// All other fields are empty for raw events.
+	Raw string
+}
+
+// buildkitStatus mirrors the subset of BuildKit's rawjson SolveStatus capsule decodes.
+type buildkitStatus struct {
+	Vertexes []struct {
+		Digest    string  `json:"digest"`
+		Name      string  `json:"name"`
+		Completed *string `json:"completed"`
+		Error     string  `json:"error"`
+	} `json:"vertexes"`
+	Statuses []struct {
+		ID        string  `json:"id"`
+		Vertex    string  `json:"vertex"`
+		Name      string  `json:"name"`
+		Current   int64   `json:"current"`
+		Total     int64   `json:"total"`
+		Completed *string `json:"completed"`
+	} `json:"statuses"`
+}
+
+// parseBuildProgressLine decodes a BuildKit rawjson line into events.
+func parseBuildProgressLine(line string) []ProgressEvent {
+	var status buildkitStatus
+	if err := json.Unmarshal([]byte(line), &status); err != nil {
+		return []ProgressEvent{{Raw: line}}
+	}
+
+	var events []ProgressEvent
+	for _, v := range status.Vertexes {
+		events = append(events, ProgressEvent{
+			ID:     v.Digest,
+			Status: v.Name,
+			Done:   v.Completed != nil,
+			Error:  v.Error,
+		})
+	}
+	for _, st := range status.Statuses {
+		events = append(events, ProgressEvent{
+			ID:      st.ID,
+			Status:  st.Name,
+			Current: st.Current,
+			Total:   st.Total,
+			Done:    st.Completed != nil,
+		})
+	}
+	return events
+}
+
+// parsePullProgressLine decodes a `docker pull` text line of the form "<layer>: <status>".
+func parsePullProgressLine(line string) []ProgressEvent {
+	id, status, ok := strings.Cut(line, ": ")
+	if !ok || strings.ContainsAny(id, " \t") {
+		return []ProgressEvent{{Raw: line}}
+	}
+	return []ProgressEvent{{
+		ID:     id,
+		Status: status,
+		Done:   status == "Pull complete" || status == "Already exists",
+	}}
+}
+
+// runWithProgress runs cmd, decoding each line of its combined output with parse
+// and sending the resulting events. It returns once the command exits.
+func runWithProgress(cmd *exec.Cmd, events chan<- ProgressEvent, parse func(string) []ProgressEvent) error {
+	reader, writer := io.Pipe()
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	waitErr := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		writer.Close()
+		waitErr <- err
+	}()
+
+	scanner := bufio.NewScanner(reader)
+	// BuildKit status lines can be large
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if events == nil {
+			continue
+		}
+		for _, event := range parse(line) {
+			events <- event
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		// Drain so the command isn't blocked writing to a full pipe
+		_, _ = io.Copy(io.Discard, reader)
+		if runErr := <-waitErr; runErr != nil {
+			return runErr
+		}
+		return fmt.Errorf("failed to read progress output: %w", err)
+	}
+
+	return <-waitErr
+}
+
+// PullImageWithProgress pulls an image and reports per-layer progress as events.
+// The docker CLI only emits text for pulls, so events carry layer IDs and status
+// but not byte counts. The events channel is closed when the pull finishes;
+// the caller must drain it. A nil channel reports nothing. The image is pulled
+// for platform (see docker.ResolvePlatform), or the engine's default when empty.
+func PullImageWithProgress(imageName, platform string, events chan<- ProgressEvent) error {
+	if events != nil {
+		defer close(events)
+	}
+
+	args := []string{"pull"}
+	if platform != "" {
+		args = append(args, "--platform", platform)
+	}
+	cmd := exec.Command("docker", append(args, imageName)...)
+	if err := runWithProgress(cmd, events, parsePullProgressLine); err != nil {
+		return fmt.Errorf("failed to pull image %s: %w", imageName, err)
+	}
+	return nil
+}
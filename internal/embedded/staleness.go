@@ -0,0 +1,40 @@
+package embedded
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// LabelDockerfileHash records the hash of the embedded Dockerfile an image was built from.
+const LabelDockerfileHash = "capsule.dockerfile-hash"
+
+// DockerfileHash returns the SHA-256 of the embedded Dockerfile. It changes whenever
+// the image definition changes.
+func DockerfileHash() string {
+	sum := sha256.Sum256(Dockerfile)
+	return hex.EncodeToString(sum[:])
+}
+
+// ImageOutdated reports whether a local image was built from a different version
+// of the embedded Dockerfile than the one in this binary. Images built before the
+// hash label was recorded are reported as outdated. Returns an error if the image
+// does not exist.
+func ImageOutdated(imageName string) (bool, error) {
+	format := fmt.Sprintf(`{{ index .Config.Labels %q }}`, LabelDockerfileHash)
+	output, err := exec.Command("docker", "image", "inspect", "--format", format, imageName).Output()
+	if err != nil {
+		if !ImageExists(imageName) {
+			return false, fmt.Errorf("docker image '%s' not found", imageName)
+		}
+		return false, fmt.Errorf("failed to inspect image %s: %w", imageName, err)
+	}
+
+	builtFrom := strings.TrimSpace(string(output))
+	if builtFrom == "" || builtFrom == "<no value>" {
+		return true, nil
+	}
+	return builtFrom != DockerfileHash(), nil
+}
@@ -0,0 +1,66 @@
+// Package filelock provides exclusive cross-process locks backed by flock(2),
+// shared by the container and volume operation locks.
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// retryWait is how long Acquire sleeps between attempts.
+const retryWait = 100 * time.Millisecond
+
+// ErrTimeout is returned, wrapped, when a lock is still held by another
+// process after the timeout.
+var ErrTimeout = errors.New("timed out waiting for lock")
+
+// Lock is a held exclusive lock on a file.
+type Lock struct {
+	file *os.File
+}
+
+// Acquire takes an exclusive flock on path, creating the file and its
+// directory if needed. It retries until timeout and then returns an error
+// wrapping ErrTimeout. The lock is released by Release or when the process exits.
+func Acquire(path string, timeout time.Duration) (*Lock, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, constants.DirPermissions); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory %s: %w", dir, err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, constants.FilePermissions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file %s: %w", path, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			return &Lock{file: file}, nil
+		}
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("%w: %s (waited %v)", ErrTimeout, path, timeout)
+		}
+		time.Sleep(retryWait)
+	}
+}
+
+// Release unlocks and closes the lock file. It is safe on a nil Lock.
+func (l *Lock) Release() {
+	if l == nil || l.file == nil {
+		return
+	}
+	_ = syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	l.file.Close()
+}
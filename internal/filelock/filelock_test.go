@@ -0,0 +1,29 @@
+package filelock
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locks", "test.lock")
+
+	lock, err := Acquire(path, time.Second)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	// flock locks belong to the open file, so a second open conflicts even in-process
+	if _, err := Acquire(path, 150*time.Millisecond); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Acquire() while held error = %v, want ErrTimeout", err)
+	}
+
+	lock.Release()
+	again, err := Acquire(path, time.Second)
+	if err != nil {
+		t.Fatalf("Acquire() after Release error = %v", err)
+	}
+	again.Release()
+	(*Lock)(nil).Release()
+}
@@ -0,0 +1,59 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Cleanup is a stack of cleanup steps run in reverse order of registration.
+// Register each step right after the resource it undoes is set up (e.g.
+// "unmount volume" after mounting), then call Run on every way out. Run is
+// safe to call from a signal handler and a normal return alike: the steps
+// run only once.
+type Cleanup struct {
+	mu    sync.Mutex
+	steps []cleanupStep
+	ran   bool
+}
+
+type cleanupStep struct {
+	name string
+	fn   func() error
+}
+
+// Push registers a cleanup step. name describes it in errors.
+func (c *Cleanup) Push(name string, fn func() error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.steps = append(c.steps, cleanupStep{name: name, fn: fn})
+}
+
+// Discard drops every registered step without running it, for when setup
+// succeeded and the resources should outlive the caller.
+func (c *Cleanup) Discard() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.steps = nil
+}
+
+// Run runs the registered steps last-in first-out. Every step runs even if an
+// earlier one fails; the returned error joins all failures. Calls after the
+// first do nothing.
+func (c *Cleanup) Run() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ran {
+		return nil
+	}
+	c.ran = true
+
+	var errs []error
+	for i := len(c.steps) - 1; i >= 0; i-- {
+		if err := c.steps[i].fn(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.steps[i].name, err))
+		}
+	}
+	c.steps = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,34 @@
+package lifecycle
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCleanup(t *testing.T) {
+	var order []string
+	var c Cleanup
+	c.Push("unmount volume", func() error { order = append(order, "unmount"); return nil })
+	c.Push("remove container", func() error { order = append(order, "remove"); return errors.New("busy") })
+	c.Push("stop container", func() error { order = append(order, "stop"); return nil })
+
+	err := c.Run()
+	if got := strings.Join(order, ","); got != "stop,remove,unmount" {
+		t.Errorf("cleanup order = %s, want LIFO", got)
+	}
+	if err == nil || !strings.Contains(err.Error(), "remove container: busy") {
+		t.Errorf("Run() error = %v, want the failing step", err)
+	}
+
+	if err := c.Run(); err != nil || len(order) != 3 {
+		t.Errorf("second Run() = %v, ran %d steps, want no-op", err, len(order))
+	}
+
+	var discarded Cleanup
+	discarded.Push("never", func() error { t.Error("discarded step ran"); return nil })
+	discarded.Discard()
+	if err := discarded.Run(); err != nil {
+		t.Errorf("Run() after Discard = %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package lifecycle
+
+import (
+	"github.com/jeanhaley32/claude-capsule/internal/docker"
+	"github.com/jeanhaley32/claude-capsule/internal/terminal"
+	"github.com/jeanhaley32/claude-capsule/internal/volume"
+)
+
+// fakeDocker records the DockerManager calls lifecycle makes. Methods it does
+// not override panic through the nil embedded interface.
+type fakeDocker struct {
+	docker.DockerManager
+	calls []string
+
+	dirty      bool
+	dirtyPaths []string
+
+	privilegedErr error
+}
+
+func (f *fakeDocker) Stop(containerName string) error {
+	f.calls = append(f.calls, "stop "+containerName)
+	return nil
+}
+
+func (f *fakeDocker) Start(config docker.ContainerConfig) error {
+	f.calls = append(f.calls, "start "+config.ContainerName)
+	return nil
+}
+
+func (f *fakeDocker) SetupWorkspaceSymlink(containerName, repoID string) error {
+	f.calls = append(f.calls, "symlink "+repoID)
+	return nil
+}
+
+func (f *fakeDocker) RunPostStart(config docker.ContainerConfig) error {
+	f.calls = append(f.calls, "post-start "+config.ContainerName)
+	return nil
+}
+
+func (f *fakeDocker) VerifyMounts(containerName string, config docker.ContainerConfig) error {
+	f.calls = append(f.calls, "verify "+containerName)
+	return nil
+}
+
+func (f *fakeDocker) CheckPrivilegedRun() error {
+	return f.privilegedErr
+}
+
+func (f *fakeDocker) WorkspaceDirty(containerName string) (bool, []string, error) {
+	return f.dirty, f.dirtyPaths, nil
+}
+
+// fakeVolume records the VolumeManager calls lifecycle makes, with the volume
+// mounted at mountPoint until Unmount is called.
+type fakeVolume struct {
+	volume.VolumeManager
+	calls []string
+
+	mountPoint    string
+	snapshotErr   error
+	diskImagesErr error
+}
+
+func (f *fakeVolume) CheckDiskImageAccess() error {
+	return f.diskImagesErr
+}
+
+func (f *fakeVolume) GetMountPoint(volumePath string) string {
+	return f.mountPoint
+}
+
+// MountForRepo mounts at mountPoint, which must already exist for
+// volume.WaitMounted.
+func (f *fakeVolume) MountForRepo(volumePath, repoID string, password *terminal.SecurePassword) (string, error) {
+	f.calls = append(f.calls, "mount "+repoID)
+	return f.mountPoint, nil
+}
+
+func (f *fakeVolume) Unmount(mountPoint string) error {
+	f.calls = append(f.calls, "unmount "+mountPoint)
+	f.mountPoint = ""
+	return nil
+}
+
+func (f *fakeVolume) SnapshotVolume(mountPoint, name string) error {
+	f.calls = append(f.calls, "snapshot "+name)
+	return f.snapshotErr
+}
+
+func (f *fakeVolume) CompactVolume(volumePath string, password *terminal.SecurePassword) (int64, error) {
+	f.calls = append(f.calls, "compact")
+	return 0, nil
+}
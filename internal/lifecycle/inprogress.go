@@ -0,0 +1,76 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// OperationInProgress reports whether another capsule process holds the volume
+// lock or a container lock, and if so describes the operation, e.g.
+// "volume operation" or "operation on container claude-abc". It never waits:
+// each lock file is probed with a non-blocking shared lock that is released at
+// once, which cannot take a lock away from its holder. A missing lock
+// directory means nothing is in progress.
+func OperationInProgress() (bool, string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	dir := filepath.Join(homeDir, constants.CapsuleConfigDir, constants.LocksSubdir)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, "", nil
+		}
+		return false, "", fmt.Errorf("failed to read lock directory %s: %w", dir, err)
+	}
+
+	// Check the volume lock first; it blocks every session on the machine
+	if held, err := lockHeld(filepath.Join(dir, constants.VolumeLockFileName)); err != nil || held {
+		return held, "volume operation", err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		container, ok := strings.CutPrefix(name, constants.ContainerLockPrefix)
+		if entry.IsDir() || !ok || !strings.HasSuffix(container, ".lock") {
+			continue
+		}
+		held, err := lockHeld(filepath.Join(dir, name))
+		if err != nil {
+			return false, "", err
+		}
+		if held {
+			return true, "operation on container " + strings.TrimSuffix(container, ".lock"), nil
+		}
+	}
+	return false, "", nil
+}
+
+// lockHeld reports whether another open file holds an exclusive flock on path.
+func lockHeld(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil // Removed since the directory was read
+		}
+		return false, fmt.Errorf("failed to open lock file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_SH|syscall.LOCK_NB)
+	if err == nil {
+		_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		return false, nil
+	}
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return true, nil
+	}
+	return false, fmt.Errorf("failed to probe lock %s: %w", path, err)
+}
@@ -0,0 +1,44 @@
+package lifecycle
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+func TestOperationInProgress(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if busy, _, err := OperationInProgress(); err != nil || busy {
+		t.Fatalf("OperationInProgress() = %v, %v, want idle without a lock directory", busy, err)
+	}
+
+	dir := filepath.Join(home, constants.CapsuleConfigDir, constants.LocksSubdir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	lock, err := os.Create(filepath.Join(dir, constants.ContainerLockPrefix+"claude-abc.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Close()
+	if busy, _, err := OperationInProgress(); err != nil || busy {
+		t.Errorf("OperationInProgress() = %v, %v, want idle with an unheld lock file", busy, err)
+	}
+
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		t.Fatal(err)
+	}
+	busy, op, err := OperationInProgress()
+	if err != nil || !busy || op != "operation on container claude-abc" {
+		t.Errorf("OperationInProgress() = %v, %q, %v", busy, op, err)
+	}
+	// The holder must keep its lock
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		t.Errorf("lock lost after probe: %v", err)
+	}
+}
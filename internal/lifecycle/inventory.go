@@ -0,0 +1,105 @@
+package lifecycle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jeanhaley32/claude-capsule/internal/docker"
+	"github.com/jeanhaley32/claude-capsule/internal/symlink"
+	"github.com/jeanhaley32/claude-capsule/internal/volume"
+)
+
+// InventoryOptions configures Inventory.
+type InventoryOptions struct {
+	// VolumeDir overrides the directory searched for volume images.
+	VolumeDir string
+
+	// WorkspaceRoots are searched for _docs symlinks, in the root itself and
+	// in each of its immediate subdirectories (e.g. ~/src).
+	WorkspaceRoots []string
+
+	// Managers default to the standard implementations when nil.
+	Docker  docker.DockerManager
+	Symlink symlink.SymlinkManager
+}
+
+// InventoryReport lists every host artifact capsule has created.
+type InventoryReport struct {
+	Containers      []string `json:"containers"`
+	DockerAvailable bool     `json:"docker_available"`
+	MountPoints     []string `json:"mount_points"`
+	Volumes         []string `json:"volumes"`
+	Symlinks        []string `json:"symlinks"`
+	Errors          []string `json:"errors,omitempty"`
+}
+
+// Inventory gathers capsule-labelled containers, capsule mount points, volume
+// images, and _docs symlinks under the workspace roots. It only reads state.
+// Problems such as Docker not running are recorded in the report's Errors and
+// the rest of the inventory is still collected from the filesystem.
+func Inventory(opts InventoryOptions) (*InventoryReport, error) {
+	report := &InventoryReport{}
+
+	if opts.Docker == nil {
+		opts.Docker = docker.NewManager()
+	}
+	if opts.Symlink == nil {
+		opts.Symlink = symlink.NewManager()
+	}
+
+	containers, err := opts.Docker.ListCapsuleContainers()
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	} else {
+		report.DockerAvailable = true
+		report.Containers = containers
+	}
+
+	report.MountPoints = volume.ListMountPoints()
+
+	resolver, err := volume.NewPathResolver()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create path resolver: %w", err)
+	}
+	if err := resolver.SetVolumeDir(opts.VolumeDir); err != nil {
+		return nil, err
+	}
+	volumes, err := resolver.ListVolumes()
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	}
+	for _, v := range volumes {
+		report.Volumes = append(report.Volumes, v.Path)
+	}
+
+	for _, root := range opts.WorkspaceRoots {
+		report.Symlinks = append(report.Symlinks, findDocsSymlinks(opts.Symlink, root, report)...)
+	}
+
+	return report, nil
+}
+
+// findDocsSymlinks returns _docs symlinks in root and its immediate subdirectories.
+func findDocsSymlinks(links symlink.SymlinkManager, root string, report *InventoryReport) []string {
+	candidates := []string{root}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("failed to read workspace root %s: %v", root, err))
+		return nil
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			candidates = append(candidates, filepath.Join(root, entry.Name()))
+		}
+	}
+
+	var found []string
+	for _, workspace := range candidates {
+		linkPath := links.Path(workspace)
+		if info, err := os.Lstat(linkPath); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			found = append(found, linkPath)
+		}
+	}
+	return found
+}
@@ -0,0 +1,53 @@
+package lifecycle
+
+import (
+	"fmt"
+
+	"github.com/jeanhaley32/claude-capsule/internal/docker"
+	"github.com/jeanhaley32/claude-capsule/internal/volume"
+)
+
+// PrivilegeReport says which privileged operations capsule can perform as the
+// current user. A nil error means the capability is available.
+type PrivilegeReport struct {
+	// DiskImages is why this account cannot create, attach, or detach disk
+	// images with hdiutil, which creating and mounting volumes needs.
+	DiskImages error
+
+	// PrivilegedContainers is why Docker refuses `docker run --privileged`,
+	// which ClearVMCache needs.
+	PrivilegedContainers error
+}
+
+// Problems returns a message for each missing capability, worded for users on
+// locked-down machines.
+func (r *PrivilegeReport) Problems() []string {
+	var problems []string
+	if r.DiskImages != nil {
+		problems = append(problems, fmt.Sprintf("your account can't mount disk images, so capsule can't create or open its encrypted volume: %v", r.DiskImages))
+	}
+	if r.PrivilegedContainers != nil {
+		problems = append(problems, fmt.Sprintf("Docker doesn't allow privileged containers, so the VM cache can't be cleared after remounts: %v", r.PrivilegedContainers))
+	}
+	return problems
+}
+
+// CheckPrivileges probes whether the current user can run hdiutil attach and
+// detach and whether Docker allows --privileged containers. The probes are
+// non-destructive: a throwaway 1 MB image and a container running `true`.
+// Missing capabilities are recorded in the report; the error is only set when
+// the checks cannot run at all, e.g. on an unsupported operating system.
+func CheckPrivileges() (*PrivilegeReport, error) {
+	vm, err := volume.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create volume manager: %w", err)
+	}
+	return checkPrivileges(vm, docker.NewManager()), nil
+}
+
+func checkPrivileges(vm volume.VolumeManager, dm docker.DockerManager) *PrivilegeReport {
+	return &PrivilegeReport{
+		DiskImages:           vm.CheckDiskImageAccess(),
+		PrivilegedContainers: dm.CheckPrivilegedRun(),
+	}
+}
@@ -0,0 +1,28 @@
+package lifecycle
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckPrivileges(t *testing.T) {
+	report := checkPrivileges(&fakeVolume{}, &fakeDocker{})
+	if report.DiskImages != nil || report.PrivilegedContainers != nil {
+		t.Errorf("checkPrivileges() = %+v, want no problems", report)
+	}
+	if problems := report.Problems(); len(problems) != 0 {
+		t.Errorf("Problems() = %q, want none", problems)
+	}
+
+	diskErr := errors.New("hdiutil: attach failed - not permitted")
+	privErr := errors.New("privileged mode is disabled")
+	report = checkPrivileges(&fakeVolume{diskImagesErr: diskErr}, &fakeDocker{privilegedErr: privErr})
+	if !errors.Is(report.DiskImages, diskErr) || !errors.Is(report.PrivilegedContainers, privErr) {
+		t.Errorf("checkPrivileges() = %+v, want both errors", report)
+	}
+	problems := report.Problems()
+	if len(problems) != 2 || !strings.Contains(problems[0], "disk images") || !strings.Contains(problems[1], "privileged containers") {
+		t.Errorf("Problems() = %q", problems)
+	}
+}
@@ -0,0 +1,125 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jeanhaley32/claude-capsule/internal/docker"
+	"github.com/jeanhaley32/claude-capsule/internal/symlink"
+	"github.com/jeanhaley32/claude-capsule/internal/volume"
+)
+
+// PurgeOptions configures PurgeAll.
+type PurgeOptions struct {
+	// DeleteVolumes deletes each file in VolumePaths after unmounting.
+	DeleteVolumes bool
+	VolumePaths   []string
+
+	// RemoveSymlinks removes the _docs symlink from each of WorkspacePaths.
+	RemoveSymlinks bool
+	WorkspacePaths []string
+
+	// Confirm must be set for DeleteVolumes or RemoveSymlinks to take effect.
+	// Without it PurgeAll refuses to run.
+	Confirm bool
+
+	// Managers default to the standard implementations when nil.
+	Docker  docker.DockerManager
+	Volume  volume.VolumeManager
+	Symlink symlink.SymlinkManager
+}
+
+// PurgeReport lists everything PurgeAll touched.
+type PurgeReport struct {
+	ContainersRemoved []string
+	VolumesUnmounted  []string
+	VolumesDeleted    []string
+	SymlinksRemoved   []string
+	Errors            []error
+}
+
+// PurgeAll resets all capsule state: it removes every capsule-managed container,
+// unmounts every capsule volume, and optionally deletes volume files and _docs
+// symlinks. It continues past individual failures; the returned error joins all
+// of them and the report records what succeeded.
+func PurgeAll(opts PurgeOptions) (PurgeReport, error) {
+	var report PurgeReport
+
+	if (opts.DeleteVolumes || opts.RemoveSymlinks) && !opts.Confirm {
+		return report, fmt.Errorf("refusing to delete volumes or symlinks without confirmation")
+	}
+
+	if opts.Docker == nil {
+		opts.Docker = docker.NewManager()
+	}
+	if opts.Volume == nil {
+		vm, err := volume.New()
+		if err != nil {
+			return report, fmt.Errorf("failed to create volume manager: %w", err)
+		}
+		opts.Volume = vm
+	}
+	if opts.Symlink == nil {
+		opts.Symlink = symlink.NewManager()
+	}
+
+	// Containers first so they release their bind mounts
+	containers, err := opts.Docker.ListCapsuleContainers()
+	if err != nil {
+		report.Errors = append(report.Errors, err)
+	}
+	for _, name := range containers {
+		if err := opts.Docker.Stop(name); err != nil {
+			report.Errors = append(report.Errors, fmt.Errorf("remove container %s: %w", name, err))
+			continue
+		}
+		report.ContainersRemoved = append(report.ContainersRemoved, name)
+	}
+
+	for _, mountPoint := range volume.ListMountPoints() {
+		if err := opts.Volume.Unmount(mountPoint); err != nil {
+			report.Errors = append(report.Errors, fmt.Errorf("unmount %s: %w", mountPoint, err))
+			continue
+		}
+		report.VolumesUnmounted = append(report.VolumesUnmounted, mountPoint)
+	}
+
+	if opts.DeleteVolumes {
+		for _, volumePath := range opts.VolumePaths {
+			if !opts.Volume.Exists(volumePath) {
+				continue
+			}
+			// Never delete a volume that is still mounted
+			if mountPoint := opts.Volume.GetMountPoint(volumePath); mountPoint != "" {
+				report.Errors = append(report.Errors, fmt.Errorf("delete %s: still mounted at %s", volumePath, mountPoint))
+				continue
+			}
+			// Sparse bundles are directories, so remove recursively
+			if err := os.RemoveAll(volumePath); err != nil {
+				report.Errors = append(report.Errors, fmt.Errorf("delete %s: %w", volumePath, err))
+				continue
+			}
+			report.VolumesDeleted = append(report.VolumesDeleted, volumePath)
+		}
+	}
+
+	if opts.RemoveSymlinks {
+		for _, workspacePath := range opts.WorkspacePaths {
+			linkPath := opts.Symlink.Path(workspacePath)
+			if !opts.Symlink.Exists(workspacePath) {
+				continue
+			}
+			if err := opts.Symlink.Remove(workspacePath); err != nil {
+				report.Errors = append(report.Errors, fmt.Errorf("remove symlink in %s: %w", workspacePath, err))
+				continue
+			}
+			report.SymlinksRemoved = append(report.SymlinksRemoved, linkPath)
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		return report, fmt.Errorf("purge incomplete: %w", errors.Join(report.Errors...))
+	}
+	return report, nil
+}
@@ -0,0 +1,175 @@
+package lifecycle
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/jeanhaley32/claude-capsule/internal/docker"
+	"github.com/jeanhaley32/claude-capsule/internal/state"
+	"github.com/jeanhaley32/claude-capsule/internal/terminal"
+	"github.com/jeanhaley32/claude-capsule/internal/volume"
+)
+
+// DesiredState is the environment Reconcile converges to. Each level implies
+// the ones before it: a running container needs the volume mounted, and the
+// _docs symlink is created from inside the container.
+type DesiredState struct {
+	VolumeMounted    bool
+	ContainerRunning bool
+	SymlinkValid     bool
+}
+
+// Action is a step Reconcile took.
+type Action string
+
+const (
+	ActionMountVolume    Action = "mount-volume"
+	ActionStartContainer Action = "start-container"
+	ActionSetupSymlink   Action = "setup-symlink"
+)
+
+// ReconcileOptions configures Reconcile.
+type ReconcileOptions struct {
+	Desired DesiredState
+
+	// Config describes the container; its VolumeMountPoint is filled in from
+	// the detected or new mount.
+	Config     docker.ContainerConfig
+	RepoID     string
+	VolumePath string
+
+	// Password is only needed when the volume has to be mounted.
+	Password *terminal.SecurePassword
+
+	// Managers default to the standard implementations when nil.
+	Docker docker.DockerManager
+	Volume volume.VolumeManager
+}
+
+// Reconcile compares the detected environment with opts.Desired and performs
+// the minimal mount, start, and symlink actions to converge, returning the
+// actions taken in order. It never tears anything down, so it is safe to re-run;
+// a second run with nothing left to do returns no actions. A stale mount is
+// reported as an error rather than repaired, since remounting it needs care.
+func Reconcile(opts ReconcileOptions) ([]Action, error) {
+	if opts.Docker == nil {
+		opts.Docker = docker.NewManager()
+	}
+	if opts.Volume == nil {
+		vm, err := volume.New()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create volume manager: %w", err)
+		}
+		opts.Volume = vm
+	}
+
+	current, err := detectCurrent(&opts)
+	if err != nil {
+		return nil, err
+	}
+	containerName := opts.Config.ContainerName
+
+	var taken []Action
+	mountPoint := current.MountPoint
+	for _, action := range planActions(opts.Desired, current) {
+		switch action {
+		case ActionMountVolume:
+			if opts.Password == nil {
+				return taken, fmt.Errorf("volume %s is not mounted and no password was provided", opts.VolumePath)
+			}
+			mountPoint, err = opts.Volume.MountForRepo(opts.VolumePath, opts.RepoID, opts.Password)
+			if err != nil {
+				return taken, fmt.Errorf("failed to mount volume: %w", err)
+			}
+			if err := volume.WaitMounted(mountPoint, volume.DefaultMountWaitTimeout); err != nil {
+				return taken, err
+			}
+		case ActionStartContainer:
+			opts.Config.VolumeMountPoint = mountPoint
+			if err := opts.Docker.Start(opts.Config); err != nil {
+				return taken, fmt.Errorf("failed to start container: %w", err)
+			}
+		case ActionSetupSymlink:
+			if err := opts.Docker.SetupWorkspaceSymlink(containerName, opts.RepoID); err != nil {
+				return taken, fmt.Errorf("failed to set up workspace symlink: %w", err)
+			}
+		}
+		taken = append(taken, action)
+	}
+
+	// Only a container created above needs its setup commands; they run once
+	// the workspace link exists
+	if slices.Contains(taken, ActionStartContainer) {
+		if err := opts.Docker.RunPostStart(opts.Config); err != nil {
+			return taken, err
+		}
+	}
+
+	// A container that was already running must still serve this volume and workspace
+	if current.ContainerRunning && (opts.Desired.ContainerRunning || opts.Desired.SymlinkValid) {
+		opts.Config.VolumeMountPoint = mountPoint
+		if err := opts.Docker.VerifyMounts(containerName, opts.Config); err != nil {
+			return taken, err
+		}
+	}
+	return taken, nil
+}
+
+// Plan returns the actions Reconcile would take with the same options, in
+// order, without performing any of them, so callers can show the plan and ask
+// for confirmation first. Both detect the environment and plan the same way,
+// so Reconcile runs exactly these actions unless the environment changes in
+// between. It needs no password and does not use the managers in opts.
+func Plan(opts ReconcileOptions) ([]Action, error) {
+	current, err := detectCurrent(&opts)
+	if err != nil {
+		return nil, err
+	}
+	return planActions(opts.Desired, current), nil
+}
+
+// detectCurrent resolves the container name into opts.Config and detects the
+// environment, refusing a stale mount.
+func detectCurrent(opts *ReconcileOptions) (*state.EnvironmentState, error) {
+	containerName, err := docker.ResolveContainerName(opts.Config.ContainerName)
+	if err != nil {
+		return nil, err
+	}
+	opts.Config.ContainerName = containerName
+	opts.Config.RepoID = opts.RepoID
+
+	current := detectEnvironment(opts.VolumePath, containerName, opts.Config.WorkspacePath, opts.RepoID)
+	if current.VolumeStale {
+		return nil, fmt.Errorf("volume mount at %s is stale; remount it before reconciling", current.MountPoint)
+	}
+	return current, nil
+}
+
+// detectEnvironment detects the environment for a volume, container, and
+// workspace whose symlink should point at repoID. Tests replace it.
+var detectEnvironment = func(volumePath, containerName, workspacePath, repoID string) *state.EnvironmentState {
+	detector := state.NewDetector(volumePath, containerName, workspacePath)
+	detector.SetExpectedTarget(repoID, "")
+	return detector.Detect()
+}
+
+// planActions returns the actions needed to move from current to desired.
+func planActions(desired DesiredState, current *state.EnvironmentState) []Action {
+	needSymlink := desired.SymlinkValid
+	needContainer := desired.ContainerRunning || needSymlink
+	needVolume := desired.VolumeMounted || needContainer
+
+	var actions []Action
+	if needVolume && !current.VolumeMounted {
+		actions = append(actions, ActionMountVolume)
+	}
+	if needContainer && !current.ContainerRunning {
+		actions = append(actions, ActionStartContainer)
+	}
+	// The link targets a container path, so it always looks broken from the
+	// host; only its target matters. A new container gets a fresh link.
+	if needSymlink && (!current.ContainerRunning || !current.SymlinkExists || !current.SymlinkTargetCorrect) {
+		actions = append(actions, ActionSetupSymlink)
+	}
+	return actions
+}
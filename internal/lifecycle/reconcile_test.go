@@ -0,0 +1,102 @@
+package lifecycle
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jeanhaley32/claude-capsule/internal/docker"
+	"github.com/jeanhaley32/claude-capsule/internal/state"
+	"github.com/jeanhaley32/claude-capsule/internal/terminal"
+)
+
+func TestPlanActions(t *testing.T) {
+	converged := &state.EnvironmentState{
+		VolumeMounted:        true,
+		ContainerRunning:     true,
+		SymlinkExists:        true,
+		SymlinkTargetCorrect: true,
+	}
+	tests := []struct {
+		name    string
+		desired DesiredState
+		current *state.EnvironmentState
+		want    []Action
+	}{
+		{"nothing running", DesiredState{SymlinkValid: true}, &state.EnvironmentState{},
+			[]Action{ActionMountVolume, ActionStartContainer, ActionSetupSymlink}},
+		{"volume only", DesiredState{VolumeMounted: true}, &state.EnvironmentState{},
+			[]Action{ActionMountVolume}},
+		{"converged", DesiredState{SymlinkValid: true}, converged, nil},
+		{"wrong symlink target", DesiredState{SymlinkValid: true},
+			&state.EnvironmentState{VolumeMounted: true, ContainerRunning: true, SymlinkExists: true},
+			[]Action{ActionSetupSymlink}},
+		{"container stopped", DesiredState{ContainerRunning: true},
+			&state.EnvironmentState{VolumeMounted: true},
+			[]Action{ActionStartContainer}},
+	}
+
+	for _, tt := range tests {
+		if got := planActions(tt.desired, tt.current); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: planActions() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPlanMatchesReconcile(t *testing.T) {
+	mountPoint := t.TempDir()
+	tests := []struct {
+		name    string
+		desired DesiredState
+		current state.EnvironmentState
+	}{
+		{"nothing running", DesiredState{SymlinkValid: true}, state.EnvironmentState{}},
+		{"volume only", DesiredState{VolumeMounted: true}, state.EnvironmentState{}},
+		{"container stopped", DesiredState{ContainerRunning: true},
+			state.EnvironmentState{VolumeMounted: true, MountPoint: mountPoint}},
+		{"wrong symlink target", DesiredState{SymlinkValid: true},
+			state.EnvironmentState{VolumeMounted: true, MountPoint: mountPoint, ContainerRunning: true, SymlinkExists: true}},
+		{"converged", DesiredState{SymlinkValid: true},
+			state.EnvironmentState{VolumeMounted: true, MountPoint: mountPoint, ContainerRunning: true, SymlinkExists: true, SymlinkTargetCorrect: true}},
+	}
+
+	saved := detectEnvironment
+	defer func() { detectEnvironment = saved }()
+	for _, tt := range tests {
+		detectEnvironment = func(volumePath, containerName, workspacePath, repoID string) *state.EnvironmentState {
+			current := tt.current
+			return &current
+		}
+		opts := ReconcileOptions{
+			Desired:    tt.desired,
+			Config:     docker.ContainerConfig{ContainerName: "claude-capsule-test"},
+			RepoID:     "github.com-user-project",
+			VolumePath: "/tmp/test.sparseimage",
+			Password:   &terminal.SecurePassword{},
+			Docker:     &fakeDocker{},
+			Volume:     &fakeVolume{mountPoint: mountPoint},
+		}
+
+		planned, err := Plan(opts)
+		if err != nil {
+			t.Fatalf("%s: Plan() error = %v", tt.name, err)
+		}
+		taken, err := Reconcile(opts)
+		if err != nil {
+			t.Fatalf("%s: Reconcile() error = %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(planned, taken) {
+			t.Errorf("%s: Plan() = %v, Reconcile() took %v", tt.name, planned, taken)
+		}
+	}
+}
+
+func TestPlanRefusesStaleMount(t *testing.T) {
+	saved := detectEnvironment
+	defer func() { detectEnvironment = saved }()
+	detectEnvironment = func(volumePath, containerName, workspacePath, repoID string) *state.EnvironmentState {
+		return &state.EnvironmentState{VolumeMounted: true, VolumeStale: true, MountPoint: "/Volumes/Capsule-test"}
+	}
+	if _, err := Plan(ReconcileOptions{Config: docker.ContainerConfig{ContainerName: "claude-capsule-test"}}); err == nil {
+		t.Error("Plan() with a stale mount = nil error, want error")
+	}
+}
@@ -0,0 +1,108 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jeanhaley32/claude-capsule/internal/docker"
+	"github.com/jeanhaley32/claude-capsule/internal/volume"
+)
+
+// StopReport lists what StopAll stopped and unmounted.
+type StopReport struct {
+	ContainersStopped []string
+	VolumesUnmounted  []string
+	Errors            []error
+}
+
+// StopAll stops every capsule-managed container and then unmounts every capsule
+// volume, for use from system shutdown hooks. It continues past individual
+// failures and stops waiting once ctx is done, so it never hangs shutdown; an
+// operation still running at that point is abandoned. The returned error joins
+// all failures and the report records what succeeded.
+func StopAll(ctx context.Context) (StopReport, error) {
+	return StopAllForSession(ctx, "")
+}
+
+// StopAllForSession is like StopAll but only stops containers labeled with
+// sessionID (see docker.Manager.SetSessionFilter). Mounts are not tied to a
+// session, so when sessionID is set no volumes are unmounted; another user's
+// container may still be using them. Empty behaves like StopAll.
+func StopAllForSession(ctx context.Context, sessionID string) (StopReport, error) {
+	dm := docker.NewManager()
+	if err := dm.SetSessionFilter(sessionID); err != nil {
+		return StopReport{}, err
+	}
+	vm, err := volume.New()
+	if err != nil {
+		return StopReport{}, fmt.Errorf("failed to create volume manager: %w", err)
+	}
+	return stopAll(ctx, dm, vm, sessionID == "")
+}
+
+func stopAll(ctx context.Context, dm docker.DockerManager, vm volume.VolumeManager, unmount bool) (StopReport, error) {
+	var report StopReport
+
+	// The list is handed over on a channel so an abandoned call can't race with us
+	listed := make(chan []string, 1)
+	err := runWithContext(ctx, func() error {
+		names, err := dm.ListCapsuleContainers()
+		listed <- names
+		return err
+	})
+	var containers []string
+	if err != nil {
+		report.Errors = append(report.Errors, err)
+	} else {
+		containers = <-listed
+	}
+	for _, name := range containers {
+		if err := runWithContext(ctx, func() error { return dm.Stop(name) }); err != nil {
+			report.Errors = append(report.Errors, fmt.Errorf("stop container %s: %w", name, err))
+			continue
+		}
+		report.ContainersStopped = append(report.ContainersStopped, name)
+	}
+
+	// Unmount after the containers have released their bind mounts
+	var mountPoints []string
+	if unmount {
+		mountPoints = volume.ListMountPoints()
+	}
+	for _, mountPoint := range mountPoints {
+		if err := runWithContext(ctx, func() error { return vm.Unmount(mountPoint) }); err != nil {
+			report.Errors = append(report.Errors, fmt.Errorf("unmount %s: %w", mountPoint, err))
+			continue
+		}
+		report.VolumesUnmounted = append(report.VolumesUnmounted, mountPoint)
+	}
+
+	if len(report.Errors) > 0 {
+		return report, fmt.Errorf("stop incomplete: %w", errors.Join(report.Errors...))
+	}
+	return report, nil
+}
+
+// runWithContext runs fn, returning ctx's error if ctx is done before fn finishes.
+// Panics in fn are recovered and returned as errors.
+func runWithContext(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
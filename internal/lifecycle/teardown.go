@@ -0,0 +1,136 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jeanhaley32/claude-capsule/internal/docker"
+	"github.com/jeanhaley32/claude-capsule/internal/symlink"
+	"github.com/jeanhaley32/claude-capsule/internal/terminal"
+	"github.com/jeanhaley32/claude-capsule/internal/volume"
+)
+
+// TeardownOptions configures a full capsule teardown.
+type TeardownOptions struct {
+	ContainerName string // Container to stop and remove
+	VolumePath    string // Volume file whose mount point should be unmounted
+	WorkspacePath string // Workspace containing the _docs symlink
+
+	// RemoveSymlink also deletes the workspace _docs symlink.
+	RemoveSymlink bool
+
+	// ConfirmDirty, if set, is called with the changed paths when the running
+	// container's workspace has uncommitted git changes. Returning false aborts
+	// the teardown with ErrTeardownDeclined before anything is stopped.
+	ConfirmDirty func(paths []string) bool
+
+	// SnapshotName, if set, takes an APFS snapshot of the volume under this name
+	// once the container has stopped and before the volume is unmounted.
+	SnapshotName string
+	// Compact reclaims free space in the volume image after it is unmounted,
+	// using CompactPassword to attach it.
+	Compact         bool
+	CompactPassword *terminal.SecurePassword
+
+	// Managers default to the standard implementations when nil.
+	Docker  docker.DockerManager
+	Volume  volume.VolumeManager
+	Symlink symlink.SymlinkManager
+}
+
+// ErrTeardownDeclined is returned when ConfirmDirty declines the teardown.
+var ErrTeardownDeclined = errors.New("teardown declined: workspace has uncommitted changes")
+
+// PostStopError is returned when the teardown itself succeeded but the
+// snapshot or compaction requested in TeardownOptions failed.
+type PostStopError struct {
+	Err error
+}
+
+func (e *PostStopError) Error() string {
+	return fmt.Sprintf("teardown succeeded but post-stop housekeeping failed: %v", e.Err)
+}
+
+func (e *PostStopError) Unwrap() error { return e.Err }
+
+// Teardown stops and removes the container, unmounts the volume, and optionally
+// removes the _docs symlink, with an optional snapshot before the unmount and
+// compaction after it. Every step is attempted even if an earlier one fails;
+// the returned error joins all failures. If only the snapshot or compaction
+// failed, the error is a *PostStopError.
+func Teardown(opts TeardownOptions) error {
+	if opts.Docker == nil {
+		opts.Docker = docker.NewManager()
+	}
+	if opts.Volume == nil {
+		vm, err := volume.New()
+		if err != nil {
+			return fmt.Errorf("failed to create volume manager: %w", err)
+		}
+		opts.Volume = vm
+	}
+	if opts.Symlink == nil {
+		opts.Symlink = symlink.NewManager()
+	}
+
+	if opts.ConfirmDirty != nil && opts.ContainerName != "" {
+		// Best effort: a stopped container or non-git workspace has nothing to check
+		if dirty, paths, err := opts.Docker.WorkspaceDirty(opts.ContainerName); err == nil && dirty && !opts.ConfirmDirty(paths) {
+			return ErrTeardownDeclined
+		}
+	}
+
+	var errs, postStopErrs []error
+
+	// Stop the container first so it releases its bind mounts
+	if opts.ContainerName != "" {
+		if err := opts.Docker.Stop(opts.ContainerName); err != nil {
+			errs = append(errs, fmt.Errorf("stop container %s: %w", opts.ContainerName, err))
+		}
+	}
+
+	// Unmount falls back to a forced detach if the clean unmount fails
+	unmounted := false
+	if opts.VolumePath != "" {
+		if mountPoint := opts.Volume.GetMountPoint(opts.VolumePath); mountPoint != "" {
+			if opts.SnapshotName != "" {
+				if err := opts.Volume.SnapshotVolume(mountPoint, opts.SnapshotName); err != nil {
+					postStopErrs = append(postStopErrs, fmt.Errorf("snapshot %s: %w", opts.SnapshotName, err))
+				}
+			}
+			if err := opts.Volume.Unmount(mountPoint); err != nil {
+				errs = append(errs, fmt.Errorf("unmount volume at %s: %w", mountPoint, err))
+			} else {
+				unmounted = true
+			}
+		} else {
+			if opts.SnapshotName != "" {
+				postStopErrs = append(postStopErrs, fmt.Errorf("snapshot %s: %w", opts.SnapshotName, volume.ErrNotMounted))
+			}
+			unmounted = true
+		}
+	}
+
+	// Compaction needs the volume detached, so skip it if the unmount failed
+	if opts.Compact && opts.VolumePath != "" && unmounted {
+		if _, err := opts.Volume.CompactVolume(opts.VolumePath, opts.CompactPassword); err != nil {
+			postStopErrs = append(postStopErrs, fmt.Errorf("compact volume: %w", err))
+		}
+	}
+
+	if opts.RemoveSymlink {
+		if opts.WorkspacePath == "" {
+			errs = append(errs, fmt.Errorf("remove symlink: workspace path is required"))
+		} else if err := opts.Symlink.Remove(opts.WorkspacePath); err != nil {
+			errs = append(errs, fmt.Errorf("remove symlink: %w", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("teardown incomplete: %w", errors.Join(append(errs, postStopErrs...)...))
+	}
+	if len(postStopErrs) > 0 {
+		return &PostStopError{Err: errors.Join(postStopErrs...)}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package lifecycle
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jeanhaley32/claude-capsule/internal/symlink/symlinktest"
+)
+
+func TestTeardown_SnapshotAndCompact(t *testing.T) {
+	dm := &fakeDocker{}
+	vm := &fakeVolume{mountPoint: "/Volumes/Capsule-abc"}
+	err := Teardown(TeardownOptions{
+		ContainerName: "claude-abc",
+		VolumePath:    "/volumes/capsule.sparseimage",
+		SnapshotName:  "before-upgrade",
+		Compact:       true,
+		Docker:        dm,
+		Volume:        vm,
+		Symlink:       symlinktest.NewFake(),
+	})
+	if err != nil {
+		t.Fatalf("Teardown() error = %v", err)
+	}
+	if want := []string{"stop claude-abc"}; !reflect.DeepEqual(dm.calls, want) {
+		t.Errorf("docker calls = %v, want %v", dm.calls, want)
+	}
+	// The snapshot needs the volume mounted; compaction needs it detached
+	if want := []string{"snapshot before-upgrade", "unmount /Volumes/Capsule-abc", "compact"}; !reflect.DeepEqual(vm.calls, want) {
+		t.Errorf("volume calls = %v, want %v", vm.calls, want)
+	}
+}
+
+func TestTeardown_SnapshotFailure(t *testing.T) {
+	vm := &fakeVolume{mountPoint: "/Volumes/Capsule-abc", snapshotErr: errors.New("no snapshot")}
+	err := Teardown(TeardownOptions{
+		VolumePath:   "/volumes/capsule.sparseimage",
+		SnapshotName: "before-upgrade",
+		Docker:       &fakeDocker{},
+		Volume:       vm,
+		Symlink:      symlinktest.NewFake(),
+	})
+	var postStop *PostStopError
+	if !errors.As(err, &postStop) {
+		t.Fatalf("Teardown() error = %v, want *PostStopError", err)
+	}
+	if vm.mountPoint != "" {
+		t.Error("Teardown() left the volume mounted after a failed snapshot")
+	}
+
+	// Without a mounted volume there is nothing to snapshot
+	err = Teardown(TeardownOptions{
+		VolumePath:   "/volumes/capsule.sparseimage",
+		SnapshotName: "before-upgrade",
+		Docker:       &fakeDocker{},
+		Volume:       &fakeVolume{},
+		Symlink:      symlinktest.NewFake(),
+	})
+	if !errors.As(err, &postStop) {
+		t.Errorf("Teardown() with nothing mounted error = %v, want *PostStopError", err)
+	}
+}
+
+func TestTeardown_ConfirmDirty(t *testing.T) {
+	dm := &fakeDocker{dirty: true, dirtyPaths: []string{"main.go"}}
+	var asked []string
+	opts := TeardownOptions{
+		ContainerName: "claude-abc",
+		Docker:        dm,
+		Volume:        &fakeVolume{},
+		Symlink:       symlinktest.NewFake(),
+		ConfirmDirty: func(paths []string) bool {
+			asked = paths
+			return false
+		},
+	}
+	if err := Teardown(opts); !errors.Is(err, ErrTeardownDeclined) {
+		t.Fatalf("Teardown() error = %v, want ErrTeardownDeclined", err)
+	}
+	if !reflect.DeepEqual(asked, []string{"main.go"}) || len(dm.calls) != 0 {
+		t.Errorf("asked about %v and made calls %v, want the dirty paths and nothing stopped", asked, dm.calls)
+	}
+
+	opts.ConfirmDirty = func([]string) bool { return true }
+	if err := Teardown(opts); err != nil || !reflect.DeepEqual(dm.calls, []string{"stop claude-abc"}) {
+		t.Errorf("confirmed Teardown() = %v, calls %v, want the container stopped", err, dm.calls)
+	}
+
+	// A clean workspace is never asked about
+	dm.dirty, dm.calls = false, nil
+	opts.ConfirmDirty = func([]string) bool { t.Error("ConfirmDirty called for a clean workspace"); return false }
+	if err := Teardown(opts); err != nil {
+		t.Errorf("Teardown() with a clean workspace error = %v", err)
+	}
+}
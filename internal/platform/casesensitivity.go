@@ -0,0 +1,53 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// caseProbePrefix names the files CheckWorkspaceCaseSensitivity creates.
+const caseProbePrefix = ".capsule-case-"
+
+// CheckWorkspaceCaseSensitivity reports whether the filesystem holding
+// workspacePath treats names that differ only by case as different files. It
+// creates a lowercase probe file, then tries to create its uppercase twin; on
+// a case-insensitive filesystem (the macOS default) the second name already
+// exists. Both files are removed before returning.
+//
+// A repository containing case-only filename differences checks out correctly
+// inside the Linux container but not on an insensitive host workspace.
+func CheckWorkspaceCaseSensitivity(workspacePath string) (sensitive bool, err error) {
+	lower, err := os.CreateTemp(workspacePath, caseProbePrefix+"*")
+	if err != nil {
+		return false, fmt.Errorf("failed to create case probe in %s: %w", workspacePath, err)
+	}
+	lower.Close()
+	defer os.Remove(lower.Name())
+
+	// CreateTemp's random suffix is digits, so upper-case the whole base name
+	upperName := filepath.Join(workspacePath, strings.ToUpper(filepath.Base(lower.Name())))
+	upper, err := os.OpenFile(upperName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create case probe in %s: %w", workspacePath, err)
+	}
+	upper.Close()
+	defer os.Remove(upperName)
+
+	// Both creates succeeded; confirm both names are still distinct files
+	lowerInfo, err := os.Stat(lower.Name())
+	if err != nil {
+		return false, fmt.Errorf("failed to stat case probe: %w", err)
+	}
+	upperInfo, err := os.Stat(upperName)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat case probe: %w", err)
+	}
+	return !os.SameFile(lowerInfo, upperInfo), nil
+}
@@ -0,0 +1,40 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckWorkspaceCaseSensitivity(t *testing.T) {
+	dir := t.TempDir()
+
+	// Work out the answer independently: does an upper-case name find a lower-case file?
+	reference := filepath.Join(t.TempDir(), "probe")
+	if err := os.WriteFile(reference, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := os.Stat(filepath.Join(filepath.Dir(reference), "PROBE"))
+	want := os.IsNotExist(err)
+
+	sensitive, err := CheckWorkspaceCaseSensitivity(dir)
+	if err != nil {
+		t.Fatalf("CheckWorkspaceCaseSensitivity() error = %v", err)
+	}
+	if sensitive != want {
+		t.Errorf("CheckWorkspaceCaseSensitivity() = %v, want %v", sensitive, want)
+	}
+
+	// The probe files are removed
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("workspace has %d leftover entries after the check", len(entries))
+	}
+
+	if _, err := CheckWorkspaceCaseSensitivity(filepath.Join(dir, "missing")); err == nil {
+		t.Error("CheckWorkspaceCaseSensitivity(missing dir) = nil error, want error")
+	}
+}
@@ -0,0 +1,41 @@
+package platform
+
+import "fmt"
+
+// problematicFilesystems lists filesystem types that are known to lose or corrupt
+// writes when bind-mounted into Docker Desktop.
+var problematicFilesystems = map[string]bool{
+	"smbfs":   true,
+	"cifs":    true,
+	"smb2":    true,
+	"nfs":     true,
+	"afpfs":   true,
+	"webdav":  true,
+	"fuse":    true,
+	"macfuse": true,
+	"osxfuse": true,
+}
+
+// UnsupportedFilesystemError reports a path on a filesystem that is not safe to
+// bind-mount into a container.
+type UnsupportedFilesystemError struct {
+	Path string
+	Type string
+}
+
+func (e *UnsupportedFilesystemError) Error() string {
+	return fmt.Sprintf("%s is on a %s filesystem, which is known to cause silent data issues when bind-mounted into Docker", e.Path, e.Type)
+}
+
+// CheckBindMountFilesystem returns *UnsupportedFilesystemError if path lives on
+// a network or FUSE filesystem known to misbehave under Docker bind mounts.
+func CheckBindMountFilesystem(path string) error {
+	fsType, err := FilesystemType(path)
+	if err != nil {
+		return fmt.Errorf("failed to determine filesystem of %s: %w", path, err)
+	}
+	if problematicFilesystems[fsType] {
+		return &UnsupportedFilesystemError{Path: path, Type: fsType}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package platform
+
+import "syscall"
+
+// FilesystemType returns the type name of the filesystem containing path
+// (e.g. "apfs", "smbfs"), as reported by statfs f_fstypename.
+func FilesystemType(path string) (string, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return "", err
+	}
+
+	name := make([]byte, 0, len(st.Fstypename))
+	for _, c := range st.Fstypename {
+		if c == 0 {
+			break
+		}
+		name = append(name, byte(c))
+	}
+	return string(name), nil
+}
+
+// FreeSpace returns the bytes available to unprivileged users on the
+// filesystem containing path.
+func FreeSpace(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return st.Bavail * uint64(st.Bsize), nil
+}
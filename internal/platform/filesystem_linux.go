@@ -0,0 +1,45 @@
+package platform
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// linuxFilesystemMagic maps statfs f_type values to filesystem type names.
+var linuxFilesystemMagic = map[int64]string{
+	0xEF53:     "ext4",
+	0x9123683E: "btrfs",
+	0x58465342: "xfs",
+	0x01021994: "tmpfs",
+	0x794C7630: "overlay",
+	0x517B:     "smbfs",
+	0xFE534D42: "smb2",
+	0xFF534D42: "cifs",
+	0x6969:     "nfs",
+	0x65735546: "fuse",
+}
+
+// FilesystemType returns the type name of the filesystem containing path
+// (e.g. "ext4", "cifs"), derived from statfs f_type. Unrecognized types are
+// reported by their magic number.
+func FilesystemType(path string) (string, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return "", err
+	}
+
+	if name, ok := linuxFilesystemMagic[int64(st.Type)]; ok {
+		return name, nil
+	}
+	return fmt.Sprintf("0x%x", st.Type), nil
+}
+
+// FreeSpace returns the bytes available to unprivileged users on the
+// filesystem containing path.
+func FreeSpace(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return st.Bavail * uint64(st.Bsize), nil
+}
@@ -0,0 +1,15 @@
+//go:build !darwin && !linux
+
+package platform
+
+import "errors"
+
+// FilesystemType is not supported on this platform.
+func FilesystemType(path string) (string, error) {
+	return "", errors.New("filesystem type detection is not supported on this platform")
+}
+
+// FreeSpace is not supported on this platform.
+func FreeSpace(path string) (uint64, error) {
+	return 0, errors.New("free space detection is not supported on this platform")
+}
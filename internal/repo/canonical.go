@@ -0,0 +1,149 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxForgeResponseSize bounds how much of a forge API response is read.
+const maxForgeResponseSize = 1 << 20
+
+// forgeAPI describes how to look up repositories on a known host.
+type forgeAPI struct {
+	baseURL string
+	lookup  func(ctx context.Context, baseURL, path, token string) (string, error)
+}
+
+// forgeAPIs maps known hosts to their APIs. Tests point baseURL at a fake server.
+var forgeAPIs = map[string]forgeAPI{
+	"github.com": {baseURL: "https://api.github.com", lookup: githubCanonicalPath},
+	"gitlab.com": {baseURL: "https://gitlab.com/api/v4", lookup: gitlabCanonicalPath},
+}
+
+// CanonicalRepoID returns a repo ID that survives renames and forks. For
+// remotes on a known forge (GitHub, GitLab) it asks the forge API for the
+// repository's current location and, for forks, the repository it was forked
+// from, and derives the ID from that. The request is bounded by ctx.
+//
+// Without a token, or for other hosts, it makes no network call and returns
+// NormalizeRemoteURL(remoteURL). If the lookup fails, that same fallback ID is
+// returned along with the error, so callers may log the error and carry on.
+func CanonicalRepoID(ctx context.Context, remoteURL, token string) (string, error) {
+	fallback := NormalizeRemoteURL(remoteURL)
+	if token == "" {
+		return fallback, nil
+	}
+	host, path, ok := splitRemoteURL(remoteURL)
+	if !ok {
+		return fallback, nil
+	}
+	api, known := forgeAPIs[host]
+	if !known {
+		return fallback, nil
+	}
+
+	canonical, err := api.lookup(ctx, api.baseURL, path, token)
+	if err != nil {
+		return fallback, fmt.Errorf("failed to resolve canonical repository for %s: %w", remoteURL, err)
+	}
+	return NormalizeRemoteURL(host + "/" + canonical), nil
+}
+
+// splitRemoteURL splits an HTTPS or SSH remote URL into its lowercase host and
+// owner/name path, without the .git suffix.
+func splitRemoteURL(remoteURL string) (host, path string, ok bool) {
+	s := strings.TrimSpace(remoteURL)
+	if rest, found := strings.CutPrefix(s, "git@"); found {
+		host, path, ok = strings.Cut(rest, ":")
+	} else {
+		u, err := url.Parse(s)
+		if err != nil || u.Host == "" {
+			return "", "", false
+		}
+		host, path, ok = u.Hostname(), u.Path, true
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if !ok || host == "" || !strings.Contains(path, "/") {
+		return "", "", false
+	}
+	return strings.ToLower(host), path, true
+}
+
+// githubCanonicalPath returns the owner/name of the root of a GitHub
+// repository's fork network. Renamed repositories redirect to their new name.
+func githubCanonicalPath(ctx context.Context, baseURL, path, token string) (string, error) {
+	var repo struct {
+		FullName string `json:"full_name"`
+		Parent   *struct {
+			FullName string `json:"full_name"`
+		} `json:"parent"`
+		Source *struct {
+			FullName string `json:"full_name"`
+		} `json:"source"`
+	}
+	header := http.Header{"Authorization": {"Bearer " + token}, "Accept": {"application/vnd.github+json"}}
+	if err := getForgeJSON(ctx, baseURL+"/repos/"+path, header, &repo); err != nil {
+		return "", err
+	}
+
+	switch {
+	case repo.Source != nil && repo.Source.FullName != "":
+		return repo.Source.FullName, nil
+	case repo.Parent != nil && repo.Parent.FullName != "":
+		return repo.Parent.FullName, nil
+	case repo.FullName != "":
+		return repo.FullName, nil
+	}
+	return "", fmt.Errorf("response has no repository name")
+}
+
+// gitlabCanonicalPath returns the namespace/name of a GitLab project, or of
+// the project it was forked from.
+func gitlabCanonicalPath(ctx context.Context, baseURL, path, token string) (string, error) {
+	var project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+		ForkedFrom        *struct {
+			PathWithNamespace string `json:"path_with_namespace"`
+		} `json:"forked_from_project"`
+	}
+	header := http.Header{"PRIVATE-TOKEN": {token}}
+	if err := getForgeJSON(ctx, baseURL+"/projects/"+url.PathEscape(path), header, &project); err != nil {
+		return "", err
+	}
+
+	if project.ForkedFrom != nil && project.ForkedFrom.PathWithNamespace != "" {
+		return project.ForkedFrom.PathWithNamespace, nil
+	}
+	if project.PathWithNamespace != "" {
+		return project.PathWithNamespace, nil
+	}
+	return "", fmt.Errorf("response has no project path")
+}
+
+// getForgeJSON sends a GET request and decodes a JSON response into v.
+func getForgeJSON(ctx context.Context, endpoint string, header http.Header, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header = header
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxForgeResponseSize)).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
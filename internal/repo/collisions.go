@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CheckRepoCollisions normalizes each remote URL with NormalizeRemoteURL and
+// returns the repo IDs that more than one repository maps to, each with the
+// input URLs that produce it, in input order. Spellings of the same
+// repository (HTTPS or SSH, any case, with or without .git) are not a
+// collision; e.g. github.com/a/b-c and github.com/a-b/c are, since both become
+// github.com-a-b-c. Use it to pre-flight a batch of repositories before
+// provisioning capsules for them. Empty URLs are reported in the error and
+// skipped.
+func CheckRepoCollisions(remoteURLs []string) (map[string][]string, error) {
+	var errs []error
+	urlsByID := map[string][]string{}
+	reposByID := map[string]map[string]bool{}
+	seen := map[string]bool{}
+	for i, remoteURL := range remoteURLs {
+		remoteURL = strings.TrimSpace(remoteURL)
+		if remoteURL == "" {
+			errs = append(errs, fmt.Errorf("remote URL %d is empty", i))
+			continue
+		}
+		if seen[remoteURL] {
+			continue
+		}
+		seen[remoteURL] = true
+
+		id := NormalizeRemoteURL(remoteURL)
+		urlsByID[id] = append(urlsByID[id], remoteURL)
+		if reposByID[id] == nil {
+			reposByID[id] = map[string]bool{}
+		}
+		reposByID[id][repoLocation(remoteURL)] = true
+	}
+
+	collisions := map[string][]string{}
+	for id, repos := range reposByID {
+		if len(repos) > 1 {
+			collisions[id] = urlsByID[id]
+		}
+	}
+	return collisions, errors.Join(errs...)
+}
+
+// repoLocation returns a key that is equal for every spelling of the same
+// repository's remote URL: lowercase host/owner/name without scheme or .git.
+func repoLocation(remoteURL string) string {
+	if host, path, ok := splitRemoteURL(remoteURL); ok {
+		return host + "/" + strings.TrimSuffix(strings.ToLower(path), ".git")
+	}
+	return strings.TrimSuffix(strings.ToLower(remoteURL), ".git")
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
@@ -36,11 +37,14 @@ func (d *DefaultIdentifier) GetRepoID(workspacePath string) (string, error) {
 	cmd := exec.Command("git", "-C", workspacePath, "remote", "get-url", "origin")
 	output, err := cmd.Output()
 	if err != nil {
-		// Not a git repo or no remote, use directory name
 		absPath, err := filepath.Abs(workspacePath)
 		if err != nil {
 			return "", fmt.Errorf("failed to get absolute path: %w", err)
 		}
+		if findGitRoot(absPath) == "" {
+			return PathDerivedID(absPath), nil
+		}
+		// Git repo without a remote, use directory name
 		return sanitizeName(filepath.Base(absPath)), nil
 	}
 
@@ -83,11 +87,29 @@ func (d *DefaultIdentifier) GetWorkspaceRoot(path string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
-// normalizeRemoteURL converts a git remote URL to a filesystem-safe identifier.
+// NormalizeRemoteURL converts a git remote URL to a filesystem-safe identifier.
+// The result is used as the per-repository directory name inside the volume
+// (repos/<id>) and as the input to container name hashing, so this behavior is
+// stable: changing it would orphan existing documentation.
+//
+// Guarantees:
+//   - The http://, https://, and git:// schemes are removed.
+//   - SCP-style SSH remotes (git@host:path) are rewritten to host/path.
+//   - A trailing .git suffix is removed (in any case).
+//   - Host and path are lowercased, since hosts and GitHub paths are
+//     case-insensitive. Docs stored under an older mixed-case ID are moved by
+//     volume.AdoptLegacyCaseRepoDocs when the volume is next used.
+//   - The remainder is passed through SanitizeName, so every guarantee of
+//     SanitizeName also holds for the result.
+//
 // Examples:
 //   - https://github.com/user/repo.git -> github.com-user-repo
 //   - git@github.com:user/repo.git -> github.com-user-repo
-func normalizeRemoteURL(url string) string {
+//   - https://GitHub.com/User/Repo -> github.com-user-repo
+func NormalizeRemoteURL(url string) string {
+	// Hosts and repository paths are case-insensitive
+	url = strings.ToLower(url)
+
 	// Remove protocol
 	url = strings.TrimPrefix(url, "https://")
 	url = strings.TrimPrefix(url, "http://")
@@ -102,11 +124,20 @@ func normalizeRemoteURL(url string) string {
 	// Remove .git suffix
 	url = strings.TrimSuffix(url, ".git")
 
-	return sanitizeName(url)
+	return SanitizeName(url)
 }
 
-// sanitizeName converts a string to a filesystem-safe name.
-func sanitizeName(name string) string {
+// SanitizeName converts a string to a filesystem-safe name.
+//
+// Guarantees:
+//   - The result is never empty; inputs with no usable characters become "unknown-repo".
+//   - The result contains only [a-zA-Z0-9._-].
+//   - Runs of path separators (/ \ :), "@", and whitespace become a single hyphen.
+//   - Other unsafe characters are dropped.
+//   - The result never starts or ends with a hyphen and never contains "--".
+//   - The result is at most 100 bytes long.
+//   - The function is idempotent: SanitizeName(SanitizeName(s)) == SanitizeName(s).
+func SanitizeName(name string) string {
 	// Replace path separators and special characters with hyphens
 	name = pathSepRegex.ReplaceAllString(name, "-")
 
@@ -133,3 +164,109 @@ func sanitizeName(name string) string {
 
 	return name
 }
+
+// pathHashLength is the number of hex characters of the path hash in PathDerivedID.
+const pathHashLength = 8
+
+// PathDerivedID returns the repo ID for a directory that is not in a git
+// repository: its sanitized base name plus a hash of the absolute path, e.g.
+// "scratch-1a2b3c4d". Two directories with the same name get different IDs,
+// and the ID stays stable as long as the directory is not moved.
+func PathDerivedID(absPath string) string {
+	hash := sha256.Sum256([]byte(filepath.Clean(absPath)))
+	suffix := hex.EncodeToString(hash[:])[:pathHashLength]
+
+	name := SanitizeName(filepath.Base(absPath))
+	if maxName := maxIdentifierLength - pathHashLength - 1; len(name) > maxName {
+		name = strings.TrimRight(name[:maxName], "-")
+	}
+	return name + "-" + suffix
+}
+
+// Display ID parameters for ShortID
+const (
+	shortIDHashLength = 6
+	shortIDMaxName    = 16
+)
+
+// ShortID returns a compact, stable form of a repo ID for display, e.g.
+// "github.com-user-capsule" -> "capsule-43c3cb". The format is the last
+// hyphen-separated segment of the ID (the first is usually the shared host),
+// truncated to 16 characters, a hyphen, and the first 6 hex characters of the
+// SHA-256 of the full ID. It is deterministic and will not change between
+// versions, so scripts may rely on it. IDs that share a last segment differ
+// by their hash; it is for display only and must not replace the full ID.
+func ShortID(repoID string) string {
+	hash := sha256.Sum256([]byte(repoID))
+	suffix := hex.EncodeToString(hash[:])[:shortIDHashLength]
+
+	name := strings.TrimRight(repoID, "-")
+	name = unsafeCharRegex.ReplaceAllString(name[strings.LastIndex(name, "-")+1:], "")
+	if len(name) > shortIDMaxName {
+		name = name[:shortIDMaxName]
+	}
+	if name == "" {
+		return suffix
+	}
+	return name + "-" + suffix
+}
+
+// normalizeRemoteURL is the internal alias for NormalizeRemoteURL.
+func normalizeRemoteURL(url string) string {
+	return NormalizeRemoteURL(url)
+}
+
+// sanitizeName is the internal alias for SanitizeName.
+func sanitizeName(name string) string {
+	return SanitizeName(name)
+}
+
+// IdentifyWorkspace finds the git repository enclosing dir and returns its repo ID
+// along with the origin remote URL it was derived from. It walks up from dir to the
+// nearest directory containing .git. If there is no origin remote, the ID is
+// derived from the repository's directory name; if there is no repository at
+// all, it is PathDerivedID(dir). Remote is empty in both cases.
+func IdentifyWorkspace(dir string) (repoID string, remote string, err error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	root := findGitRoot(absDir)
+	if root == "" {
+		return PathDerivedID(absDir), "", nil
+	}
+
+	cmd := exec.Command("git", "-C", root, "config", "--get", "remote.origin.url")
+	output, err := cmd.Output()
+	remote = strings.TrimSpace(string(output))
+	if err != nil || remote == "" {
+		// No origin remote configured
+		return SanitizeName(filepath.Base(root)), "", nil
+	}
+
+	return NormalizeRemoteURL(remote), remote, nil
+}
+
+// findGitRoot walks up from dir looking for a .git entry and returns the
+// directory containing it, or "" if none is found. .git may be a directory
+// or, for worktrees and submodules, a file.
+func findGitRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LegacyPathDerivedID returns the ID a directory outside any git repository
+// had before PathDerivedID: just its sanitized base name. Docs stored under it
+// are moved by volume.AdoptLegacyRepoDocs.
+func LegacyPathDerivedID(absPath string) string {
+	return SanitizeName(filepath.Base(absPath))
+}
@@ -0,0 +1,178 @@
+package repo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeRemoteURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://github.com/user/repo.git", "github.com-user-repo"},
+		{"http://github.com/user/repo", "github.com-user-repo"},
+		{"git@github.com:user/repo.git", "github.com-user-repo"},
+		{"git://github.com/user/repo.git", "github.com-user-repo"},
+		{"https://GitHub.com/User/Repo", "github.com-user-repo"},
+		{"https://github.com/User/Repo.GIT", "github.com-user-repo"},
+		{"git@GITHUB.COM:User/Repo.git", "github.com-user-repo"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeRemoteURL(tt.url); got != tt.want {
+			t.Errorf("NormalizeRemoteURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCheckRepoCollisions(t *testing.T) {
+	got, err := CheckRepoCollisions([]string{
+		"https://github.com/a/b-c.git",
+		"git@GitHub.com:A-B/C.git", // Another repository with the same ID
+		"https://GitHub.com/user/repo",
+		"git@github.com:User/Repo.GIT", // The same repository as above
+		"https://github.com/a/b-c.git",
+	})
+	if err != nil {
+		t.Fatalf("CheckRepoCollisions() error = %v", err)
+	}
+	want := map[string][]string{
+		"github.com-a-b-c": {"https://github.com/a/b-c.git", "git@GitHub.com:A-B/C.git"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckRepoCollisions() = %v, want %v", got, want)
+	}
+
+	if _, err := CheckRepoCollisions([]string{" "}); err == nil {
+		t.Error("CheckRepoCollisions() accepted an empty URL")
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"my-project", "my-project"},
+		{"my project", "my-project"},
+		{"a//b\\\\c", "a-b-c"},
+		{"--leading-and-trailing--", "leading-and-trailing"},
+		{"weird$chars!", "weirdchars"},
+		{"", "unknown-repo"},
+		{"///", "unknown-repo"},
+	}
+
+	for _, tt := range tests {
+		if got := SanitizeName(tt.name); got != tt.want {
+			t.Errorf("SanitizeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeName_Guarantees(t *testing.T) {
+	inputs := []string{
+		strings.Repeat("a-", 80),
+		"host:port/path@ref with space",
+		"ünïcödé",
+	}
+
+	for _, input := range inputs {
+		got := SanitizeName(input)
+		if len(got) > maxIdentifierLength {
+			t.Errorf("SanitizeName(%q) length = %d, want <= %d", input, len(got), maxIdentifierLength)
+		}
+		if strings.HasPrefix(got, "-") || strings.HasSuffix(got, "-") || strings.Contains(got, "--") {
+			t.Errorf("SanitizeName(%q) = %q has stray hyphens", input, got)
+		}
+		if again := SanitizeName(got); again != got {
+			t.Errorf("SanitizeName is not idempotent: %q -> %q", got, again)
+		}
+	}
+}
+
+func TestPathDerivedID(t *testing.T) {
+	a := PathDerivedID("/home/me/a/scratch")
+	b := PathDerivedID("/home/me/b/scratch")
+	if a == b {
+		t.Errorf("PathDerivedID gave %q for two different paths", a)
+	}
+	if !strings.HasPrefix(a, "scratch-") || len(a) != len("scratch-")+pathHashLength {
+		t.Errorf("PathDerivedID() = %q, want scratch-<hash>", a)
+	}
+	if again := PathDerivedID("/home/me/a/scratch/"); again != a {
+		t.Errorf("PathDerivedID is not stable across trailing slash: %q vs %q", a, again)
+	}
+
+	long := PathDerivedID("/" + strings.Repeat("x", 200))
+	if len(long) > maxIdentifierLength || SanitizeName(long) != long {
+		t.Errorf("PathDerivedID(long) = %q is not a valid identifier", long)
+	}
+}
+
+func TestIdentifyWorkspace_NoGit(t *testing.T) {
+	dir := t.TempDir()
+	if findGitRoot(dir) != "" {
+		t.Skip("temp directory is inside a git repository")
+	}
+	repoID, remote, err := IdentifyWorkspace(dir)
+	if err != nil {
+		t.Fatalf("IdentifyWorkspace() error = %v", err)
+	}
+	if repoID != PathDerivedID(dir) || remote != "" {
+		t.Errorf("IdentifyWorkspace() = %q, %q, want path-derived ID", repoID, remote)
+	}
+}
+
+func TestShortID(t *testing.T) {
+	id := ShortID("github.com-user-capsule")
+	if id != ShortID("github.com-user-capsule") {
+		t.Error("ShortID() is not deterministic")
+	}
+	// Pinned: the format is documented as stable across versions
+	if id != "capsule-43c3cb" {
+		t.Errorf("ShortID() = %q, want capsule-43c3cb", id)
+	}
+
+	// Same last segment, different repos
+	if fork := ShortID("github.com-fork-capsule"); fork == id {
+		t.Errorf("ShortID() collided for different repos: %q", fork)
+	}
+
+	if got := ShortID("---"); len(got) != 6 {
+		t.Errorf("ShortID(\"---\") = %q, want hash only", got)
+	}
+	if got := ShortID("github.com-user-" + strings.Repeat("x", 40)); len(got) != 16+1+6 {
+		t.Errorf("ShortID(long) = %q, want name truncated to 16", got)
+	}
+}
+
+func TestCanonicalRepoID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" || r.URL.Path != "/repos/me/capsule" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"full_name": "me/capsule", "parent": {"full_name": "upstream/capsule"}}`))
+	}))
+	defer server.Close()
+
+	github := forgeAPIs["github.com"]
+	forgeAPIs["github.com"] = forgeAPI{baseURL: server.URL, lookup: github.lookup}
+	defer func() { forgeAPIs["github.com"] = github }()
+
+	ctx := context.Background()
+	if id, err := CanonicalRepoID(ctx, "git@github.com:me/capsule.git", "token"); err != nil || id != "github.com-upstream-capsule" {
+		t.Errorf("CanonicalRepoID(fork) = %q, %v", id, err)
+	}
+	if id, err := CanonicalRepoID(ctx, "https://github.com/me/capsule", ""); err != nil || id != "github.com-me-capsule" {
+		t.Errorf("CanonicalRepoID(no token) = %q, %v, want fallback", id, err)
+	}
+	if id, err := CanonicalRepoID(ctx, "https://github.com/me/other", "token"); err == nil || id != "github.com-me-other" {
+		t.Errorf("CanonicalRepoID(lookup failure) = %q, %v, want fallback and error", id, err)
+	}
+}
@@ -3,8 +3,9 @@ package repo
 // Identifier generates consistent repository IDs.
 type Identifier interface {
 	// GetRepoID returns a unique, filesystem-safe identifier for the repository.
-	// For git repos, this is derived from the remote URL.
-	// For non-git directories, this is derived from the directory name.
+	// For git repos, this is derived from the remote URL, or the directory
+	// name if there is no remote. For directories outside any git repository,
+	// it is the directory name plus a hash of its absolute path (see PathDerivedID).
 	GetRepoID(workspacePath string) (string, error)
 
 	// GetWorkspaceRoot returns the root directory of the workspace.
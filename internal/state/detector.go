@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/jeanhaley32/claude-capsule/internal/constants"
+	"github.com/jeanhaley32/claude-capsule/internal/docslink"
+	"github.com/jeanhaley32/claude-capsule/internal/volume"
 )
 
 // Timeout for state detection commands
@@ -19,6 +21,7 @@ type EnvironmentState struct {
 	VolumeExists     bool
 	VolumePath       string
 	VolumeMounted    bool
+	VolumeStale      bool // Mount point exists but its contents are unreadable
 	MountPoint       string
 	ContainerExists  bool
 	ContainerRunning bool
@@ -27,6 +30,11 @@ type EnvironmentState struct {
 	SymlinkBroken    bool
 	SymlinkPath      string
 	WorkspacePath    string
+
+	// SymlinkTarget and SymlinkTargetCorrect are only populated when an
+	// expected target has been set with SetExpectedTarget.
+	SymlinkTarget        string
+	SymlinkTargetCorrect bool
 }
 
 // Detector checks the state of the environment.
@@ -34,6 +42,12 @@ type Detector struct {
 	volumePath    string
 	containerName string
 	workspacePath string
+
+	expectedRepoID   string
+	volumeMountPoint string
+	docsLink         string
+
+	watchInterval time.Duration
 }
 
 // NewDetector creates a new state detector.
@@ -45,6 +59,21 @@ func NewDetector(volumePath, containerName, workspacePath string) *Detector {
 	}
 }
 
+// SetExpectedTarget enables checking that the _docs symlink points at repos/<repoID>.
+// The link is accepted if it targets either the in-container path
+// (/claude-env/repos/<repoID>) or the host path under volumeMountPoint.
+// volumeMountPoint may be empty to check only the in-container path.
+func (d *Detector) SetExpectedTarget(repoID, volumeMountPoint string) {
+	d.expectedRepoID = repoID
+	d.volumeMountPoint = volumeMountPoint
+}
+
+// SetDocsLinkName sets the name of the workspace symlink to check; empty
+// selects the default, constants.DocsSymlinkName.
+func (d *Detector) SetDocsLinkName(name string) {
+	d.docsLink = name
+}
+
 // Detect checks all aspects of the environment state.
 func (d *Detector) Detect() *EnvironmentState {
 	state := &EnvironmentState{
@@ -59,37 +88,59 @@ func (d *Detector) Detect() *EnvironmentState {
 	}
 
 	// Check if volume is mounted
-	state.MountPoint, state.VolumeMounted = d.checkVolumeMounted()
+	state.MountPoint, state.VolumeMounted, state.VolumeStale = d.checkVolumeMounted()
 
 	// Check container status
 	state.ContainerExists, state.ContainerRunning = d.checkContainer()
 
 	// Check symlink status
-	state.SymlinkPath = filepath.Join(d.workspacePath, constants.DocsSymlinkName)
+	state.SymlinkPath = filepath.Join(d.workspacePath, docslink.Name(d.docsLink))
 	state.SymlinkExists, state.SymlinkBroken = d.checkSymlink()
+	if state.SymlinkExists && d.expectedRepoID != "" {
+		state.SymlinkTarget, state.SymlinkTargetCorrect = d.checkSymlinkTarget()
+	}
 
 	return state
 }
 
-// checkVolumeMounted checks if the ClaudeEnv volume is mounted.
-// This mirrors the logic in volume/macos.go findMountPoint().
-func (d *Detector) checkVolumeMounted() (string, bool) {
-	// Check for unique mount points in /tmp (current approach)
-	entries, err := os.ReadDir("/tmp")
-	if err == nil {
-		for _, entry := range entries {
-			if strings.HasPrefix(entry.Name(), "capsule-") && entry.IsDir() {
-				mountPoint := filepath.Join("/tmp", entry.Name())
-				// Verify it's actually mounted by checking for content
-				contents, err := os.ReadDir(mountPoint)
-				if err == nil && len(contents) > 0 {
-					return mountPoint, true
-				}
-			}
+// livenessProbeDir is a directory every bootstrapped volume contains.
+// Stat-ing it distinguishes a live mount from a stale one.
+const livenessProbeDir = "home"
+
+// checkVolumeMounted checks if a capsule volume is mounted and whether it is usable.
+// A mount point whose contents can't be read (e.g. the backing image was
+// detached underneath it) is reported as mounted but stale.
+// When an expected repo ID is set, that repo's own mount point is checked first;
+// otherwise, or for legacy mounts, every capsule mount point is scanned.
+func (d *Detector) checkVolumeMounted() (mountPoint string, mounted bool, stale bool) {
+	if d.expectedRepoID != "" {
+		candidate := volume.MountPointForVolume(d.expectedRepoID)
+		if mounted, stale := probeMountPoint(candidate); mounted {
+			return candidate, true, stale
 		}
 	}
 
-	return "", false
+	for _, candidate := range volume.ListMountPoints() {
+		if mounted, stale := probeMountPoint(candidate); mounted {
+			return candidate, true, stale
+		}
+	}
+
+	return "", false, false
+}
+
+// probeMountPoint reports whether a volume is mounted at candidate and whether it is stale.
+// A single Stat is enough: a live volume has the probe dir, a leftover empty
+// directory (or no directory) doesn't, and a dead mount fails with an I/O error.
+func probeMountPoint(candidate string) (mounted bool, stale bool) {
+	_, err := os.Stat(filepath.Join(candidate, livenessProbeDir))
+	if err == nil {
+		return true, false
+	}
+	if !os.IsNotExist(err) {
+		return true, true
+	}
+	return false, false
 }
 
 // checkContainer checks if the container exists and is running.
@@ -119,7 +170,7 @@ func (d *Detector) checkContainer() (exists bool, running bool) {
 
 // checkSymlink checks if the _docs symlink exists and if it's broken.
 func (d *Detector) checkSymlink() (exists bool, broken bool) {
-	symlinkPath := filepath.Join(d.workspacePath, constants.DocsSymlinkName)
+	symlinkPath := filepath.Join(d.workspacePath, docslink.Name(d.docsLink))
 
 	// Check if symlink exists
 	info, err := os.Lstat(symlinkPath)
@@ -141,6 +192,31 @@ func (d *Detector) checkSymlink() (exists bool, broken bool) {
 	return exists, broken
 }
 
+// checkSymlinkTarget reads the _docs symlink and compares it to the expected repo directory.
+func (d *Detector) checkSymlinkTarget() (target string, correct bool) {
+	symlinkPath := filepath.Join(d.workspacePath, docslink.Name(d.docsLink))
+
+	target, err := os.Readlink(symlinkPath)
+	if err != nil {
+		return "", false
+	}
+
+	cleanTarget := filepath.Clean(target)
+	expected := []string{
+		filepath.Join(constants.ContainerVolumePath, constants.ReposDirName, d.expectedRepoID),
+	}
+	if d.volumeMountPoint != "" {
+		expected = append(expected, filepath.Join(d.volumeMountPoint, constants.ReposDirName, d.expectedRepoID))
+	}
+
+	for _, e := range expected {
+		if cleanTarget == e {
+			return target, true
+		}
+	}
+	return target, false
+}
+
 // CheckDockerRunning verifies Docker daemon is running.
 func CheckDockerRunning() error {
 	ctx, cancel := context.WithTimeout(context.Background(), stateCheckTimeout)
@@ -0,0 +1,58 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// DefaultWatchInterval is how often Watch polls when no interval has been set.
+const DefaultWatchInterval = 2 * time.Second
+
+// SetWatchInterval configures how often Watch polls the environment.
+func (d *Detector) SetWatchInterval(interval time.Duration) {
+	d.watchInterval = interval
+}
+
+// Watch polls Detect and emits the environment state whenever it changes.
+// The current state is emitted immediately. The channel is closed when ctx is done.
+func (d *Detector) Watch(ctx context.Context) (<-chan EnvironmentState, error) {
+	interval := d.watchInterval
+	if interval == 0 {
+		interval = DefaultWatchInterval
+	}
+	if interval < 0 {
+		return nil, fmt.Errorf("watch interval must be positive, got %v", interval)
+	}
+
+	ch := make(chan EnvironmentState)
+
+	go func() {
+		defer close(ch)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var last *EnvironmentState
+		for {
+			current := d.Detect()
+			if last == nil || !reflect.DeepEqual(*last, *current) {
+				select {
+				case ch <- *current:
+					last = current
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return ch, nil
+}
@@ -0,0 +1,21 @@
+package symlink
+
+// SymlinkManager handles host-side operations on the workspace _docs symlink.
+// Creation happens inside the container via DockerManager.SetupWorkspaceSymlink.
+type SymlinkManager interface {
+	// Path returns the _docs symlink path for a workspace.
+	Path(workspacePath string) string
+
+	// Exists reports whether anything exists at the workspace's _docs path.
+	Exists(workspacePath string) bool
+
+	// IsManagedSymlink reports whether _docs is a symlink capsule created into the volume's repos/.
+	IsManagedSymlink(workspacePath, volumeMountPoint string) (bool, error)
+
+	// Remove deletes the _docs symlink, refusing to remove a real file or directory.
+	Remove(workspacePath string) error
+
+	// RemoveOrphan removes a dangling _docs symlink found by FindOrphanSymlinks,
+	// refusing links that aren't capsule-managed or have changed since.
+	RemoveOrphan(orphan OrphanSymlink) error
+}
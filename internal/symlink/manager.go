@@ -0,0 +1,135 @@
+package symlink
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+	"github.com/jeanhaley32/claude-capsule/internal/docslink"
+)
+
+// ErrUnrecognizedTarget is returned by RepoIDFromSymlink when the _docs
+// symlink does not point at <volume>/repos/<repoID>, e.g. a link the user
+// created themselves.
+var ErrUnrecognizedTarget = errors.New("symlink target is not a volume repo directory")
+
+// Manager handles host-side operations on the workspace _docs symlink.
+// The symlink itself is created inside the container by setup-workspace-symlink.sh.
+type Manager struct {
+	linkName string
+}
+
+// NewManager creates a new symlink manager for the default _docs link.
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// NewManagerWithLinkName creates a symlink manager for a workspace link with
+// the given name (see docslink.Validate); empty selects the default.
+func NewManagerWithLinkName(name string) (*Manager, error) {
+	if err := docslink.Validate(name); err != nil {
+		return nil, err
+	}
+	return &Manager{linkName: name}, nil
+}
+
+// Path returns the _docs symlink path for a workspace.
+func (m *Manager) Path(workspacePath string) string {
+	return filepath.Join(workspacePath, docslink.Name(m.linkName))
+}
+
+// Exists reports whether anything exists at the workspace's _docs path,
+// including a broken symlink.
+func (m *Manager) Exists(workspacePath string) bool {
+	_, err := os.Lstat(m.Path(workspacePath))
+	return err == nil
+}
+
+// IsManagedSymlink reports whether the workspace's _docs is a symlink created
+// by capsule, i.e. one pointing at a repo directory under repos/ in the volume.
+// The container creates links to /claude-env/repos/<id>, so that target counts
+// as well as <volumeMountPoint>/repos/<id> on the host. It returns false, not an
+// error, if _docs is missing, a real directory, or a symlink pointing elsewhere.
+func (m *Manager) IsManagedSymlink(workspacePath, volumeMountPoint string) (bool, error) {
+	linkPath := m.Path(workspacePath)
+
+	info, err := os.Lstat(linkPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to stat %s: %w", linkPath, err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return false, nil
+	}
+
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read symlink %s: %w", linkPath, err)
+	}
+	return docslink.IsManagedTarget(target, workspacePath, volumeMountPoint), nil
+}
+
+// RepoIDFromSymlink reads the workspace's _docs symlink and splits its target
+// <volumeMountPoint>/repos/<repoID> into its parts, to show where a
+// workspace's docs actually live. Links created inside the container point at
+// /claude-env/repos/<repoID>, so volumeMountPoint is then
+// constants.ContainerVolumePath rather than a host path. Targets of any other
+// shape wrap ErrUnrecognizedTarget. linkName is the symlink's name; empty
+// selects the default.
+func RepoIDFromSymlink(workspacePath, linkName string) (repoID, volumeMountPoint string, err error) {
+	linkPath := filepath.Join(workspacePath, docslink.Name(linkName))
+
+	info, err := os.Lstat(linkPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to stat %s: %w", linkPath, err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return "", "", fmt.Errorf("%s is not a symlink", linkPath)
+	}
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read symlink %s: %w", linkPath, err)
+	}
+
+	resolved := target
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(workspacePath, resolved)
+	}
+	resolved = filepath.Clean(resolved)
+
+	reposDir := filepath.Dir(resolved)
+	repoID = filepath.Base(resolved)
+	volumeMountPoint = filepath.Dir(reposDir)
+	if filepath.Base(reposDir) != constants.ReposDirName || volumeMountPoint == "/" || repoID == constants.ReposDirName {
+		return "", "", fmt.Errorf("%w: %s -> %s", ErrUnrecognizedTarget, linkPath, target)
+	}
+	return repoID, volumeMountPoint, nil
+}
+
+// Remove deletes the _docs symlink from the workspace.
+// It returns nil if the symlink does not exist, and refuses to remove a
+// real file or directory so user data is never deleted.
+func (m *Manager) Remove(workspacePath string) error {
+	linkPath := m.Path(workspacePath)
+
+	info, err := os.Lstat(linkPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to stat %s: %w", linkPath, err)
+	}
+
+	if info.Mode()&os.ModeSymlink == 0 {
+		return fmt.Errorf("refusing to remove %s: not a symlink", linkPath)
+	}
+
+	if err := os.Remove(linkPath); err != nil {
+		return fmt.Errorf("failed to remove symlink %s: %w", linkPath, err)
+	}
+	return nil
+}
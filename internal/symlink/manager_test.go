@@ -0,0 +1,132 @@
+package symlink
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsManagedSymlink(t *testing.T) {
+	m := NewManager()
+	mountPoint := "/Volumes/Capsule-abc"
+
+	tests := []struct {
+		name   string
+		target string // Empty for a real directory
+		want   bool
+	}{
+		{"container target", "/claude-env/repos/project", true},
+		{"host target", "/Volumes/Capsule-abc/repos/project", true},
+		{"repos root", "/claude-env/repos", false},
+		{"user link", "/home/me/notes", false},
+		{"escaping target", "/claude-env/repos/../home", false},
+		{"real directory", "", false},
+	}
+	for _, tt := range tests {
+		workspace := t.TempDir()
+		if tt.target == "" {
+			if err := os.Mkdir(m.Path(workspace), 0755); err != nil {
+				t.Fatal(err)
+			}
+		} else if err := os.Symlink(tt.target, m.Path(workspace)); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := m.IsManagedSymlink(workspace, mountPoint)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: IsManagedSymlink() = %v, %v, want %v", tt.name, got, err, tt.want)
+		}
+	}
+
+	if got, err := m.IsManagedSymlink(filepath.Join(t.TempDir(), "none"), mountPoint); got || err != nil {
+		t.Errorf("missing _docs: IsManagedSymlink() = %v, %v, want false, nil", got, err)
+	}
+}
+
+func TestRepoIDFromSymlink(t *testing.T) {
+	tests := []struct {
+		target     string
+		wantID     string
+		wantVolume string // Empty when the target is unrecognized
+	}{
+		{"/claude-env/repos/github.com-user-project", "github.com-user-project", "/claude-env"},
+		{"/Volumes/Capsule-abc/repos/project/", "project", "/Volumes/Capsule-abc"},
+		{"/claude-env/repos", "", ""},
+		{"/claude-env/repos/project/sub", "", ""},
+		{"/claude-env/repos/../home", "", ""},
+		{"/repos/project", "", ""},
+		{"notes", "", ""},
+	}
+	for _, tt := range tests {
+		workspace := t.TempDir()
+		if err := os.Symlink(tt.target, filepath.Join(workspace, "_docs")); err != nil {
+			t.Fatal(err)
+		}
+		id, volume, err := RepoIDFromSymlink(workspace, "")
+		if tt.wantVolume == "" {
+			if !errors.Is(err, ErrUnrecognizedTarget) {
+				t.Errorf("RepoIDFromSymlink(%q) = %q, %q, %v, want ErrUnrecognizedTarget", tt.target, id, volume, err)
+			}
+			continue
+		}
+		if err != nil || id != tt.wantID || volume != tt.wantVolume {
+			t.Errorf("RepoIDFromSymlink(%q) = %q, %q, %v, want %q, %q", tt.target, id, volume, err, tt.wantID, tt.wantVolume)
+		}
+	}
+
+	if _, _, err := RepoIDFromSymlink(t.TempDir(), ""); err == nil {
+		t.Error("RepoIDFromSymlink() succeeded without a symlink")
+	}
+}
+
+func TestFindOrphanSymlinks(t *testing.T) {
+	root, mountPoint := t.TempDir(), t.TempDir()
+	if err := os.MkdirAll(filepath.Join(mountPoint, "repos", "live"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"live":          "/claude-env/repos/live",
+		"gone":          "/claude-env/repos/gone",
+		"user":          filepath.Join(root, "missing-notes"),
+		"a/b/c/d/e/far": "/claude-env/repos/gone",
+		".hidden/x":     "/claude-env/repos/gone",
+	}
+	for dir, target := range links {
+		workspace := filepath.Join(root, dir)
+		if err := os.MkdirAll(workspace, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(target, filepath.Join(workspace, "_docs")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	orphans, err := findOrphanSymlinks([]string{root}, "_docs", []string{mountPoint}, MaxOrphanSearchDepth)
+	if err != nil {
+		t.Fatalf("findOrphanSymlinks() error = %v", err)
+	}
+	found := map[string]bool{}
+	for _, o := range orphans {
+		rel, _ := filepath.Rel(root, o.Workspace)
+		found[rel] = o.Managed
+	}
+	if len(found) != 2 || found["gone"] != true || found["user"] != false {
+		t.Errorf("orphans = %+v, want managed gone and unmanaged user", orphans)
+	}
+
+	if orphans, _ := findOrphanSymlinks([]string{root}, "_docs", nil, MaxOrphanSearchDepth); len(orphans) != 1 {
+		t.Errorf("orphans without mounted volumes = %+v, want only the host target", orphans)
+	}
+
+	m := NewManager()
+	for _, o := range orphans {
+		err := m.RemoveOrphan(o)
+		if o.Managed != (err == nil) {
+			t.Errorf("RemoveOrphan(%+v) error = %v", o, err)
+		}
+	}
+	if m.Exists(filepath.Join(root, "gone")) || !m.Exists(filepath.Join(root, "user")) {
+		t.Error("RemoveOrphan() removed the wrong links")
+	}
+}
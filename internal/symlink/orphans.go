@@ -0,0 +1,132 @@
+package symlink
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+	"github.com/jeanhaley32/claude-capsule/internal/docslink"
+	"github.com/jeanhaley32/claude-capsule/internal/volume"
+)
+
+// MaxOrphanSearchDepth is how many directory levels below each root
+// FindOrphanSymlinks descends looking for workspaces.
+const MaxOrphanSearchDepth = 4
+
+// OrphanSymlink is a _docs symlink whose target no longer exists.
+type OrphanSymlink struct {
+	Workspace string // Directory containing the _docs symlink
+	Target    string // Dangling target, as stored in the link
+	Managed   bool   // Whether the link points into a volume's repos/ (see IsManagedSymlink)
+}
+
+// FindOrphanSymlinks walks each root up to MaxOrphanSearchDepth levels deep and
+// returns the _docs symlinks whose targets don't resolve. In-container targets
+// (/claude-env/repos/<id>) are resolved against the capsule volumes mounted on
+// the host; while none is mounted they can't be checked and are skipped. Hidden
+// directories are not searched. linkName is the symlink name to look for; empty
+// selects the default. Unreadable directories are skipped and reported
+// in the returned error alongside the orphans that were found.
+func FindOrphanSymlinks(workspaceRoots []string, linkName string) ([]OrphanSymlink, error) {
+	return findOrphanSymlinks(workspaceRoots, docslink.Name(linkName), volume.ListMountPoints(), MaxOrphanSearchDepth)
+}
+
+func findOrphanSymlinks(roots []string, linkName string, mountPoints []string, maxDepth int) ([]OrphanSymlink, error) {
+	var orphans []OrphanSymlink
+	var errs []error
+	for _, root := range roots {
+		root = filepath.Clean(root)
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				errs = append(errs, err)
+				if d != nil && d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if !d.IsDir() {
+				if d.Name() == linkName && d.Type()&fs.ModeSymlink != 0 {
+					if orphan, ok := checkOrphan(p, mountPoints); ok {
+						orphans = append(orphans, orphan)
+					}
+				}
+				return nil
+			}
+			if p != root && (strings.HasPrefix(d.Name(), ".") || strings.Count(strings.TrimPrefix(p, root), string(filepath.Separator)) > maxDepth) {
+				return fs.SkipDir
+			}
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return orphans, errors.Join(errs...)
+}
+
+// checkOrphan reports whether the _docs symlink at linkPath is dangling.
+func checkOrphan(linkPath string, mountPoints []string) (OrphanSymlink, bool) {
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		return OrphanSymlink{}, false
+	}
+	workspace := filepath.Dir(linkPath)
+	resolved := target
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(workspace, resolved)
+	}
+	resolved = filepath.Clean(resolved)
+
+	containerRepos := path.Join(constants.ContainerVolumePath, constants.ReposDirName)
+	if rel, ok := strings.CutPrefix(resolved, containerRepos+"/"); ok {
+		if len(mountPoints) == 0 {
+			return OrphanSymlink{}, false
+		}
+		for _, mountPoint := range mountPoints {
+			if _, err := os.Stat(filepath.Join(mountPoint, constants.ReposDirName, rel)); err == nil {
+				return OrphanSymlink{}, false
+			}
+		}
+		return OrphanSymlink{Workspace: workspace, Target: target, Managed: true}, true
+	}
+
+	if _, err := os.Stat(resolved); err == nil {
+		return OrphanSymlink{}, false
+	}
+	return OrphanSymlink{Workspace: workspace, Target: target, Managed: isVolumeReposPath(resolved)}, true
+}
+
+// isVolumeReposPath reports whether p is inside repos/ of a capsule mount point.
+func isVolumeReposPath(p string) bool {
+	for dir := filepath.Dir(p); dir != "/" && dir != "."; dir = filepath.Dir(dir) {
+		if filepath.Base(dir) == constants.ReposDirName && volume.IsCapsuleMount(filepath.Dir(dir)) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveOrphan removes an orphaned _docs symlink found by FindOrphanSymlinks.
+// It refuses links that aren't capsule-managed or that changed since they were
+// found, so a link the user created or repointed is never removed.
+func (m *Manager) RemoveOrphan(orphan OrphanSymlink) error {
+	if !orphan.Managed {
+		return fmt.Errorf("refusing to remove %s: not a capsule-managed symlink", m.Path(orphan.Workspace))
+	}
+	target, err := os.Readlink(m.Path(orphan.Workspace))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read symlink %s: %w", m.Path(orphan.Workspace), err)
+	}
+	if target != orphan.Target {
+		return fmt.Errorf("refusing to remove %s: target changed to %s", m.Path(orphan.Workspace), target)
+	}
+	return m.Remove(orphan.Workspace)
+}
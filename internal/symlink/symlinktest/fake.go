@@ -0,0 +1,76 @@
+// Package symlinktest provides an in-memory symlink.SymlinkManager for tests.
+package symlinktest
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/jeanhaley32/claude-capsule/internal/docslink"
+	"github.com/jeanhaley32/claude-capsule/internal/symlink"
+)
+
+// Fake is an in-memory SymlinkManager. Workspaces listed in Links have a _docs
+// symlink; workspaces listed in Dirs have a real _docs directory that Remove refuses
+// to delete. It never touches the filesystem.
+type Fake struct {
+	mu      sync.Mutex
+	Links   map[string]bool
+	Dirs    map[string]bool
+	Removed []string // Workspaces whose symlink was removed, in order
+
+	// LinkName is the symlink name used by Path; empty selects the default.
+	LinkName string
+}
+
+// NewFake creates a fake with a _docs symlink in each of the given workspaces.
+func NewFake(workspacePaths ...string) *Fake {
+	f := &Fake{Links: map[string]bool{}, Dirs: map[string]bool{}}
+	for _, w := range workspacePaths {
+		f.Links[w] = true
+	}
+	return f
+}
+
+// Path returns the _docs symlink path for a workspace.
+func (f *Fake) Path(workspacePath string) string {
+	return filepath.Join(workspacePath, docslink.Name(f.LinkName))
+}
+
+// Exists reports whether the workspace has a _docs symlink or directory.
+func (f *Fake) Exists(workspacePath string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Links[workspacePath] || f.Dirs[workspacePath]
+}
+
+// IsManagedSymlink reports whether the workspace has a _docs symlink. Every
+// fake link counts as capsule-managed.
+func (f *Fake) IsManagedSymlink(workspacePath, volumeMountPoint string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Links[workspacePath], nil
+}
+
+// Remove deletes the workspace's symlink and records the call.
+func (f *Fake) Remove(workspacePath string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Dirs[workspacePath] {
+		return fmt.Errorf("refusing to remove %s: not a symlink", f.Path(workspacePath))
+	}
+	if f.Links[workspacePath] {
+		delete(f.Links, workspacePath)
+		f.Removed = append(f.Removed, workspacePath)
+	}
+	return nil
+}
+
+// RemoveOrphan removes the orphan's workspace symlink like Remove, refusing
+// orphans that aren't capsule-managed.
+func (f *Fake) RemoveOrphan(orphan symlink.OrphanSymlink) error {
+	if !orphan.Managed {
+		return fmt.Errorf("refusing to remove %s: not a capsule-managed symlink", f.Path(orphan.Workspace))
+	}
+	return f.Remove(orphan.Workspace)
+}
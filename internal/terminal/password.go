@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strings"
 	"syscall"
 
 	"golang.org/x/term"
@@ -102,12 +101,46 @@ func ReadPasswordConfirmSecure(prompt, confirmPrompt string) (*SecurePassword, e
 // ReadPasswordFromStdinSecure reads a password from stdin and returns a SecurePassword.
 func ReadPasswordFromStdinSecure() (*SecurePassword, error) {
 	reader := bufio.NewReader(os.Stdin)
-	password, err := reader.ReadString('\n')
-	if err != nil {
+	password, err := reader.ReadBytes('\n')
+	if err != nil && !(err == io.EOF && len(password) > 0) {
 		return nil, fmt.Errorf("failed to read password from stdin: %w", err)
 	}
-	trimmed := strings.TrimSuffix(password, "\n")
-	return &SecurePassword{data: []byte(trimmed)}, nil
+	return &SecurePassword{data: trimNewline(password)}, nil
+}
+
+// ReadPasswordFromFileSecure reads a password from the first line of a file.
+// The file must not be readable by group or others (e.g. mode 0600).
+func ReadPasswordFromFileSecure(path string) (*SecurePassword, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read password file: %w", err)
+	}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		return nil, fmt.Errorf("password file %s has mode %#o; restrict it to its owner (chmod 600)", path, perm)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read password file: %w", err)
+	}
+	line := data
+	if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+		line = data[:idx+1]
+	}
+	password := append([]byte(nil), trimNewline(line)...)
+	for i := range data {
+		data[i] = 0
+	}
+	if len(password) == 0 {
+		return nil, fmt.Errorf("password file %s is empty", path)
+	}
+	return &SecurePassword{data: password}, nil
+}
+
+// trimNewline strips a trailing "\n" or "\r\n".
+func trimNewline(b []byte) []byte {
+	b = bytes.TrimSuffix(b, []byte("\n"))
+	return bytes.TrimSuffix(b, []byte("\r"))
 }
 
 // ReadPasswordFromEnvSecure reads the password from CAPSULE_PASSWORD and returns a SecurePassword.
@@ -120,23 +153,35 @@ func ReadPasswordFromEnvSecure() *SecurePassword {
 	return &SecurePassword{data: []byte(env)}
 }
 
-// ReadPasswordMultiSourceSecure attempts to read password from multiple sources.
+// PasswordSources selects where ReadPasswordFromSources reads the password.
+type PasswordSources struct {
+	File  string // Path to a file holding the password; see ReadPasswordFromFileSecure
+	Stdin bool   // Read from stdin (for piped input)
+}
+
+// ReadPasswordFromSources reads the password from the first available source,
+// in order: File, Stdin, the CAPSULE_PASSWORD environment variable, and finally
+// an interactive terminal prompt. The password is never echoed.
 // The caller must call Clear() on the returned password when done.
-// Sources checked in order:
-// 1. If useStdin is true, read from stdin (for piped input)
-// 2. Check CAPSULE_PASSWORD environment variable
-// 3. Fall back to interactive terminal prompt
-func ReadPasswordMultiSourceSecure(useStdin bool, prompt string) (*SecurePassword, error) {
-	// Option 1: Read from stdin if flag is set
-	if useStdin {
+func ReadPasswordFromSources(sources PasswordSources, prompt string) (*SecurePassword, error) {
+	if sources.File != "" {
+		return ReadPasswordFromFileSecure(sources.File)
+	}
+	if sources.Stdin {
 		return ReadPasswordFromStdinSecure()
 	}
-
-	// Option 2: Check environment variable
 	if envPassword := ReadPasswordFromEnvSecure(); envPassword != nil {
 		return envPassword, nil
 	}
-
-	// Option 3: Interactive terminal prompt
 	return ReadPasswordSecure(prompt)
 }
+
+// ReadPasswordMultiSourceSecure attempts to read password from multiple sources.
+// The caller must call Clear() on the returned password when done.
+// Sources checked in order:
+// 1. If useStdin is true, read from stdin (for piped input)
+// 2. Check CAPSULE_PASSWORD environment variable
+// 3. Fall back to interactive terminal prompt
+func ReadPasswordMultiSourceSecure(useStdin bool, prompt string) (*SecurePassword, error) {
+	return ReadPasswordFromSources(PasswordSources{Stdin: useStdin}, prompt)
+}
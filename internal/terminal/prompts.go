@@ -78,3 +78,35 @@ func PromptIntWithDefault(question string, defaultVal int) (int, error) {
 		return num, nil
 	}
 }
+
+// PromptConfirm asks a yes/no question and returns the answer. Pressing Enter
+// selects defaultYes. Without a terminal it returns false without asking, so
+// scripts must opt in explicitly (e.g. with a --yes flag).
+func PromptConfirm(question string, defaultYes bool) (bool, error) {
+	if !IsTerminal() {
+		return false, nil
+	}
+
+	hint := "y/N"
+	if defaultYes {
+		hint = "Y/n"
+	}
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("%s [%s]: ", question, hint)
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return false, fmt.Errorf("failed to read input: %w", err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(input)) {
+		case "":
+			return defaultYes, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Println("Please answer y or n")
+	}
+}
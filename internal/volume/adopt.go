@@ -0,0 +1,224 @@
+package volume
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+	"github.com/jeanhaley32/claude-capsule/internal/docslink"
+)
+
+// AdoptExistingDocs moves the contents of a real _docs directory in the
+// workspace into repos/<repoID> in the mounted volume, then replaces it with
+// the _docs symlink the container would create. It does nothing if _docs is
+// missing or already a symlink. It refuses if repos/<repoID> already has
+// content; use AdoptExistingDocsMerge to combine them. linkName is the
+// workspace symlink's name; empty selects the default.
+func AdoptExistingDocs(workspacePath, linkName, volumeMountPoint, repoID string) error {
+	return adoptExistingDocs(workspacePath, linkName, volumeMountPoint, repoID, false)
+}
+
+// AdoptExistingDocsMerge is like AdoptExistingDocs but merges into existing
+// repo docs. Files present in both must be identical; otherwise nothing is
+// moved and the conflicting paths are reported.
+func AdoptExistingDocsMerge(workspacePath, linkName, volumeMountPoint, repoID string) error {
+	return adoptExistingDocs(workspacePath, linkName, volumeMountPoint, repoID, true)
+}
+
+func adoptExistingDocs(workspacePath, linkName, volumeMountPoint, repoID string, merge bool) error {
+	if workspacePath == "" || volumeMountPoint == "" {
+		return fmt.Errorf("workspace path and volume mount point are required")
+	}
+	if err := ValidateRepoID(repoID); err != nil {
+		return err
+	}
+
+	docsPath := filepath.Join(workspacePath, docslink.Name(linkName))
+	info, err := os.Lstat(docsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to stat %s: %w", docsPath, err)
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return nil // Already adopted
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", docsPath)
+	}
+
+	targetPath := RepoDocsPath(volumeMountPoint, repoID)
+	skip, err := planAdoption(docsPath, targetPath, merge)
+	if err != nil {
+		return err
+	}
+	if err := copyDocsTree(docsPath, targetPath, skip); err != nil {
+		return err
+	}
+
+	// Everything is in the volume now; swap the directory for the symlink.
+	// The symlink is created under a temporary name and renamed into place,
+	// as setup-workspace-symlink.sh does.
+	linkTarget := path.Join(constants.ContainerVolumePath, constants.ReposDirName, repoID)
+	tempLink, err := tempSymlink(linkTarget, docsPath)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(docsPath); err != nil {
+		os.Remove(tempLink)
+		return fmt.Errorf("docs copied to %s but failed to remove %s: %w", targetPath, docsPath, err)
+	}
+	if err := os.Rename(tempLink, docsPath); err != nil {
+		os.Remove(tempLink)
+		return fmt.Errorf("docs copied to %s but failed to create symlink %s: %w", targetPath, docsPath, err)
+	}
+	return nil
+}
+
+// maxTempSymlinkAttempts bounds retries when a temporary link name is taken.
+const maxTempSymlinkAttempts = 10
+
+// tempSymlink creates a symlink to target at a random name beside linkPath
+// and returns that name. Random rather than pid-based names keep concurrent
+// goroutines in one process from colliding.
+func tempSymlink(target, linkPath string) (string, error) {
+	for i := 0; i < maxTempSymlinkAttempts; i++ {
+		suffix := make([]byte, 6)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", fmt.Errorf("failed to generate temporary name: %w", err)
+		}
+		tempLink := linkPath + ".tmp." + hex.EncodeToString(suffix)
+		err := os.Symlink(target, tempLink)
+		if err == nil {
+			return tempLink, nil
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("failed to create symlink: %w", err)
+		}
+	}
+	return "", fmt.Errorf("failed to create symlink: no free temporary name beside %s", linkPath)
+}
+
+// planAdoption checks that src can be copied into dst and returns the relative
+// paths that already exist identically in dst and can be skipped.
+func planAdoption(src, dst string, merge bool) (map[string]bool, error) {
+	entries, err := os.ReadDir(dst)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", dst, err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	if !merge {
+		return nil, fmt.Errorf("%s already has docs (use merge to combine)", dst)
+	}
+
+	srcFiles, err := walkDocs(src)
+	if err != nil {
+		return nil, err
+	}
+	dstFiles, err := walkDocs(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[string]bool)
+	var conflicts []string
+	for rel, a := range srcFiles {
+		b, ok := dstFiles[rel]
+		if !ok {
+			if _, err := os.Lstat(filepath.Join(dst, rel)); err == nil {
+				conflicts = append(conflicts, rel) // A directory in dst
+			}
+			continue
+		}
+		same, err := sameDocsFile(filepath.Join(src, rel), a, filepath.Join(dst, rel), b)
+		if err != nil {
+			return nil, err
+		}
+		if !same {
+			conflicts = append(conflicts, rel)
+			continue
+		}
+		skip[rel] = true
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, fmt.Errorf("cannot merge %s into %s: %d conflicting files: %s",
+			src, dst, len(conflicts), strings.Join(conflicts, ", "))
+	}
+	return skip, nil
+}
+
+// copyDocsTree copies files, directories and symlinks from src into dst,
+// preserving file permissions and never overwriting. Paths in skip are ignored.
+func copyDocsTree(src, dst string, skip map[string]bool) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case d.IsDir():
+			if err := os.MkdirAll(target, constants.DirPermissions); err != nil {
+				return fmt.Errorf("failed to create %s: %w", target, err)
+			}
+		case skip[rel]:
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return fmt.Errorf("failed to read symlink %s: %w", p, err)
+			}
+			if err := os.Symlink(link, target); err != nil {
+				return fmt.Errorf("failed to create symlink %s: %w", target, err)
+			}
+		case d.Type().IsRegular():
+			if err := copyNewFile(p, target); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("cannot adopt %s: not a regular file, directory or symlink", p)
+		}
+		return nil
+	})
+}
+
+// copyNewFile copies a regular file to a path that must not exist yet.
+func copyNewFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", src, err)
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+	return nil
+}
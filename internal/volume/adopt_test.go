@@ -0,0 +1,76 @@
+package volume
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+func TestAdoptExistingDocs(t *testing.T) {
+	workspace, mountPoint := t.TempDir(), t.TempDir()
+	docs := filepath.Join(workspace, constants.DocsSymlinkName)
+	writeFile(t, filepath.Join(docs, "notes.md"), "notes")
+	writeFile(t, filepath.Join(docs, "design", "api.md"), "api")
+
+	repoDocs := RepoDocsPath(mountPoint, "project")
+	writeFile(t, filepath.Join(repoDocs, "notes.md"), "other notes")
+
+	if err := AdoptExistingDocs(workspace, "", mountPoint, "project"); err == nil {
+		t.Fatal("AdoptExistingDocs() accepted existing repo docs without merge")
+	}
+	if err := AdoptExistingDocsMerge(workspace, "", mountPoint, "project"); err == nil {
+		t.Fatal("AdoptExistingDocsMerge() accepted a conflicting file")
+	}
+	if _, err := os.Stat(filepath.Join(repoDocs, "design", "api.md")); !os.IsNotExist(err) {
+		t.Error("failed merge copied files")
+	}
+
+	writeFile(t, filepath.Join(repoDocs, "notes.md"), "notes")
+	if err := AdoptExistingDocsMerge(workspace, "", mountPoint, "project"); err != nil {
+		t.Fatalf("AdoptExistingDocsMerge() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(repoDocs, "design", "api.md")); err != nil || string(data) != "api" {
+		t.Errorf("adopted file = %q, %v", data, err)
+	}
+	target, err := os.Readlink(docs)
+	if err != nil || target != "/claude-env/repos/project" {
+		t.Errorf("_docs link = %q, %v", target, err)
+	}
+
+	// Already a symlink: nothing to do
+	if err := AdoptExistingDocs(workspace, "", mountPoint, "project"); err != nil {
+		t.Errorf("AdoptExistingDocs() on symlink error = %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdoptExistingDocs_ReplacesDirWithLink(t *testing.T) {
+	workspace, mountPoint := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(workspace, constants.DocsSymlinkName, "notes.md"), "notes")
+
+	if err := AdoptExistingDocs(workspace, "", mountPoint, "project"); err != nil {
+		t.Fatalf("AdoptExistingDocs() error = %v", err)
+	}
+	link := filepath.Join(workspace, constants.DocsSymlinkName)
+	if target, err := os.Readlink(link); err != nil || target != "/claude-env/repos/project" {
+		t.Errorf("link = %q, %v", target, err)
+	}
+	entries, err := os.ReadDir(workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("workspace has %d entries, want only the link (temporary links leaked)", len(entries))
+	}
+}
@@ -0,0 +1,303 @@
+package volume
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// BundleFormatVersion is the version of the bundle layout written by
+// ExportBundle. ImportBundle rejects bundles with any other version.
+const BundleFormatVersion = 1
+
+// Bundle entry names. The manifest is written last so its checksums can be
+// computed while the other entries stream into the archive.
+const (
+	bundleConfigEntry   = "config.json"
+	bundleVolumeDir     = "volume"
+	bundleManifestEntry = "manifest.json"
+)
+
+// maxBundleMetadataSize bounds the config and manifest entries read into memory.
+const maxBundleMetadataSize = 1 << 20
+
+// ErrBundleCorrupt is returned by ImportBundle when the archive is incomplete
+// or its contents do not match the manifest checksums.
+var ErrBundleCorrupt = errors.New("bundle is corrupt")
+
+// bundleManifest records the format version and the SHA-256 of every file.
+type bundleManifest struct {
+	FormatVersion int
+	Checksums     map[string]string // Entry name -> hex SHA-256
+}
+
+// ExportBundle writes configData (the output of config.Config.Dump, stored as
+// is) and the volume image at volumePath to a single tar archive at destPath,
+// for moving a whole capsule setup to another machine. The volume must be
+// unmounted, and destPath must not exist. The image is still encrypted inside
+// the bundle.
+func (m *MacOSVolumeManager) ExportBundle(configData []byte, volumePath, destPath string) (err error) {
+	if len(configData) == 0 {
+		return fmt.Errorf("bundle configuration is empty")
+	}
+	if _, err := os.Stat(volumePath); err != nil {
+		return fmt.Errorf("volume not found at %s: %w", volumePath, err)
+	}
+
+	release, err := m.lockVolumes()
+	if err != nil {
+		return err
+	}
+	defer release()
+	if mountPoint := m.findMountPointForVolume(volumePath); mountPoint != "" {
+		return fmt.Errorf("volume is mounted at %s; unmount it before exporting", mountPoint)
+	}
+
+	f, err := os.OpenFile(destPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, constants.FilePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(destPath)
+		}
+	}()
+
+	tw := tar.NewWriter(f)
+	manifest := bundleManifest{FormatVersion: BundleFormatVersion, Checksums: map[string]string{}}
+
+	if err := writeBundleFile(tw, manifest.Checksums, bundleConfigEntry, int64(len(configData)), constants.FilePermissions, bytes.NewReader(configData)); err != nil {
+		return err
+	}
+
+	volumeRoot := path.Join(bundleVolumeDir, filepath.Base(volumePath))
+	err = filepath.WalkDir(volumePath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(volumePath, p)
+		if err != nil {
+			return err
+		}
+		name := path.Join(volumeRoot, filepath.ToSlash(rel))
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: name + "/", Mode: int64(info.Mode().Perm())})
+		case info.Mode().IsRegular():
+			src, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+			return writeBundleFile(tw, manifest.Checksums, name, info.Size(), info.Mode().Perm(), src)
+		default:
+			return fmt.Errorf("unsupported file type in volume: %s", p)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive volume: %w", err)
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: bundleManifestEntry, Size: int64(len(manifestData)), Mode: int64(constants.FilePermissions)}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifestData); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// writeBundleFile adds a regular file to the archive and records its checksum.
+func writeBundleFile(tw *tar.Writer, checksums map[string]string, name string, size int64, perm os.FileMode, r io.Reader) error {
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Size: size, Mode: int64(perm)}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tw, h), r); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	checksums[name] = hex.EncodeToString(h.Sum(nil))
+	return nil
+}
+
+// ImportBundle restores a bundle written by ExportBundle into volumeDir on
+// this machine: the volume image keeps its file name, and the restored path
+// must not already exist. It returns the stored configuration data, for the
+// caller to parse with config.ParseDump, point at volumePath, and save. The
+// format version and every checksum are verified before the volume is moved
+// into place; mismatches wrap ErrBundleCorrupt.
+func (m *MacOSVolumeManager) ImportBundle(srcPath, volumeDir string) (configData []byte, volumePath string, err error) {
+	if volumeDir == "" {
+		return nil, "", fmt.Errorf("volume directory is required")
+	}
+	f, err := os.Open(srcPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+	tr := tar.NewReader(f)
+
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != bundleConfigEntry {
+		return nil, "", fmt.Errorf("%w: missing %s", ErrBundleCorrupt, bundleConfigEntry)
+	}
+	checksums := map[string]string{}
+	configData, err = readBundleFile(tr, checksums, hdr.Name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	release, err := m.lockVolumes()
+	if err != nil {
+		return nil, "", err
+	}
+	defer release()
+
+	if err := os.MkdirAll(volumeDir, constants.DirPermissions); err != nil {
+		return nil, "", fmt.Errorf("failed to create %s: %w", volumeDir, err)
+	}
+	// Stage in the destination directory so the final move is a rename
+	staging, err := os.MkdirTemp(volumeDir, ".capsule-import-")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	var manifest *bundleManifest
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrBundleCorrupt, err)
+		}
+		if manifest != nil {
+			return nil, "", fmt.Errorf("%w: unexpected %s after manifest", ErrBundleCorrupt, hdr.Name)
+		}
+
+		if hdr.Name == bundleManifestEntry {
+			data, err := io.ReadAll(io.LimitReader(tr, maxBundleMetadataSize))
+			if err != nil {
+				return nil, "", fmt.Errorf("%w: %v", ErrBundleCorrupt, err)
+			}
+			manifest = &bundleManifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, "", fmt.Errorf("%w: invalid manifest: %v", ErrBundleCorrupt, err)
+			}
+			continue
+		}
+		if err := extractBundleEntry(tr, hdr, staging, checksums); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if manifest == nil {
+		return nil, "", fmt.Errorf("%w: missing %s", ErrBundleCorrupt, bundleManifestEntry)
+	}
+	if manifest.FormatVersion != BundleFormatVersion {
+		return nil, "", fmt.Errorf("unsupported bundle format version %d (want %d)", manifest.FormatVersion, BundleFormatVersion)
+	}
+	if err := verifyBundleChecksums(manifest.Checksums, checksums); err != nil {
+		return nil, "", err
+	}
+
+	// The bundle holds exactly one volume image, under its original name
+	entries, err := os.ReadDir(filepath.Join(staging, bundleVolumeDir))
+	if err != nil || len(entries) != 1 || !isVolumeExtension(entries[0].Name()) {
+		return nil, "", fmt.Errorf("%w: bundle must contain exactly one volume image", ErrBundleCorrupt)
+	}
+	volumePath = filepath.Join(volumeDir, entries[0].Name())
+	if _, err := os.Lstat(volumePath); err == nil {
+		return nil, "", fmt.Errorf("volume already exists at %s", volumePath)
+	}
+	if err := os.Rename(filepath.Join(staging, bundleVolumeDir, entries[0].Name()), volumePath); err != nil {
+		return nil, "", fmt.Errorf("failed to restore volume: %w", err)
+	}
+	return configData, volumePath, nil
+}
+
+// extractBundleEntry writes one volume entry under staging, refusing names
+// that would land outside it.
+func extractBundleEntry(tr *tar.Reader, hdr *tar.Header, staging string, checksums map[string]string) error {
+	name := path.Clean(hdr.Name)
+	if !strings.HasPrefix(name, bundleVolumeDir+"/") || path.IsAbs(name) {
+		return fmt.Errorf("%w: unexpected entry %s", ErrBundleCorrupt, hdr.Name)
+	}
+	dst := filepath.Join(staging, filepath.FromSlash(name))
+
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(dst, constants.DirPermissions)
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(dst), constants.DirPermissions); err != nil {
+			return err
+		}
+		out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, os.FileMode(hdr.Mode).Perm())
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", dst, err)
+		}
+		h := sha256.New()
+		_, err = io.Copy(io.MultiWriter(out, h), tr)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", dst, err)
+		}
+		checksums[hdr.Name] = hex.EncodeToString(h.Sum(nil))
+		return nil
+	default:
+		return fmt.Errorf("%w: unsupported entry type for %s", ErrBundleCorrupt, hdr.Name)
+	}
+}
+
+// readBundleFile reads a small archive entry into memory and records its checksum.
+func readBundleFile(tr *tar.Reader, checksums map[string]string, name string) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(tr, maxBundleMetadataSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read %s: %v", ErrBundleCorrupt, name, err)
+	}
+	sum := sha256.Sum256(data)
+	checksums[name] = hex.EncodeToString(sum[:])
+	return data, nil
+}
+
+// verifyBundleChecksums checks the files read match the manifest exactly.
+func verifyBundleChecksums(want, got map[string]string) error {
+	for name, sum := range want {
+		if got[name] != sum {
+			return fmt.Errorf("%w: checksum mismatch for %s", ErrBundleCorrupt, name)
+		}
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			return fmt.Errorf("%w: %s is not in the manifest", ErrBundleCorrupt, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package volume
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	volumePath := filepath.Join(dir, "capsule.sparsebundle")
+	writeFile(t, filepath.Join(volumePath, "Info.plist"), "plist")
+	writeFile(t, filepath.Join(volumePath, "bands", "0"), "encrypted")
+
+	configData := []byte("{\"RepoID\": \"github.com-user-repo\"}\n")
+	m := NewMacOSVolumeManagerWithRunner(&recordingRunner{})
+	bundle := filepath.Join(dir, "capsule.tar")
+	if err := m.ExportBundle(configData, volumePath, bundle); err != nil {
+		t.Fatalf("ExportBundle() error = %v", err)
+	}
+
+	if _, _, err := m.ImportBundle(bundle, dir); err == nil {
+		t.Error("ImportBundle() overwrote an existing volume")
+	}
+
+	// Restore into a different volume directory, as on another machine
+	otherDir := filepath.Join(t.TempDir(), "volumes")
+	gotConfig, gotPath, err := m.ImportBundle(bundle, otherDir)
+	if err != nil {
+		t.Fatalf("ImportBundle() error = %v", err)
+	}
+	restored := filepath.Join(otherDir, "capsule.sparsebundle")
+	if gotPath != restored || !bytes.Equal(gotConfig, configData) {
+		t.Errorf("ImportBundle() = %q, %q, want %q and the exported config", gotConfig, gotPath, restored)
+	}
+	if data, err := os.ReadFile(filepath.Join(restored, "bands", "0")); err != nil || string(data) != "encrypted" {
+		t.Errorf("restored band = %q, %v", data, err)
+	}
+
+	// Flip a byte of the archived band
+	os.RemoveAll(restored)
+	data, _ := os.ReadFile(bundle)
+	i := bytes.Index(data, []byte("encrypted"))
+	data[i] = 'E'
+	os.WriteFile(bundle, data, 0600)
+	if _, _, err := m.ImportBundle(bundle, otherDir); !errors.Is(err, ErrBundleCorrupt) {
+		t.Errorf("ImportBundle(tampered) error = %v, want ErrBundleCorrupt", err)
+	}
+	if _, err := os.Stat(restored); !os.IsNotExist(err) {
+		t.Error("ImportBundle(tampered) restored the volume")
+	}
+}
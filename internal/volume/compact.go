@@ -0,0 +1,83 @@
+package volume
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jeanhaley32/claude-capsule/internal/terminal"
+)
+
+// compactTimeout bounds hdiutil compact, which rewrites the image's free bands.
+const compactTimeout = 10 * time.Minute
+
+// CompactVolume runs `hdiutil compact` to return space freed inside the volume
+// to the host filesystem, since sparse images never shrink on their own. The
+// volume must be unmounted. It returns the number of bytes reclaimed.
+func (m *MacOSVolumeManager) CompactVolume(volumePath string, password *terminal.SecurePassword) (int64, error) {
+	if !m.Exists(volumePath) {
+		return 0, fmt.Errorf("volume not found at %s", volumePath)
+	}
+	if password == nil || password.Len() == 0 {
+		return 0, fmt.Errorf("password is required")
+	}
+	release, err := m.lockVolumes()
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	if mountPoint := m.findMountPointForVolume(volumePath); mountPoint != "" {
+		return 0, fmt.Errorf("volume is mounted at %s; unmount it before compacting", mountPoint)
+	}
+
+	before, err := diskUsage(volumePath)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), compactTimeout)
+	defer cancel()
+
+	output, err := m.combinedOutput(ctx, password.Reader(), "hdiutil", "compact", "-stdinpass", volumePath)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return 0, fmt.Errorf("volume compaction timed out after %v", compactTimeout)
+		}
+		if strings.Contains(string(output), "Authentication error") {
+			return 0, ErrWrongPassword
+		}
+		return 0, fmt.Errorf("failed to compact volume: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	after, err := diskUsage(volumePath)
+	if err != nil {
+		return 0, err
+	}
+	return max(before-after, 0), nil
+}
+
+// diskUsage returns the size of a volume image, summing the bands of a sparse bundle.
+func diskUsage(volumePath string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(volumePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure %s: %w", volumePath, err)
+	}
+	return total, nil
+}
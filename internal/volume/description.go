@@ -0,0 +1,59 @@
+package volume
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// descriptionSuffix names the sidecar file holding a volume's description.
+// It sits next to the image so it survives unmount and is readable while locked.
+const descriptionSuffix = ".description"
+
+// maxDescriptionLength bounds volume descriptions.
+const maxDescriptionLength = 1024
+
+// VolumeInfo describes a volume image found by ListVolumes.
+type VolumeInfo struct {
+	Path        string
+	Description string
+}
+
+// SetVolumeDescription stores a human-readable description alongside the volume.
+// An empty description removes it.
+func SetVolumeDescription(volumePath, desc string) error {
+	if _, err := os.Stat(volumePath); err != nil {
+		return fmt.Errorf("volume not found at %s: %w", volumePath, err)
+	}
+
+	desc = strings.TrimSpace(desc)
+	descPath := volumePath + descriptionSuffix
+	if desc == "" {
+		if err := os.Remove(descPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove description: %w", err)
+		}
+		return nil
+	}
+	if len(desc) > maxDescriptionLength {
+		return fmt.Errorf("description too long: %d characters (max %d)", len(desc), maxDescriptionLength)
+	}
+
+	if err := os.WriteFile(descPath, []byte(desc+"\n"), constants.PublicFilePermissions); err != nil {
+		return fmt.Errorf("failed to write description: %w", err)
+	}
+	return nil
+}
+
+// VolumeDescription returns the volume's description, or "" if none is set.
+func VolumeDescription(volumePath string) (string, error) {
+	data, err := os.ReadFile(volumePath + descriptionSuffix)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read description: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
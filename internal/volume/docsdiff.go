@@ -0,0 +1,169 @@
+package volume
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// maxDiffFiles caps how many files DiffRepoDocs visits per side.
+const maxDiffFiles = 10000
+
+// ErrDiffTooLarge is returned by DiffRepoDocs when a docs tree has more than
+// maxDiffFiles files. The entries found up to that point are still returned.
+var ErrDiffTooLarge = errors.New("docs tree too large to diff")
+
+// DiffKind classifies a DiffEntry.
+type DiffKind string
+
+const (
+	DiffOnlyInA DiffKind = "only-in-a"
+	DiffOnlyInB DiffKind = "only-in-b"
+	DiffDiffers DiffKind = "differs"
+)
+
+// DiffEntry is a file that differs between two repo docs directories.
+type DiffEntry struct {
+	Path string // Relative to the repo docs directory
+	Kind DiffKind
+}
+
+// docsFile is a file found while walking a docs tree.
+type docsFile struct {
+	size int64
+	link bool
+}
+
+// DiffRepoDocs compares repos/<idA> and repos/<idB> in a mounted volume and
+// reports files only in A, only in B, or differing by size or content hash,
+// sorted by path. A missing docs directory counts as empty. Use it to preview
+// what MergeRepoDocs would do. Returns no entries if the volume is not mounted.
+func DiffRepoDocs(volumeMountPoint, idA, idB string) ([]DiffEntry, error) {
+	if volumeMountPoint == "" {
+		return nil, fmt.Errorf("volume mount point is required")
+	}
+	if err := ValidateRepoID(idA); err != nil {
+		return nil, err
+	}
+	if err := ValidateRepoID(idB); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(volumeMountPoint); os.IsNotExist(err) {
+		return nil, nil // Not mounted, nothing to compare
+	}
+
+	dirA, dirB := RepoDocsPath(volumeMountPoint, idA), RepoDocsPath(volumeMountPoint, idB)
+	filesA, errA := walkDocs(dirA)
+	filesB, errB := walkDocs(dirB)
+	for _, err := range []error{errA, errB} {
+		if err != nil && !errors.Is(err, ErrDiffTooLarge) {
+			return nil, err
+		}
+	}
+	limitErr := errors.Join(errA, errB)
+
+	var entries []DiffEntry
+	for rel, a := range filesA {
+		b, ok := filesB[rel]
+		if !ok {
+			entries = append(entries, DiffEntry{Path: rel, Kind: DiffOnlyInA})
+			continue
+		}
+		same, err := sameDocsFile(filepath.Join(dirA, rel), a, filepath.Join(dirB, rel), b)
+		if err != nil {
+			return nil, err
+		}
+		if !same {
+			entries = append(entries, DiffEntry{Path: rel, Kind: DiffDiffers})
+		}
+	}
+	for rel := range filesB {
+		if _, ok := filesA[rel]; !ok {
+			entries = append(entries, DiffEntry{Path: rel, Kind: DiffOnlyInB})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, limitErr
+}
+
+// walkDocs returns the files and symlinks under dir keyed by relative path.
+func walkDocs(dir string) (map[string]docsFile, error) {
+	files := make(map[string]docsFile)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if len(files) >= maxDiffFiles {
+			return fmt.Errorf("%w: %s has more than %d files", ErrDiffTooLarge, dir, maxDiffFiles)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[rel] = docsFile{size: info.Size(), link: d.Type()&fs.ModeSymlink != 0}
+		return nil
+	})
+	return files, err
+}
+
+// sameDocsFile compares two files by type, size, and then content hash.
+// Symlinks are compared by target.
+func sameDocsFile(pathA string, a docsFile, pathB string, b docsFile) (bool, error) {
+	if a.link != b.link || a.size != b.size {
+		return false, nil
+	}
+	if a.link {
+		targetA, err := os.Readlink(pathA)
+		if err != nil {
+			return false, err
+		}
+		targetB, err := os.Readlink(pathB)
+		if err != nil {
+			return false, err
+		}
+		return targetA == targetB, nil
+	}
+
+	hashA, err := hashFile(pathA)
+	if err != nil {
+		return false, err
+	}
+	hashB, err := hashFile(pathB)
+	if err != nil {
+		return false, err
+	}
+	return hashA == hashB, nil
+}
+
+// hashFile returns the SHA-256 of a file's contents.
+func hashFile(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
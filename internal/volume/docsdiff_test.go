@@ -0,0 +1,91 @@
+package volume
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffRepoDocs(t *testing.T) {
+	mountPoint := t.TempDir()
+	write := func(repoID, rel, content string) {
+		path := filepath.Join(RepoDocsPath(mountPoint, repoID), rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("old", "README.md", "same")
+	write("old", "notes/a.md", "only old")
+	write("old", "design.md", "v1")
+	write("new", "README.md", "same")
+	write("new", "design.md", "v2")
+	write("new", "notes/b.md", "only new")
+
+	got, err := DiffRepoDocs(mountPoint, "old", "new")
+	if err != nil {
+		t.Fatalf("DiffRepoDocs() error = %v", err)
+	}
+	want := []DiffEntry{
+		{Path: "design.md", Kind: DiffDiffers},
+		{Path: filepath.Join("notes", "a.md"), Kind: DiffOnlyInA},
+		{Path: filepath.Join("notes", "b.md"), Kind: DiffOnlyInB},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffRepoDocs() = %v, want %v", got, want)
+	}
+
+	// A missing side is treated as empty
+	got, err = DiffRepoDocs(mountPoint, "old", "missing")
+	if err != nil || len(got) != 3 {
+		t.Errorf("DiffRepoDocs(missing) = %v, %v, want 3 only-in-a entries", got, err)
+	}
+}
+
+func TestSyncRepoDocs(t *testing.T) {
+	mountPoint, dest := t.TempDir(), t.TempDir()
+	docs := RepoDocsPath(mountPoint, "project")
+	if err := os.MkdirAll(filepath.Join(docs, "notes"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(docs, "notes", "a.md")
+	if err := os.WriteFile(src, []byte("notes"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(src, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	for _, rel := range []string{"stale.md", ".git/HEAD"} {
+		path := filepath.Join(dest, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := SyncRepoDocs(mountPoint, "project", dest, true); err != nil {
+		t.Fatalf("SyncRepoDocs() error = %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dest, "notes", "a.md"))
+	if err != nil || !info.ModTime().Equal(modTime) {
+		t.Errorf("synced file = %v, %v, want mod time %v", info, err, modTime)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "stale.md")); !os.IsNotExist(err) {
+		t.Errorf("stale.md not deleted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, ".git", "HEAD")); err != nil {
+		t.Errorf(".git was deleted: %v", err)
+	}
+
+	if err := SyncRepoDocs(filepath.Join(mountPoint, "missing"), "project", dest, false); !errors.Is(err, ErrNotMounted) {
+		t.Errorf("SyncRepoDocs(unmounted) error = %v, want ErrNotMounted", err)
+	}
+}
@@ -0,0 +1,51 @@
+package volume
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// FindDuplicateRepoIDs scans the repos/ directory of every mounted capsule
+// volume and returns the repo IDs found in more than one, mapped to the mount
+// points holding them. A _docs symlink for such a repo shows whichever volume
+// the container was started with, so docs can end up split between volumes;
+// DiffRepoDocs and MergeRepoDocs help reconcile them.
+func FindDuplicateRepoIDs() (map[string][]string, error) {
+	return findDuplicateRepoIDs(ListMountPoints())
+}
+
+// findDuplicateRepoIDs is FindDuplicateRepoIDs over the given mount points.
+// Unreadable mounts are skipped and reported in the joined error.
+func findDuplicateRepoIDs(mountPoints []string) (map[string][]string, error) {
+	found := make(map[string][]string)
+	var errs []error
+	for _, mountPoint := range mountPoints {
+		reposDir := filepath.Join(mountPoint, constants.ReposDirName)
+		entries, err := os.ReadDir(reposDir)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("failed to read %s: %w", reposDir, err))
+			}
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				found[entry.Name()] = append(found[entry.Name()], mountPoint)
+			}
+		}
+	}
+
+	duplicates := make(map[string][]string)
+	for repoID, mounts := range found {
+		if len(mounts) > 1 {
+			sort.Strings(mounts)
+			duplicates[repoID] = mounts
+		}
+	}
+	return duplicates, errors.Join(errs...)
+}
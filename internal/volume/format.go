@@ -0,0 +1,114 @@
+package volume
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VolumeFormat is the on-disk format of a volume image.
+type VolumeFormat string
+
+const (
+	// FormatSparseImage is a single-file sparse image (.sparseimage). It is the default.
+	FormatSparseImage VolumeFormat = "sparseimage"
+	// FormatSparseBundle is a directory of fixed-size bands (.sparsebundle), which
+	// cloud-sync and backup tools can copy incrementally.
+	FormatSparseBundle VolumeFormat = "sparsebundle"
+)
+
+// Validate checks that the format is known. Empty means FormatSparseImage.
+func (f VolumeFormat) Validate() error {
+	switch f {
+	case "", FormatSparseImage, FormatSparseBundle:
+		return nil
+	}
+	return fmt.Errorf("invalid volume format %q: must be %s or %s", f, FormatSparseImage, FormatSparseBundle)
+}
+
+// orDefault returns the format, or FormatSparseImage if empty.
+func (f VolumeFormat) orDefault() VolumeFormat {
+	if f == "" {
+		return FormatSparseImage
+	}
+	return f
+}
+
+// Extension returns the file extension hdiutil uses for the format.
+func (f VolumeFormat) Extension() string {
+	return "." + string(f.orDefault())
+}
+
+// hdiutilType returns the `hdiutil create -type` value for the format.
+func (f VolumeFormat) hdiutilType() string {
+	if f.orDefault() == FormatSparseBundle {
+		return "SPARSEBUNDLE"
+	}
+	return "SPARSE"
+}
+
+// WithFormatExtension returns volumePath with its extension replaced by the
+// format's, e.g. capsule.sparseimage -> capsule.sparsebundle.
+func WithFormatExtension(volumePath string, format VolumeFormat) string {
+	return strings.TrimSuffix(volumePath, filepath.Ext(volumePath)) + format.Extension()
+}
+
+// DetectVolumeFormat reports the format of an existing volume from its
+// structure: a sparse bundle is a directory, a sparse image a regular file.
+// For a path that doesn't exist, the format is taken from its extension.
+func DetectVolumeFormat(volumePath string) (VolumeFormat, error) {
+	info, err := os.Stat(volumePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to stat %s: %w", volumePath, err)
+		}
+		switch filepath.Ext(volumePath) {
+		case FormatSparseBundle.Extension():
+			return FormatSparseBundle, nil
+		case FormatSparseImage.Extension():
+			return FormatSparseImage, nil
+		}
+		return "", fmt.Errorf("cannot determine format of %s", volumePath)
+	}
+	if info.IsDir() {
+		return FormatSparseBundle, nil
+	}
+	return FormatSparseImage, nil
+}
+
+// isVolumeExtension reports whether name has the extension of a volume format.
+func isVolumeExtension(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == FormatSparseImage.Extension() || ext == FormatSparseBundle.Extension()
+}
+
+// AmbiguousVolumeError is returned when a directory holds the capsule volume
+// in both formats, so it is unclear which one holds the user's data.
+type AmbiguousVolumeError struct {
+	Paths []string
+}
+
+func (e *AmbiguousVolumeError) Error() string {
+	return fmt.Sprintf("found more than one volume: %s; remove or rename all but one, or choose one with --volume", strings.Join(e.Paths, ", "))
+}
+
+// existingVolumeIn returns the path of the capsule volume in dir, as a sparse
+// image or a sparse bundle of the same name. ok is false if neither exists.
+// If both exist it returns *AmbiguousVolumeError rather than guess.
+func existingVolumeIn(dir, fileName string) (volumePath string, ok bool, err error) {
+	var found []string
+	for _, format := range []VolumeFormat{FormatSparseImage, FormatSparseBundle} {
+		candidate := filepath.Join(dir, WithFormatExtension(fileName, format))
+		if _, err := os.Stat(candidate); err == nil {
+			found = append(found, candidate)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return filepath.Join(dir, fileName), false, nil
+	case 1:
+		return found[0], true, nil
+	}
+	return "", false, &AmbiguousVolumeError{Paths: found}
+}
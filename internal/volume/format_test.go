@@ -0,0 +1,47 @@
+package volume
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+func TestVolumeFormat(t *testing.T) {
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "capsule.sparsebundle")
+	if err := os.Mkdir(bundle, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if f, err := DetectVolumeFormat(bundle); err != nil || f != FormatSparseBundle {
+		t.Errorf("DetectVolumeFormat(bundle) = %q, %v", f, err)
+	}
+	if f, err := DetectVolumeFormat(filepath.Join(dir, "new.sparseimage")); err != nil || f != FormatSparseImage {
+		t.Errorf("DetectVolumeFormat(missing image) = %q, %v", f, err)
+	}
+	if got := WithFormatExtension("/v/capsule.sparseimage", FormatSparseBundle); got != "/v/capsule.sparsebundle" {
+		t.Errorf("WithFormatExtension() = %q", got)
+	}
+
+	// Resolution finds a bundle when there is no sparse image
+	resolver := &PathResolver{homeDir: t.TempDir()}
+	if got, exists, err := resolver.ResolveVolumePath("", dir); err != nil || !exists || got != bundle {
+		t.Errorf("ResolveVolumePath() = %q, %v, %v, want %q", got, exists, err, bundle)
+	}
+
+	// Both formats side by side are refused rather than guessed between
+	if err := os.WriteFile(filepath.Join(dir, constants.MacOSVolumeFile), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	var ambiguous *AmbiguousVolumeError
+	if _, err := resolver.ResolveVolumePathStrict("", dir); !errors.As(err, &ambiguous) || len(ambiguous.Paths) != 2 {
+		t.Errorf("ResolveVolumePathStrict() with both formats error = %v, want *AmbiguousVolumeError", err)
+	}
+
+	if err := VolumeFormat("dmg").Validate(); err == nil {
+		t.Error("Validate() accepted an unknown format")
+	}
+}
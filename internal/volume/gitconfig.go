@@ -0,0 +1,72 @@
+package volume
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// VolumeHomeDir is the directory inside the volume used as $HOME in the container.
+const VolumeHomeDir = "home"
+
+// SeedGitConfig copies the host's git identity into the volume home so commits
+// work inside the container. It copies ~/.gitconfig and ~/.config/git/config,
+// and, if includeCredentials is set, ~/.git-credentials. Missing files are skipped.
+//
+// SSH private keys are never copied; forward the SSH agent into the container instead.
+func SeedGitConfig(volumeMountPoint string, includeCredentials bool) error {
+	if volumeMountPoint == "" {
+		return fmt.Errorf("volume mount point is required")
+	}
+
+	hostHome, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+	volumeHome := filepath.Join(volumeMountPoint, VolumeHomeDir)
+
+	files := []string{
+		".gitconfig",
+		filepath.Join(".config", "git", "config"),
+	}
+	if includeCredentials {
+		files = append(files, ".git-credentials")
+	}
+
+	for _, rel := range files {
+		// Git config may embed tokens or credential helper settings, so keep everything private
+		if err := copyFileIfExists(filepath.Join(hostHome, rel), filepath.Join(volumeHome, rel), constants.FilePermissions); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyFileIfExists copies src to dst with the given permissions, creating parent
+// directories as needed. It does nothing if src does not exist.
+func copyFileIfExists(src, dst string, perm os.FileMode) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), constants.DirPermissions); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", dst, err)
+	}
+
+	if err := os.WriteFile(dst, data, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+	// WriteFile does not change the mode of an existing file
+	if err := os.Chmod(dst, perm); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", dst, err)
+	}
+
+	return nil
+}
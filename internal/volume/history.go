@@ -0,0 +1,47 @@
+package volume
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// FishHistoryPath is the path within the encrypted volume to the fish shell history.
+const FishHistoryPath = "home/.local/share/fish/fish_history"
+
+// ErrNoHistory is returned when the volume has no shell history yet.
+var ErrNoHistory = errors.New("no shell history found in volume")
+
+// ExportHistory copies the fish shell history from a mounted volume to a host path.
+// The container does not need to be running; only the volume must be mounted.
+func ExportHistory(volumeMountPoint, destPath string) error {
+	if volumeMountPoint == "" {
+		return fmt.Errorf("volume mount point is required")
+	}
+	if destPath == "" {
+		return fmt.Errorf("destination path is required")
+	}
+
+	historyPath := filepath.Join(volumeMountPoint, FishHistoryPath)
+	data, err := os.ReadFile(historyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ErrNoHistory
+		}
+		return fmt.Errorf("failed to read history %s: %w", historyPath, err)
+	}
+
+	// History may contain secrets typed at the prompt, so keep it private
+	if err := os.WriteFile(destPath, data, constants.FilePermissions); err != nil {
+		return fmt.Errorf("failed to write history to %s: %w", destPath, err)
+	}
+	// WriteFile does not change the mode of an existing file
+	if err := os.Chmod(destPath, constants.FilePermissions); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", destPath, err)
+	}
+
+	return nil
+}
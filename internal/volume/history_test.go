@@ -0,0 +1,38 @@
+package volume
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+func TestExportHistory(t *testing.T) {
+	mountPoint := t.TempDir()
+	dest := filepath.Join(t.TempDir(), "fish_history")
+
+	if err := ExportHistory(mountPoint, dest); !errors.Is(err, ErrNoHistory) {
+		t.Errorf("ExportHistory() without history error = %v, want ErrNoHistory", err)
+	}
+
+	writeFile(t, filepath.Join(mountPoint, FishHistoryPath), "- cmd: export TOKEN=secret")
+	// An existing destination keeps its mode under WriteFile, so it must be tightened
+	if err := os.WriteFile(dest, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExportHistory(mountPoint, dest); err != nil {
+		t.Fatalf("ExportHistory() error = %v", err)
+	}
+	info, err := os.Stat(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != constants.FilePermissions {
+		t.Errorf("exported history mode = %v, want %v", info.Mode().Perm(), constants.FilePermissions)
+	}
+	if data, err := os.ReadFile(dest); err != nil || string(data) != "- cmd: export TOKEN=secret" {
+		t.Errorf("exported history = %q, %v", data, err)
+	}
+}
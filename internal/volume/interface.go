@@ -2,6 +2,7 @@ package volume
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/jeanhaley32/claude-capsule/internal/constants"
 	"github.com/jeanhaley32/claude-capsule/internal/terminal"
@@ -14,6 +15,10 @@ type BootstrapConfig struct {
 	Password     *terminal.SecurePassword
 	ContextFiles []string // Markdown files to extend Claude context
 	Version      string   // Capsule version for tracking installed components
+
+	// Format is the image format to create. Defaults to FormatSparseImage.
+	// VolumePath must carry the format's extension (see WithFormatExtension).
+	Format VolumeFormat
 }
 
 // Validate checks that the bootstrap configuration is valid.
@@ -28,6 +33,14 @@ func (c *BootstrapConfig) Validate() error {
 	if c.Password == nil || c.Password.Len() == 0 {
 		return fmt.Errorf("password is required")
 	}
+	if err := c.Format.Validate(); err != nil {
+		return err
+	}
+	// hdiutil appends the extension if it is missing, which would put the
+	// volume somewhere other than VolumePath
+	if ext := filepath.Ext(c.VolumePath); ext != c.Format.Extension() {
+		return fmt.Errorf("volume path %s must end in %s for format %s", c.VolumePath, c.Format.Extension(), c.Format.orDefault())
+	}
 	return nil
 }
 
@@ -40,12 +53,34 @@ type VolumeManager interface {
 	// The caller should clear the password after Mount returns.
 	Mount(volumePath string, password *terminal.SecurePassword) (mountPoint string, err error)
 
+	// MountForRepo is like Mount but uses the repository's mount point, MountPointForVolume(repoID).
+	MountForRepo(volumePath, repoID string, password *terminal.SecurePassword) (mountPoint string, err error)
+
+	// MountWithProgress is like Mount or MountForRepo but reports each phase on events.
+	MountWithProgress(volumePath string, password *terminal.SecurePassword, opts MountProgressOptions, events chan<- MountEvent) (mountPoint string, err error)
+
 	// Unmount unmounts and closes the encrypted volume.
 	Unmount(mountPoint string) error
 
+	// UnmountWithProgress is like Unmount but reports each phase on events.
+	UnmountWithProgress(mountPoint string, events chan<- MountEvent) error
+
 	// Exists checks if a volume file exists at the given path.
 	Exists(volumePath string) bool
 
 	// GetMountPoint returns the mount point for the specified volume if mounted, empty string otherwise.
 	GetMountPoint(volumePath string) string
+
+	// VerifyPassword checks that the password unlocks the volume without leaving it mounted.
+	// Returns ErrWrongPassword if the password is rejected.
+	VerifyPassword(volumePath string, password *terminal.SecurePassword) error
+
+	// SnapshotVolume takes an APFS snapshot of the mounted volume under name.
+	SnapshotVolume(mountPoint, name string) error
+
+	// CompactVolume reclaims free space inside an unmounted volume, returning the bytes reclaimed.
+	CompactVolume(volumePath string, password *terminal.SecurePassword) (int64, error)
+
+	// CheckDiskImageAccess checks this account can create, attach, and detach disk images.
+	CheckDiskImageAccess() error
 }
@@ -0,0 +1,45 @@
+package volume
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+	"github.com/jeanhaley32/claude-capsule/internal/filelock"
+)
+
+// volumeLockTimeout bounds the wait for the volume lock. Mounts finish within
+// seconds, but a concurrent compaction can hold the lock for minutes.
+const volumeLockTimeout = time.Minute
+
+// ErrVolumeBusy is returned when another capsule process is attaching,
+// detaching or compacting a volume and the lock was not released in time.
+var ErrVolumeBusy = errors.New("another capsule volume operation is in progress")
+
+// lockVolumes takes the machine-wide volume operation lock, so concurrent
+// hdiutil attach/detach calls from different capsule processes cannot race
+// for the same /Volumes mount point. Call the returned func, typically with
+// defer, to release it. Managers built with a custom runner or for dry runs
+// do not touch real volumes and skip the lock.
+func (m *MacOSVolumeManager) lockVolumes() (release func(), err error) {
+	if !m.live {
+		return func() {}, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	lockPath := filepath.Join(homeDir, constants.CapsuleConfigDir, constants.LocksSubdir, constants.VolumeLockFileName)
+	lock, err := filelock.Acquire(lockPath, volumeLockTimeout)
+	if errors.Is(err, filelock.ErrTimeout) {
+		return nil, fmt.Errorf("%w (waited %v)", ErrVolumeBusy, volumeLockTimeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return lock.Release, nil
+}
@@ -1,34 +1,104 @@
 package volume
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
-	"github.com/jeanhaley32/claude-capsule/internal/config"
 	"github.com/jeanhaley32/claude-capsule/internal/constants"
 	"github.com/jeanhaley32/claude-capsule/internal/embedded"
 	"github.com/jeanhaley32/claude-capsule/internal/terminal"
 )
 
-// mountPointPrefix is the prefix for mount points in /Volumes (standard macOS location)
-const mountPointPrefix = "/Volumes/Capsule-"
+// MountPointPrefix is the prefix for mount points in /Volumes (standard macOS location).
+// It is the source of truth for the mount-point convention; use MountPointForVolume and
+// IsCapsuleMount rather than splitting it by hand.
+const MountPointPrefix = "/Volumes/Capsule-"
+
+// maxVolumeNameLength keeps Capsule-<name> within the 255-byte limit on a
+// path component.
+const maxVolumeNameLength = 255 - len("Capsule-")
+
+// validVolumeName is the character set accepted for volume names.
+var validVolumeName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// ValidateVolumeName checks that name can follow the Capsule- prefix in a
+// mount point: lowercase letters, digits, '.', '_' and '-', starting with a
+// letter or digit. Spaces are excluded because macOS resolves mount point
+// collisions by appending " 1", and uppercase because /Volumes is
+// case-insensitive, so "Foo" and "foo" would share a mount point.
+func ValidateVolumeName(name string) error {
+	if name == "" {
+		return fmt.Errorf("volume name cannot be empty")
+	}
+	if len(name) > maxVolumeNameLength {
+		return fmt.Errorf("volume name too long: %d characters (max %d)", len(name), maxVolumeNameLength)
+	}
+	if !validVolumeName.MatchString(name) {
+		return fmt.Errorf("invalid volume name %q: must be lowercase [a-z0-9._-] and start with a letter or digit", name)
+	}
+	return nil
+}
+
+// MountPointForVolume returns the mount point for the volume with the given
+// name (the part after the Capsule- prefix). The name is lowercased, so names
+// that differ only in case map to the same mount point, as they would on the
+// case-insensitive /Volumes anyway.
+func MountPointForVolume(name string) string {
+	return MountPointPrefix + strings.ToLower(name)
+}
+
+// IsCapsuleMount reports whether path is a capsule mount point: a direct child
+// of /Volumes named Capsule-<name>.
+func IsCapsuleMount(path string) bool {
+	path = filepath.Clean(path)
+	name := strings.TrimPrefix(path, MountPointPrefix)
+	return name != path && name != "" && !strings.Contains(name, "/")
+}
+
+// mountPointsDir returns the directory holding capsule mount points.
+func mountPointsDir() string {
+	return filepath.Dir(MountPointPrefix)
+}
 
 // Timeout for volume operations (hdiutil can be slow for large volumes)
 const volumeOperationTimeout = 5 * time.Minute
 
+// ErrWrongPassword is returned when the volume password is rejected.
+var ErrWrongPassword = errors.New("wrong volume password")
+
 // MacOSVolumeManager implements VolumeManager using hdiutil for macOS.
-type MacOSVolumeManager struct{}
+type MacOSVolumeManager struct {
+	runner CommandRunner
+	dryRun bool
+	live   bool // Operates on real volumes: takes the machine-wide lock and checks image files
+}
 
 // NewMacOSVolumeManager creates a new macOS volume manager.
 func NewMacOSVolumeManager() *MacOSVolumeManager {
-	return &MacOSVolumeManager{}
+	return &MacOSVolumeManager{runner: ExecRunner{}, live: true}
+}
+
+// NewMacOSVolumeManagerWithRunner creates a macOS volume manager that issues
+// all hdiutil and diskutil commands through the given runner.
+func NewMacOSVolumeManagerWithRunner(runner CommandRunner) *MacOSVolumeManager {
+	return &MacOSVolumeManager{runner: runner}
+}
+
+// NewDryRunVolumeManager creates a macOS volume manager that writes the commands
+// it would run to out instead of running them, and skips all filesystem changes.
+func NewDryRunVolumeManager(out io.Writer) *MacOSVolumeManager {
+	return &MacOSVolumeManager{runner: DryRunRunner{Out: out}, dryRun: true}
 }
 
 func (m *MacOSVolumeManager) Bootstrap(cfg BootstrapConfig) error {
@@ -44,30 +114,40 @@ func (m *MacOSVolumeManager) Bootstrap(cfg BootstrapConfig) error {
 		return fmt.Errorf("volume already exists at %s", volumePath)
 	}
 
+	release, err := m.lockVolumes()
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	// Ensure parent directory exists
 	parentDir := filepath.Dir(volumePath)
-	if err := os.MkdirAll(parentDir, constants.DirPermissions); err != nil {
-		return fmt.Errorf("failed to create parent directory %s: %w", parentDir, err)
+	if !m.dryRun {
+		if err := os.MkdirAll(parentDir, constants.DirPermissions); err != nil {
+			return fmt.Errorf("failed to create parent directory %s: %w", parentDir, err)
+		}
 	}
 
-	// Create encrypted sparse image with timeout
-	// hdiutil create -size <size>g -encryption AES-256 -type SPARSE -fs APFS -volname ClaudeEnv -stdinpass <path>
+	// Create encrypted sparse image or bundle with timeout
+	// hdiutil create -size <size>g -encryption AES-256 -type SPARSE|SPARSEBUNDLE -fs APFS -volname ClaudeEnv -stdinpass <path>
 	ctx, cancel := context.WithTimeout(context.Background(), volumeOperationTimeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "hdiutil", "create",
-		"-size", fmt.Sprintf("%dg", cfg.SizeGB),
-		"-encryption", "AES-256",
-		"-type", "SPARSE",
-		"-fs", "APFS",
-		"-volname", constants.MacOSVolumeName,
-		"-stdinpass",
-		volumePath,
-	)
-	cmd.Stdin = cfg.Password.Reader()
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
+	err = m.runner.Run(ctx, Command{
+		Name: "hdiutil",
+		Args: []string{"create",
+			"-size", fmt.Sprintf("%dg", cfg.SizeGB),
+			"-encryption", "AES-256",
+			"-type", cfg.Format.hdiutilType(),
+			"-fs", "APFS",
+			"-volname", constants.MacOSVolumeName,
+			"-stdinpass",
+			volumePath,
+		},
+		Stdin:  cfg.Password.Reader(),
+		Stderr: os.Stderr,
+	})
+	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			return fmt.Errorf("volume creation timed out after %v", volumeOperationTimeout)
 		}
@@ -75,20 +155,22 @@ func (m *MacOSVolumeManager) Bootstrap(cfg BootstrapConfig) error {
 	}
 
 	// Mount the new volume to create directory structure
-	mountPoint, err := m.Mount(volumePath, cfg.Password)
+	mountPoint, err := m.attachAt(volumePath, m.generateMountPoint(volumePath), cfg.Password, nil)
 	if err != nil {
 		return fmt.Errorf("failed to mount new volume: %w", err)
 	}
 
-	// Create directory structure
-	if err := m.createDirectoryStructure(mountPoint, cfg); err != nil {
-		// Try to unmount even if directory creation fails
-		_ = m.Unmount(mountPoint)
-		return fmt.Errorf("failed to create directory structure: %w", err)
+	// Create directory structure (skipped in dry-run mode, where nothing is mounted)
+	if !m.dryRun {
+		if err := m.createDirectoryStructure(mountPoint, cfg); err != nil {
+			// Try to unmount even if directory creation fails
+			_ = m.unmount(mountPoint, nil)
+			return fmt.Errorf("failed to create directory structure: %w", err)
+		}
 	}
 
 	// Unmount the volume - APFS handles durability, unmount syncs data
-	if err := m.Unmount(mountPoint); err != nil {
+	if err := m.unmount(mountPoint, nil); err != nil {
 		return fmt.Errorf("failed to unmount volume after setup: %w", err)
 	}
 
@@ -96,37 +178,159 @@ func (m *MacOSVolumeManager) Bootstrap(cfg BootstrapConfig) error {
 }
 
 func (m *MacOSVolumeManager) Mount(volumePath string, password *terminal.SecurePassword) (string, error) {
-	// Check if this specific volume is already mounted
-	if mountPoint := m.findMountPointForVolume(volumePath); mountPoint != "" {
-		return mountPoint, nil
-	}
-
 	// Generate a deterministic mount point in /Volumes based on the volume path
 	// Using /Volumes is the standard macOS location and works reliably with Docker Desktop
-	mountPoint := m.generateMountPoint(volumePath)
+	return m.mountAt(volumePath, m.generateMountPoint(volumePath), password, nil)
+}
+
+// MountForRepo is like Mount but mounts at the repository's own mount point,
+// MountPointForVolume(repoID), so callers can tell which repo's volume is mounted.
+// An image can only be attached once, so if the volume is already mounted
+// elsewhere that existing mount point is returned.
+func (m *MacOSVolumeManager) MountForRepo(volumePath, repoID string, password *terminal.SecurePassword) (string, error) {
+	mountPoint, err := repoMountPoint(repoID)
+	if err != nil {
+		return "", err
+	}
+	return m.mountAt(volumePath, mountPoint, password, nil)
+}
+
+// repoMountPoint validates repoID and returns its mount point.
+func repoMountPoint(repoID string) (string, error) {
+	if err := ValidateRepoID(repoID); err != nil {
+		return "", err
+	}
+	if err := ValidateVolumeName(strings.ToLower(repoID)); err != nil {
+		return "", err
+	}
+	return MountPointForVolume(repoID), nil
+}
+
+// mountAt attaches the volume at mountPoint unless it is already mounted,
+// reporting phases to progress if it is non-nil.
+func (m *MacOSVolumeManager) mountAt(volumePath, mountPoint string, password *terminal.SecurePassword, progress *mountProgress) (string, error) {
+	// Catch damaged images before hdiutil reports them obscurely
+	progress.report(PhaseVerifying, mountPoint)
+	if m.live {
+		if err := QuickVerifyVolumeFile(volumePath); err != nil {
+			return "", err
+		}
+	}
+
+	release, err := m.lockVolumes()
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	return m.attachAt(volumePath, mountPoint, password, progress)
+}
+
+// attachAt is mountAt for callers already holding the volume lock.
+func (m *MacOSVolumeManager) attachAt(volumePath, mountPoint string, password *terminal.SecurePassword, progress *mountProgress) (string, error) {
+	// Check if this specific volume is already mounted
+	if existing := m.findMountPointForVolume(volumePath); existing != "" {
+		progress.report(PhaseMounted, existing)
+		return existing, nil
+	}
 
 	// Mount with password via stdin
 	// hdiutil will create the mount point in /Volumes (it has system entitlements to do so)
-	ctx, cancel := context.WithTimeout(context.Background(), volumeOperationTimeout)
+	timeout := progress.attachTimeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "hdiutil", "attach", "-stdinpass", "-mountpoint", mountPoint, volumePath)
-	cmd.Stdin = password.Reader()
-
-	output, err := cmd.CombinedOutput()
+	progress.report(PhaseAttaching, mountPoint)
+	stopRepeat := progress.repeat(PhaseAttaching, mountPoint)
+	output, err := m.combinedOutput(ctx, password.Reader(), "hdiutil", "attach", "-stdinpass", "-mountpoint", mountPoint, volumePath)
+	stopRepeat()
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
-			return "", fmt.Errorf("volume mount timed out after %v", volumeOperationTimeout)
+			return "", fmt.Errorf("volume mount timed out after %v: hdiutil attach stalled; check `hdiutil info` for a stuck attachment of %s", timeout, volumePath)
 		}
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return "", fmt.Errorf("failed to mount volume: %s", string(exitErr.Stderr))
+		if _, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("failed to mount volume: %s", string(output))
 		}
 		return "", fmt.Errorf("failed to mount volume: %w: %s", err, string(output))
 	}
 
+	progress.report(PhaseMounted, mountPoint)
 	return mountPoint, nil
 }
 
+// VerifyPassword checks that the password unlocks the volume without mounting it.
+// It attaches the image with -nomount and immediately detaches it again.
+// Returns ErrWrongPassword if hdiutil reports an authentication failure.
+func (m *MacOSVolumeManager) VerifyPassword(volumePath string, password *terminal.SecurePassword) error {
+	if !m.Exists(volumePath) {
+		return fmt.Errorf("volume not found at %s", volumePath)
+	}
+	if password == nil || password.Len() == 0 {
+		return fmt.Errorf("password is required")
+	}
+
+	release, err := m.lockVolumes()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	// hdiutil reuses an existing attachment, so detaching afterwards would
+	// tear down a live session
+	if mountPoint := m.findMountPointForVolume(volumePath); mountPoint != "" {
+		return fmt.Errorf("volume is already mounted at %s", mountPoint)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), volumeOperationTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	err = m.runner.Run(ctx, Command{
+		Name:   "hdiutil",
+		Args:   []string{"attach", "-nomount", "-stdinpass", volumePath},
+		Stdin:  password.Reader(),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("password verification timed out after %v", volumeOperationTimeout)
+		}
+		if strings.Contains(stderr.String(), "Authentication error") {
+			return ErrWrongPassword
+		}
+		return fmt.Errorf("failed to attach volume: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	device := parseAttachedDevice(stdout.String())
+	if m.dryRun {
+		return nil
+	}
+	if device == "" {
+		return fmt.Errorf("password accepted but could not determine attached device from hdiutil output")
+	}
+
+	detachCtx, detachCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer detachCancel()
+
+	if output, err := m.combinedOutput(detachCtx, nil, "hdiutil", "detach", device); err != nil {
+		return fmt.Errorf("password verified but failed to detach %s: %w: %s", device, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// parseAttachedDevice returns the whole-disk device (e.g. /dev/disk4) from hdiutil attach output.
+// Detaching the whole-disk device also detaches all of its partitions.
+func parseAttachedDevice(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && strings.HasPrefix(fields[0], "/dev/disk") {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
 // generateMountPoint creates a deterministic mount point path based on the volume file path.
 // This ensures the same volume always mounts to the same location, which works better
 // with Docker Desktop's VirtioFS caching.
@@ -134,10 +338,21 @@ func (m *MacOSVolumeManager) generateMountPoint(volumePath string) string {
 	// Hash the volume path to get a deterministic, short identifier
 	hash := sha256.Sum256([]byte(volumePath))
 	shortHash := hex.EncodeToString(hash[:])[:12]
-	return mountPointPrefix + shortHash
+	return MountPointForVolume(shortHash)
 }
 
 func (m *MacOSVolumeManager) Unmount(mountPoint string) error {
+	release, err := m.lockVolumes()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return m.unmount(mountPoint, nil)
+}
+
+// unmount is Unmount for callers already holding the volume lock, reporting
+// phases to progress if it is non-nil.
+func (m *MacOSVolumeManager) unmount(mountPoint string, progress *mountProgress) error {
 	if mountPoint == "" {
 		// If no mount point specified, try to find any mounted claude-env volume
 		mountPoint = m.findAnyMountedVolume()
@@ -153,12 +368,11 @@ func (m *MacOSVolumeManager) Unmount(mountPoint string) error {
 	diskutilCtx, diskutilCancel := context.WithTimeout(context.Background(), unmountTimeout)
 	defer diskutilCancel()
 
-	diskutilCmd := exec.CommandContext(diskutilCtx, "diskutil", "unmount", mountPoint)
-	if err := diskutilCmd.Run(); err == nil {
+	progress.report(PhaseUnmounting, mountPoint)
+	if err := m.run(diskutilCtx, nil, "diskutil", "unmount", mountPoint); err == nil {
 		// diskutil unmount succeeded, clean up mount point directory
-		if strings.HasPrefix(mountPoint, mountPointPrefix) {
-			os.Remove(mountPoint)
-		}
+		m.removeMountPointDir(mountPoint)
+		progress.report(PhaseUnmounted, mountPoint)
 		return nil
 	}
 
@@ -166,14 +380,14 @@ func (m *MacOSVolumeManager) Unmount(mountPoint string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), unmountTimeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "hdiutil", "detach", mountPoint)
-	if err := cmd.Run(); err != nil {
+	progress.report(PhaseDetaching, mountPoint)
+	if err := m.run(ctx, nil, "hdiutil", "detach", mountPoint); err != nil {
 		// Try force detach with fresh context
+		progress.report(PhaseForceDetaching, mountPoint)
 		forceCtx, forceCancel := context.WithTimeout(context.Background(), unmountTimeout)
 		defer forceCancel()
 
-		cmd = exec.CommandContext(forceCtx, "hdiutil", "detach", "-force", mountPoint)
-		if err := cmd.Run(); err != nil {
+		if err := m.run(forceCtx, nil, "hdiutil", "detach", "-force", mountPoint); err != nil {
 			if forceCtx.Err() == context.DeadlineExceeded {
 				return fmt.Errorf("volume unmount timed out after %v (even with force)", unmountTimeout)
 			}
@@ -181,15 +395,22 @@ func (m *MacOSVolumeManager) Unmount(mountPoint string) error {
 		}
 	}
 
-	// Clean up our mount point directory in /tmp
-	// Only remove if it's one of our managed mount points (safety check)
-	if strings.HasPrefix(mountPoint, mountPointPrefix) {
-		os.Remove(mountPoint)
-	}
+	// Clean up our mount point directory
+	m.removeMountPointDir(mountPoint)
+	progress.report(PhaseUnmounted, mountPoint)
 
 	return nil
 }
 
+// removeMountPointDir removes an empty mount point directory left after unmount.
+// Only removes our managed mount points (safety check), and never in dry-run mode.
+func (m *MacOSVolumeManager) removeMountPointDir(mountPoint string) {
+	if m.dryRun || !IsCapsuleMount(mountPoint) {
+		return
+	}
+	os.Remove(mountPoint)
+}
+
 func (m *MacOSVolumeManager) Exists(volumePath string) bool {
 	_, err := os.Stat(volumePath)
 	return err == nil
@@ -197,12 +418,15 @@ func (m *MacOSVolumeManager) Exists(volumePath string) bool {
 
 // createDirectoryStructure creates the required directories inside the mounted volume.
 func (m *MacOSVolumeManager) createDirectoryStructure(mountPoint string, cfg BootstrapConfig) error {
-	for _, dir := range config.VolumeStructure {
+	for _, dir := range constants.VolumeStructure {
 		path := filepath.Join(mountPoint, dir)
 		if err := os.MkdirAll(path, constants.DirPermissions); err != nil {
 			return fmt.Errorf("failed to create directory %s: %w", dir, err)
 		}
 	}
+	if err := InitVolumeLayout(mountPoint); err != nil {
+		return err
+	}
 
 	// Build CLAUDE.md content
 	claudeMDContent := embedded.ClaudeMDTemplate
@@ -253,14 +477,14 @@ Or delete the volume and re-run bootstrap.`, err)
 // This is a fallback for cases where we don't know the specific volume path.
 func (m *MacOSVolumeManager) findAnyMountedVolume() string {
 	// Check for our mount points in /Volumes
-	entries, err := os.ReadDir("/Volumes")
+	entries, err := os.ReadDir(mountPointsDir())
 	if err != nil {
 		return ""
 	}
 
 	for _, entry := range entries {
-		if strings.HasPrefix(entry.Name(), "Capsule-") && entry.IsDir() {
-			mountPoint := filepath.Join("/Volumes", entry.Name())
+		mountPoint := filepath.Join(mountPointsDir(), entry.Name())
+		if IsCapsuleMount(mountPoint) && entry.IsDir() {
 			// Verify it's actually mounted by checking for content
 			contents, err := os.ReadDir(mountPoint)
 			if err == nil && len(contents) > 0 {
@@ -272,6 +496,25 @@ func (m *MacOSVolumeManager) findAnyMountedVolume() string {
 	return ""
 }
 
+// ListMountPoints returns every capsule mount point currently present in /Volumes.
+// Unlike findAnyMountedVolume, it includes mount points whose contents can't be
+// read, so callers can clean up stale mounts too.
+func ListMountPoints() []string {
+	entries, err := os.ReadDir(mountPointsDir())
+	if err != nil {
+		return nil
+	}
+
+	var mountPoints []string
+	for _, entry := range entries {
+		mountPoint := filepath.Join(mountPointsDir(), entry.Name())
+		if IsCapsuleMount(mountPoint) && entry.IsDir() {
+			mountPoints = append(mountPoints, mountPoint)
+		}
+	}
+	return mountPoints
+}
+
 // findMountPointForVolume uses hdiutil info to find the mount point for a specific volume file.
 func (m *MacOSVolumeManager) findMountPointForVolume(volumePath string) string {
 	if volumePath == "" {
@@ -288,8 +531,7 @@ func (m *MacOSVolumeManager) findMountPointForVolume(volumePath string) string {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "hdiutil", "info")
-	output, err := cmd.Output()
+	output, err := m.output(ctx, nil, "hdiutil", "info")
 	if err != nil {
 		return ""
 	}
@@ -318,11 +560,11 @@ func (m *MacOSVolumeManager) findMountPointForVolume(volumePath string) string {
 		}
 
 		// If we found our image, look for mount point in /Volumes
-		if foundOurImage && strings.Contains(line, "/Volumes/Capsule-") {
+		if foundOurImage && strings.Contains(line, MountPointPrefix) {
 			// Line format: "/dev/diskXsY	Apple_APFS	/Volumes/Capsule-xxx"
 			fields := strings.Fields(line)
 			for _, field := range fields {
-				if strings.HasPrefix(field, "/Volumes/Capsule-") {
+				if IsCapsuleMount(field) {
 					return field
 				}
 			}
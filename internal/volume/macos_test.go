@@ -0,0 +1,162 @@
+package volume
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jeanhaley32/claude-capsule/internal/terminal"
+)
+
+// recordingRunner records every command and fails those whose name is in fail.
+type recordingRunner struct {
+	commands []Command
+	fail     map[string]bool
+}
+
+func (r *recordingRunner) Run(ctx context.Context, cmd Command) error {
+	r.commands = append(r.commands, cmd)
+	if r.fail[cmd.Name] {
+		return errors.New("command failed")
+	}
+	return nil
+}
+
+func (r *recordingRunner) lines() []string {
+	var out []string
+	for _, c := range r.commands {
+		out = append(out, c.String())
+	}
+	return out
+}
+
+func TestMountUsesRunner(t *testing.T) {
+	runner := &recordingRunner{}
+	m := NewMacOSVolumeManagerWithRunner(runner)
+
+	mountPoint, err := m.Mount("/tmp/test.sparseimage", &terminal.SecurePassword{})
+	if err != nil {
+		t.Fatalf("Mount() error = %v", err)
+	}
+	if mountPoint != m.generateMountPoint("/tmp/test.sparseimage") {
+		t.Errorf("Mount() = %q, want generated mount point", mountPoint)
+	}
+
+	want := []string{
+		"hdiutil info",
+		"hdiutil attach -stdinpass -mountpoint " + mountPoint + " /tmp/test.sparseimage",
+	}
+	if got := runner.lines(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+}
+
+func TestUnmountFallsBackToHdiutil(t *testing.T) {
+	runner := &recordingRunner{fail: map[string]bool{"diskutil": true}}
+	m := NewMacOSVolumeManagerWithRunner(runner)
+
+	if err := m.Unmount("/Volumes/Other"); err != nil {
+		t.Fatalf("Unmount() error = %v", err)
+	}
+
+	want := []string{
+		"diskutil unmount /Volumes/Other",
+		"hdiutil detach /Volumes/Other",
+	}
+	if got := runner.lines(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+}
+
+func TestDryRunLogsWithoutPassword(t *testing.T) {
+	var out bytes.Buffer
+	m := NewDryRunVolumeManager(&out)
+
+	if _, err := m.Mount("/tmp/test.sparseimage", &terminal.SecurePassword{}); err != nil {
+		t.Fatalf("Mount() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "[dry-run] hdiutil attach -stdinpass") {
+		t.Errorf("dry-run output = %q, want attach command", out.String())
+	}
+}
+
+func TestMountWithProgress(t *testing.T) {
+	m := NewDryRunVolumeManager(io.Discard)
+	collect := func(events chan MountEvent) <-chan []MountPhase {
+		phases := make(chan []MountPhase, 1)
+		go func() {
+			var got []MountPhase
+			for e := range events {
+				got = append(got, e.Phase)
+			}
+			phases <- got
+		}()
+		return phases
+	}
+
+	events := make(chan MountEvent)
+	phases := collect(events)
+	mountPoint, err := m.MountWithProgress("/tmp/test.sparseimage", &terminal.SecurePassword{}, MountProgressOptions{RepoID: "project"}, events)
+	if err != nil || mountPoint != MountPointForVolume("project") {
+		t.Fatalf("MountWithProgress() = %q, %v", mountPoint, err)
+	}
+	if got, want := <-phases, []MountPhase{PhaseVerifying, PhaseAttaching, PhaseMounted}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mount phases = %v, want %v", got, want)
+	}
+
+	events = make(chan MountEvent)
+	phases = collect(events)
+	if err := m.UnmountWithProgress(mountPoint, events); err != nil {
+		t.Fatalf("UnmountWithProgress() error = %v", err)
+	}
+	if got, want := <-phases, []MountPhase{PhaseUnmounting, PhaseUnmounted}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unmount phases = %v, want %v", got, want)
+	}
+
+	// A nil channel reports nothing instead of panicking or blocking
+	if _, err := m.MountWithProgress("/tmp/test.sparseimage", &terminal.SecurePassword{}, MountProgressOptions{}, nil); err != nil {
+		t.Errorf("MountWithProgress(nil) error = %v", err)
+	}
+	if err := m.UnmountWithProgress(mountPoint, nil); err != nil {
+		t.Errorf("UnmountWithProgress(nil) error = %v", err)
+	}
+}
+
+func TestIsCapsuleMount(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{MountPointForVolume("abc123"), true},
+		{MountPointForVolume("abc123") + "/", true},
+		{MountPointPrefix, false},
+		{MountPointForVolume("abc123") + "/home", false},
+		{"/Volumes/Other", false},
+		{"/tmp/Capsule-abc123", false},
+	}
+	for _, tt := range tests {
+		if got := IsCapsuleMount(tt.path); got != tt.want {
+			t.Errorf("IsCapsuleMount(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestValidateVolumeName(t *testing.T) {
+	for _, name := range []string{"abc123", "github.com-user-repo", "a_b"} {
+		if err := ValidateVolumeName(name); err != nil {
+			t.Errorf("ValidateVolumeName(%q) error = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "Repo", "my repo", ".hidden", "-flag", "a/b", strings.Repeat("a", 248)} {
+		if err := ValidateVolumeName(name); err == nil {
+			t.Errorf("ValidateVolumeName(%q) accepted an invalid name", name)
+		}
+	}
+	if MountPointForVolume("Repo") != MountPointForVolume("repo") {
+		t.Error("MountPointForVolume() differs by case")
+	}
+}
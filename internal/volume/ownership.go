@@ -0,0 +1,41 @@
+package volume
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ErrNotMounted is returned when an operation needs a mounted volume.
+var ErrNotMounted = errors.New("volume is not mounted")
+
+// FixHomeOwnership chowns the volume's home directory tree to uid:gid, so a
+// container running as a non-root user can write the HOME that earlier
+// root-run containers created. Symlinks are changed themselves, not followed.
+// The volume must be mounted; ErrNotMounted is returned otherwise.
+func FixHomeOwnership(mountPoint string, uid, gid int) error {
+	if uid < 0 || gid < 0 {
+		return fmt.Errorf("invalid owner %d:%d", uid, gid)
+	}
+	if info, err := os.Stat(mountPoint); err != nil || !info.IsDir() {
+		return fmt.Errorf("%w at %s", ErrNotMounted, mountPoint)
+	}
+
+	home := filepath.Join(mountPoint, VolumeHomeDir)
+	if _, err := os.Lstat(home); os.IsNotExist(err) {
+		return nil
+	}
+
+	err := filepath.WalkDir(home, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, uid, gid)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to change ownership of %s: %w", home, err)
+	}
+	return nil
+}
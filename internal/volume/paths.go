@@ -10,7 +10,8 @@ import (
 
 // PathResolver handles volume path resolution with priority rules.
 type PathResolver struct {
-	homeDir string
+	homeDir   string
+	volumeDir string // Overrides the global volume directory when set
 }
 
 // NewPathResolver creates a new PathResolver.
@@ -22,12 +23,77 @@ func NewPathResolver() (*PathResolver, error) {
 	return &PathResolver{homeDir: homeDir}, nil
 }
 
+// SetVolumeDir overrides the global volume directory, e.g. to keep volumes on an
+// external drive. The directory must be absolute, exist, and be writable.
+// An empty dir restores the default.
+func (p *PathResolver) SetVolumeDir(dir string) error {
+	if dir != "" {
+		if err := ValidateVolumeDir(dir); err != nil {
+			return err
+		}
+		dir = filepath.Clean(dir)
+	}
+	p.volumeDir = dir
+	return nil
+}
+
+// ValidateVolumeDir checks that dir is an absolute path to a writable directory.
+func ValidateVolumeDir(dir string) error {
+	if !filepath.IsAbs(dir) {
+		return fmt.Errorf("volume directory must be an absolute path: %q", dir)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("volume directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("volume directory %s is not a directory", dir)
+	}
+	probe, err := os.CreateTemp(dir, ".capsule-write-test-*")
+	if err != nil {
+		return fmt.Errorf("volume directory %s is not writable: %w", dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return nil
+}
+
 // GetGlobalVolumeDir returns the global volume directory path.
-// Returns: ~/.capsule/volumes
+// Returns: the directory set with SetVolumeDir, or ~/.capsule/volumes
 func (p *PathResolver) GetGlobalVolumeDir() string {
+	if p.volumeDir != "" {
+		return p.volumeDir
+	}
 	return filepath.Join(p.homeDir, constants.CapsuleConfigDir, constants.VolumesSubdir)
 }
 
+// ListVolumes returns all volume images in the global volume directory with
+// their descriptions. A missing directory yields no volumes.
+func (p *PathResolver) ListVolumes() ([]VolumeInfo, error) {
+	dir := p.GetGlobalVolumeDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read volume directory %s: %w", dir, err)
+	}
+
+	var volumes []VolumeInfo
+	for _, entry := range entries {
+		if !isVolumeExtension(entry.Name()) {
+			continue
+		}
+		volumePath := filepath.Join(dir, entry.Name())
+		desc, err := VolumeDescription(volumePath)
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, VolumeInfo{Path: volumePath, Description: desc})
+	}
+	return volumes, nil
+}
+
 // GetDefaultVolumePath returns the default global volume path.
 // Returns: ~/.capsule/volumes/capsule.sparseimage
 func (p *PathResolver) GetDefaultVolumePath() string {
@@ -43,27 +109,25 @@ func (p *PathResolver) GetLocalVolumePath(dir string) string {
 // ResolveVolumePath applies the volume resolution priority rules.
 // Priority:
 // 1. Explicit path (if provided) - use exactly what user specifies
-// 2. Local volume ({cwd}/capsule.sparseimage) - if exists, use it
-// 3. Global volume (~/.capsule/volumes/capsule.sparseimage) - default
+// 2. Local volume ({cwd}/capsule.sparseimage or .sparsebundle) - if exists, use it
+// 3. Global volume (~/.capsule/volumes/capsule.sparseimage, or the VolumeDir) - default
 //
-// Returns the resolved volume path and whether it exists.
-func (p *PathResolver) ResolveVolumePath(explicitPath, cwd string) (volumePath string, exists bool) {
+// Returns the resolved volume path and whether it exists. A directory holding
+// the volume in both formats is an *AmbiguousVolumeError.
+func (p *PathResolver) ResolveVolumePath(explicitPath, cwd string) (volumePath string, exists bool, err error) {
 	// Priority 1: Explicit path
 	if explicitPath != "" {
 		_, err := os.Stat(explicitPath)
-		return explicitPath, err == nil
+		return explicitPath, err == nil, nil
 	}
 
-	// Priority 2: Local volume
-	localPath := p.GetLocalVolumePath(cwd)
-	if _, err := os.Stat(localPath); err == nil {
-		return localPath, true
+	// Priority 2: Local volume, in either format
+	if localPath, ok, err := existingVolumeIn(cwd, constants.MacOSVolumeFile); ok || err != nil {
+		return localPath, ok, err
 	}
 
 	// Priority 3: Global volume (default)
-	globalPath := p.GetDefaultVolumePath()
-	_, err := os.Stat(globalPath)
-	return globalPath, err == nil
+	return existingVolumeIn(p.GetGlobalVolumeDir(), constants.MacOSVolumeFile)
 }
 
 // VolumeNotFoundError provides a helpful error message showing both locations checked.
@@ -78,7 +142,10 @@ func (e *VolumeNotFoundError) Error() string {
 
 // ResolveVolumePathStrict is like ResolveVolumePath but returns an error if no volume is found.
 func (p *PathResolver) ResolveVolumePathStrict(explicitPath, cwd string) (string, error) {
-	volumePath, exists := p.ResolveVolumePath(explicitPath, cwd)
+	volumePath, exists, err := p.ResolveVolumePath(explicitPath, cwd)
+	if err != nil {
+		return "", err
+	}
 	if !exists {
 		return "", &VolumeNotFoundError{
 			LocalPath:  p.GetLocalVolumePath(cwd),
@@ -23,6 +23,39 @@ func TestPathResolver_GetGlobalVolumeDir(t *testing.T) {
 	}
 }
 
+func TestPathResolver_SetVolumeDir(t *testing.T) {
+	resolver, err := NewPathResolver()
+	if err != nil {
+		t.Fatalf("NewPathResolver() error = %v", err)
+	}
+
+	if err := resolver.SetVolumeDir("relative/dir"); err == nil {
+		t.Error("SetVolumeDir() with relative path should return error")
+	}
+
+	dir := t.TempDir()
+	if err := resolver.SetVolumeDir(dir); err != nil {
+		t.Fatalf("SetVolumeDir() error = %v", err)
+	}
+	if got := resolver.GetDefaultVolumePath(); got != filepath.Join(dir, constants.MacOSVolumeFile) {
+		t.Errorf("GetDefaultVolumePath() = %v, want volume in %v", got, dir)
+	}
+
+	os.WriteFile(filepath.Join(dir, constants.MacOSVolumeFile), []byte{}, 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte{}, 0644)
+	if err := SetVolumeDescription(filepath.Join(dir, constants.MacOSVolumeFile), "work projects"); err != nil {
+		t.Fatalf("SetVolumeDescription() error = %v", err)
+	}
+	volumes, err := resolver.ListVolumes()
+	if err != nil {
+		t.Fatalf("ListVolumes() error = %v", err)
+	}
+	want := VolumeInfo{Path: filepath.Join(dir, constants.MacOSVolumeFile), Description: "work projects"}
+	if len(volumes) != 1 || volumes[0] != want {
+		t.Errorf("ListVolumes() = %v, want only %v", volumes, want)
+	}
+}
+
 func TestPathResolver_GetDefaultVolumePath(t *testing.T) {
 	resolver, err := NewPathResolver()
 	if err != nil {
@@ -62,7 +95,7 @@ func TestPathResolver_ResolveVolumePath_ExplicitPath(t *testing.T) {
 	explicitPath := "/custom/path/volume.sparseimage"
 	cwd := "/some/project/dir"
 
-	volumePath, exists := resolver.ResolveVolumePath(explicitPath, cwd)
+	volumePath, exists, _ := resolver.ResolveVolumePath(explicitPath, cwd)
 	if volumePath != explicitPath {
 		t.Errorf("ResolveVolumePath() volumePath = %v, want %v", volumePath, explicitPath)
 	}
@@ -91,7 +124,7 @@ func TestPathResolver_ResolveVolumePath_LocalVolume(t *testing.T) {
 	}
 
 	// Should find local volume
-	volumePath, exists := resolver.ResolveVolumePath("", tmpDir)
+	volumePath, exists, _ := resolver.ResolveVolumePath("", tmpDir)
 	if volumePath != localVolumePath {
 		t.Errorf("ResolveVolumePath() volumePath = %v, want %v", volumePath, localVolumePath)
 	}
@@ -114,7 +147,7 @@ func TestPathResolver_ResolveVolumePath_FallbackToGlobal(t *testing.T) {
 	}
 
 	// Should fall back to global path
-	volumePath, _ := resolver.ResolveVolumePath("", tmpDir)
+	volumePath, _, _ := resolver.ResolveVolumePath("", tmpDir)
 	expected := resolver.GetDefaultVolumePath()
 	if volumePath != expected {
 		t.Errorf("ResolveVolumePath() volumePath = %v, want %v", volumePath, expected)
@@ -0,0 +1,150 @@
+package volume
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// requiredDirs are volume directories that must exist with constants.DirPermissions.
+var requiredDirs = []string{
+	VolumeHomeDir,
+	constants.ReposDirName,
+}
+
+// sensitiveFiles hold credentials and must be constants.FilePermissions when present.
+var sensitiveFiles = []string{
+	filepath.Join("auth", "api-key"),
+	filepath.Join(VolumeHomeDir, ".gitconfig"),
+	filepath.Join(VolumeHomeDir, ".config", "git", "config"),
+	filepath.Join(VolumeHomeDir, ".git-credentials"),
+	filepath.Join(VolumeHomeDir, ".claude.json"),
+	filepath.Join(VolumeHomeDir, ".claude", ".credentials.json"),
+}
+
+// PermIssue describes a volume path with missing or incorrect permissions.
+type PermIssue struct {
+	Path    string      // Absolute path inside the mounted volume
+	Missing bool        // Path does not exist (directories only)
+	Got     os.FileMode // Current permission bits; zero if Missing
+	Want    os.FileMode // Expected permission bits
+	IsDir   bool
+}
+
+func (p PermIssue) String() string {
+	if p.Missing {
+		return fmt.Sprintf("%s: missing", p.Path)
+	}
+	return fmt.Sprintf("%s: mode %#o, want %#o", p.Path, p.Got, p.Want)
+}
+
+// CheckVolumePermissions verifies that the volume's home and repos directories
+// exist with constants.DirPermissions and that credential files are
+// constants.FilePermissions. Wrong permissions stop credentials from persisting
+// between sessions. Returns no issues if the volume is not mounted.
+func CheckVolumePermissions(mountPoint string) ([]PermIssue, error) {
+	if mountPoint == "" {
+		return nil, fmt.Errorf("volume mount point is required")
+	}
+	if _, err := os.Stat(mountPoint); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil // Not mounted, nothing to check
+		}
+		return nil, fmt.Errorf("failed to stat %s: %w", mountPoint, err)
+	}
+
+	var issues []PermIssue
+	for _, rel := range requiredDirs {
+		issue, err := checkPerm(filepath.Join(mountPoint, rel), constants.DirPermissions, true)
+		if err != nil {
+			return nil, err
+		}
+		if issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+	for _, rel := range sensitiveFiles {
+		issue, err := checkPerm(filepath.Join(mountPoint, rel), constants.FilePermissions, false)
+		if err != nil {
+			return nil, err
+		}
+		if issue != nil && !issue.Missing {
+			issues = append(issues, *issue)
+		}
+	}
+	return issues, nil
+}
+
+// checkPerm returns an issue if path is missing or its permission bits differ from want.
+func checkPerm(path string, want os.FileMode, isDir bool) (*PermIssue, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &PermIssue{Path: path, Missing: true, Want: want, IsDir: isDir}, nil
+		}
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if got := info.Mode().Perm(); got != want {
+		return &PermIssue{Path: path, Got: got, Want: want, IsDir: isDir}, nil
+	}
+	return nil, nil
+}
+
+// RepairVolumePermissions fixes the issues reported by CheckVolumePermissions,
+// creating missing directories and resetting modes. It returns the issues it
+// found; errors from individual repairs are joined so one failure does not
+// stop the rest.
+func RepairVolumePermissions(mountPoint string) ([]PermIssue, error) {
+	issues, err := CheckVolumePermissions(mountPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, issue := range issues {
+		if issue.Missing {
+			if err := os.MkdirAll(issue.Path, issue.Want); err != nil {
+				errs = append(errs, fmt.Errorf("failed to create %s: %w", issue.Path, err))
+				continue
+			}
+		}
+		// Chmod explicitly since MkdirAll is subject to the umask
+		if err := os.Chmod(issue.Path, issue.Want); err != nil {
+			errs = append(errs, fmt.Errorf("failed to chmod %s: %w", issue.Path, err))
+		}
+	}
+	return issues, errors.Join(errs...)
+}
+
+// InitVolumeLayout creates the home and repos directories inside a mounted
+// volume with constants.DirPermissions, so HOME and repo symlink targets exist
+// on the first session. Existing directories are left as they are, making it
+// safe to call on every mount.
+func InitVolumeLayout(mountPoint string) error {
+	if mountPoint == "" {
+		return fmt.Errorf("volume mount point is required")
+	}
+	if _, err := os.Stat(mountPoint); err != nil {
+		return fmt.Errorf("volume is not mounted at %s: %w", mountPoint, err)
+	}
+
+	for _, rel := range requiredDirs {
+		path := filepath.Join(mountPoint, rel)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		if err := os.MkdirAll(path, constants.DirPermissions); err != nil {
+			return fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		// Chmod explicitly since MkdirAll is subject to the umask
+		if err := os.Chmod(path, constants.DirPermissions); err != nil {
+			return fmt.Errorf("failed to chmod %s: %w", path, err)
+		}
+	}
+	return nil
+}
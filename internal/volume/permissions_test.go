@@ -0,0 +1,80 @@
+package volume
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+func TestRepairVolumePermissions(t *testing.T) {
+	mountPoint := t.TempDir()
+	home := filepath.Join(mountPoint, VolumeHomeDir)
+	if err := os.Mkdir(home, 0700); err != nil {
+		t.Fatal(err)
+	}
+	creds := filepath.Join(home, ".git-credentials")
+	if err := os.WriteFile(creds, []byte("token"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := CheckVolumePermissions(mountPoint)
+	if err != nil {
+		t.Fatalf("CheckVolumePermissions() error = %v", err)
+	}
+	// home has the wrong mode, repos is missing, and the credentials are world-readable
+	if len(issues) != 3 {
+		t.Fatalf("CheckVolumePermissions() = %v, want 3 issues", issues)
+	}
+
+	if _, err := RepairVolumePermissions(mountPoint); err != nil {
+		t.Fatalf("RepairVolumePermissions() error = %v", err)
+	}
+	issues, err = CheckVolumePermissions(mountPoint)
+	if err != nil || len(issues) != 0 {
+		t.Errorf("after repair: issues = %v, err = %v", issues, err)
+	}
+	if info, _ := os.Stat(creds); info.Mode().Perm() != constants.FilePermissions {
+		t.Errorf("credentials mode = %#o, want %#o", info.Mode().Perm(), constants.FilePermissions)
+	}
+}
+
+func TestCheckVolumePermissions_NotMounted(t *testing.T) {
+	issues, err := CheckVolumePermissions(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || issues != nil {
+		t.Errorf("CheckVolumePermissions() = %v, %v, want nil, nil", issues, err)
+	}
+}
+
+func TestInitVolumeLayout(t *testing.T) {
+	mountPoint := t.TempDir()
+
+	// Run twice to check it is idempotent
+	for i := 0; i < 2; i++ {
+		if err := InitVolumeLayout(mountPoint); err != nil {
+			t.Fatalf("InitVolumeLayout() error = %v", err)
+		}
+	}
+	if issues, err := CheckVolumePermissions(mountPoint); err != nil || len(issues) != 0 {
+		t.Errorf("after init: issues = %v, err = %v", issues, err)
+	}
+
+	if err := InitVolumeLayout(filepath.Join(mountPoint, "missing")); err == nil {
+		t.Error("InitVolumeLayout() on unmounted volume: want error")
+	}
+}
+
+func TestFixHomeOwnership(t *testing.T) {
+	mountPoint := t.TempDir()
+	writeFile(t, filepath.Join(mountPoint, VolumeHomeDir, ".claude", "settings.json"), "{}")
+
+	// Chowning to ourselves needs no privileges
+	if err := FixHomeOwnership(mountPoint, os.Getuid(), os.Getgid()); err != nil {
+		t.Errorf("FixHomeOwnership() error = %v", err)
+	}
+	if err := FixHomeOwnership(filepath.Join(mountPoint, "missing"), os.Getuid(), os.Getgid()); !errors.Is(err, ErrNotMounted) {
+		t.Errorf("FixHomeOwnership(unmounted) error = %v, want ErrNotMounted", err)
+	}
+}
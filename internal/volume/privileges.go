@@ -0,0 +1,60 @@
+package volume
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// diskImageProbeTimeout bounds each hdiutil call of CheckDiskImageAccess.
+const diskImageProbeTimeout = 30 * time.Second
+
+// CheckDiskImageAccess checks that this account can create, attach, and detach
+// disk images, as Bootstrap and Mount need. It creates a 1 MB unformatted image
+// in TempDir, attaches it without mounting, detaches it, and deletes it;
+// nothing else is touched. The error includes hdiutil's output.
+func (m *MacOSVolumeManager) CheckDiskImageAccess() error {
+	dir, err := os.MkdirTemp(TempDir(), "capsule-probe-")
+	if err != nil {
+		return fmt.Errorf("failed to create probe directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	image := filepath.Join(dir, "probe.dmg")
+
+	ctx, cancel := context.WithTimeout(context.Background(), diskImageProbeTimeout)
+	defer cancel()
+	if output, err := m.combinedOutput(ctx, nil, "hdiutil", "create", "-size", "1m", "-layout", "NONE", image); err != nil {
+		return fmt.Errorf("cannot create disk images: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	attachCtx, attachCancel := context.WithTimeout(context.Background(), diskImageProbeTimeout)
+	defer attachCancel()
+	var stdout, stderr bytes.Buffer
+	err = m.runner.Run(attachCtx, Command{
+		Name:   "hdiutil",
+		Args:   []string{"attach", "-nomount", image},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot attach disk images: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	device := parseAttachedDevice(stdout.String())
+	if m.dryRun {
+		return nil
+	}
+	if device == "" {
+		return fmt.Errorf("attached probe image but could not determine its device from hdiutil output")
+	}
+
+	detachCtx, detachCancel := context.WithTimeout(context.Background(), diskImageProbeTimeout)
+	defer detachCancel()
+	if output, err := m.combinedOutput(detachCtx, nil, "hdiutil", "detach", device); err != nil {
+		return fmt.Errorf("cannot detach disk images (%s is still attached): %w: %s", device, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package volume
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jeanhaley32/claude-capsule/internal/terminal"
+)
+
+// mountProgressInterval is how often a long-running phase is reported again.
+const mountProgressInterval = 5 * time.Second
+
+// MountPhase is a step of MountWithProgress or UnmountWithProgress.
+type MountPhase string
+
+const (
+	PhaseVerifying      MountPhase = "verifying"       // Checking the image file before attaching
+	PhaseAttaching      MountPhase = "attaching"       // hdiutil attach is decrypting and mounting
+	PhaseMounted        MountPhase = "mounted"         // The volume is mounted
+	PhaseUnmounting     MountPhase = "unmounting"      // diskutil unmount is syncing and unmounting
+	PhaseDetaching      MountPhase = "detaching"       // diskutil failed; trying hdiutil detach
+	PhaseForceDetaching MountPhase = "force-detaching" // hdiutil detach failed; trying -force
+	PhaseUnmounted      MountPhase = "unmounted"       // The volume is unmounted
+)
+
+// MountEvent reports that a mount or unmount phase began or is still running.
+// A phase that takes a while is reported again every 5 seconds.
+type MountEvent struct {
+	Phase      MountPhase
+	MountPoint string
+	Elapsed    time.Duration // Since the mount or unmount began
+}
+
+func (e MountEvent) String() string {
+	return fmt.Sprintf("%s %s (%v)", e.Phase, e.MountPoint, e.Elapsed.Round(time.Second))
+}
+
+// MountProgressOptions configures MountWithProgress.
+type MountProgressOptions struct {
+	// RepoID mounts at MountPointForVolume(RepoID), as MountForRepo does.
+	// When empty the volume is mounted where Mount would put it.
+	RepoID string
+
+	// Timeout bounds hdiutil attach. Defaults to 5 minutes.
+	Timeout time.Duration
+}
+
+// MountWithProgress is like Mount (or MountForRepo, when opts.RepoID is set)
+// but sends an event as each phase begins, and repeats the current phase
+// every few seconds while hdiutil attach runs, so callers can show that a
+// large volume is still being unlocked. The events channel is closed when the
+// mount finishes; the caller must drain it. A nil channel reports nothing.
+func (m *MacOSVolumeManager) MountWithProgress(volumePath string, password *terminal.SecurePassword, opts MountProgressOptions, events chan<- MountEvent) (string, error) {
+	progress := newMountProgress(events, opts.Timeout)
+	if events != nil {
+		defer close(events)
+	}
+
+	mountPoint := m.generateMountPoint(volumePath)
+	if opts.RepoID != "" {
+		var err error
+		if mountPoint, err = repoMountPoint(opts.RepoID); err != nil {
+			return "", err
+		}
+	}
+	return m.mountAt(volumePath, mountPoint, password, progress)
+}
+
+// UnmountWithProgress is like Unmount but sends an event as each phase
+// begins, including the fallbacks to hdiutil detach and detach -force. The
+// events channel is closed when the unmount finishes; the caller must drain it.
+// A nil channel reports nothing.
+func (m *MacOSVolumeManager) UnmountWithProgress(mountPoint string, events chan<- MountEvent) error {
+	if events != nil {
+		defer close(events)
+	}
+	release, err := m.lockVolumes()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return m.unmount(mountPoint, newMountProgress(events, 0))
+}
+
+// mountProgress sends MountEvents. A nil *mountProgress reports nothing, so
+// the plain Mount and Unmount pass nil.
+type mountProgress struct {
+	events  chan<- MountEvent
+	start   time.Time
+	timeout time.Duration
+}
+
+// newMountProgress returns a mountProgress sending to events; a nil events
+// channel sends nothing but keeps the timeout.
+func newMountProgress(events chan<- MountEvent, timeout time.Duration) *mountProgress {
+	return &mountProgress{events: events, start: time.Now(), timeout: timeout}
+}
+
+// report sends an event for the start of phase.
+func (p *mountProgress) report(phase MountPhase, mountPoint string) {
+	if p == nil || p.events == nil {
+		return
+	}
+	p.events <- MountEvent{Phase: phase, MountPoint: mountPoint, Elapsed: time.Since(p.start)}
+}
+
+// repeat reports phase every mountProgressInterval until the returned func is
+// called, which waits for the reporting goroutine to exit.
+func (p *mountProgress) repeat(phase MountPhase, mountPoint string) (stop func()) {
+	if p == nil || p.events == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(mountProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			select {
+			case p.events <- MountEvent{Phase: phase, MountPoint: mountPoint, Elapsed: time.Since(p.start)}:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// attachTimeout returns the configured hdiutil attach timeout.
+func (p *mountProgress) attachTimeout() time.Duration {
+	if p == nil || p.timeout <= 0 {
+		return volumeOperationTimeout
+	}
+	return p.timeout
+}
@@ -0,0 +1,67 @@
+package volume
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// RepoIDInUse reports whether repos/<repoID> already exists for a different
+// remote, as happens when a fork and its upstream derive the same ID. remoteURL
+// is the normalized remote (repo.NormalizeRemoteURL). existingRemote is the
+// remote recorded in the directory's marker file. Directories without a marker,
+// created before markers were written, are never reported as in use.
+func RepoIDInUse(volumeMountPoint, repoID, remoteURL string) (inUse bool, existingRemote string, err error) {
+	if err := ValidateRepoID(repoID); err != nil {
+		return false, "", err
+	}
+
+	dir := RepoDocsPath(volumeMountPoint, repoID)
+	if _, err := os.Stat(dir); err != nil {
+		if os.IsNotExist(err) {
+			return false, "", nil
+		}
+		return false, "", fmt.Errorf("failed to stat %s: %w", dir, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, constants.RepoRemoteMarkerName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, "", nil
+		}
+		return false, "", fmt.Errorf("failed to read remote marker: %w", err)
+	}
+	existingRemote = strings.TrimSpace(string(data))
+	return existingRemote != remoteURL, existingRemote, nil
+}
+
+// WriteRepoRemote records remoteURL in the marker file of repos/<repoID>,
+// creating the directory if needed. An existing marker is left untouched so
+// the directory stays attributed to the remote that created it.
+func WriteRepoRemote(volumeMountPoint, repoID, remoteURL string) error {
+	if err := ValidateRepoID(repoID); err != nil {
+		return err
+	}
+
+	dir := RepoDocsPath(volumeMountPoint, repoID)
+	if err := os.MkdirAll(dir, constants.DirPermissions); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, constants.RepoRemoteMarkerName),
+		os.O_WRONLY|os.O_CREATE|os.O_EXCL, constants.FilePermissions)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to create remote marker: %w", err)
+	}
+	if _, err := f.WriteString(remoteURL + "\n"); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write remote marker: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,46 @@
+package volume
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+	"github.com/jeanhaley32/claude-capsule/internal/terminal"
+)
+
+// MountCacheRefresher clears Docker Desktop's cached view of host mounts.
+// docker.Manager implements this interface.
+type MountCacheRefresher interface {
+	ClearVMCache() error
+	RefreshMountCache(mountPoint string) error
+}
+
+// Remount detaches and re-attaches a volume to recover from stale VirtioFS state.
+// It unmounts mountPoint (Unmount falls back to a forced detach), clears Docker's
+// VM cache, re-attaches the volume, and refreshes Docker's view of the new mount.
+// Cache clearing is best effort, as during start. Returns the new mount point.
+func Remount(vm VolumeManager, cache MountCacheRefresher, volumePath, mountPoint string, password *terminal.SecurePassword) (string, error) {
+	if password == nil || password.Len() == 0 {
+		return "", fmt.Errorf("password is required")
+	}
+
+	if err := vm.Unmount(mountPoint); err != nil {
+		return "", fmt.Errorf("failed to unmount volume: %w", err)
+	}
+
+	// Let Docker release its references to the old mount before clearing caches
+	time.Sleep(constants.MountReleaseDelay)
+	_ = cache.ClearVMCache()
+	time.Sleep(constants.CacheRefreshDelay)
+
+	newMountPoint, err := vm.Mount(volumePath, password)
+	if err != nil {
+		return "", fmt.Errorf("failed to remount volume: %w", err)
+	}
+	if err := WaitMounted(newMountPoint, DefaultMountWaitTimeout); err != nil {
+		return "", err
+	}
+	_ = cache.RefreshMountCache(newMountPoint)
+
+	return newMountPoint, nil
+}
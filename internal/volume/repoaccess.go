@@ -0,0 +1,83 @@
+package volume
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// RepoAccess is when a repo docs directory was last used in a session.
+type RepoAccess struct {
+	RepoID     string
+	LastAccess time.Time // Zero if the repo has never been tracked
+}
+
+// TouchRepoAccess records now as the last access time of repos/<repoID> by
+// updating the modification time of its marker file. Only the marker is
+// touched, so it stays cheap; callers should treat failures as warnings.
+func TouchRepoAccess(volumeMountPoint, repoID string) error {
+	if err := ValidateRepoID(repoID); err != nil {
+		return err
+	}
+
+	dir := RepoDocsPath(volumeMountPoint, repoID)
+	if err := os.MkdirAll(dir, constants.DirPermissions); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	marker := filepath.Join(dir, constants.RepoAccessMarkerName)
+	now := time.Now()
+	if err := os.Chtimes(marker, now, now); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to update access marker: %w", err)
+	}
+	f, err := os.OpenFile(marker, os.O_WRONLY|os.O_CREATE, constants.FilePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to create access marker: %w", err)
+	}
+	return f.Close()
+}
+
+// ReposByLastAccess returns every repo docs directory in a mounted volume,
+// least recently accessed first. Repos never tracked by TouchRepoAccess have
+// a zero LastAccess and sort before all others. Returns nil if the volume is
+// not mounted.
+func ReposByLastAccess(volumeMountPoint string) ([]RepoAccess, error) {
+	if volumeMountPoint == "" {
+		return nil, fmt.Errorf("volume mount point is required")
+	}
+	if _, err := os.Stat(volumeMountPoint); os.IsNotExist(err) {
+		return nil, nil // Not mounted
+	}
+
+	reposDir := filepath.Join(volumeMountPoint, constants.ReposDirName)
+	entries, err := os.ReadDir(reposDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", reposDir, err)
+	}
+
+	var repos []RepoAccess
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		access := RepoAccess{RepoID: entry.Name()}
+		if info, err := os.Stat(filepath.Join(reposDir, entry.Name(), constants.RepoAccessMarkerName)); err == nil {
+			access.LastAccess = info.ModTime()
+		}
+		repos = append(repos, access)
+	}
+
+	sort.SliceStable(repos, func(i, j int) bool {
+		return repos[i].LastAccess.Before(repos[j].LastAccess)
+	})
+	return repos, nil
+}
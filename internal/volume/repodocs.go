@@ -0,0 +1,221 @@
+package volume
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// validRepoIDPattern matches identifiers produced by repo.GetRepoID.
+var validRepoIDPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// maxRepoIDLength mirrors the identifier length limit in the repo package.
+const maxRepoIDLength = 100
+
+// ValidateRepoID checks that a repository identifier is safe to use as a
+// directory name inside the volume's repos directory.
+func ValidateRepoID(repoID string) error {
+	if repoID == "" {
+		return fmt.Errorf("repo ID cannot be empty")
+	}
+	if len(repoID) > maxRepoIDLength {
+		return fmt.Errorf("repo ID too long: %d characters (max %d)", len(repoID), maxRepoIDLength)
+	}
+	if repoID == "." || repoID == ".." || !validRepoIDPattern.MatchString(repoID) {
+		return fmt.Errorf("invalid repo ID %q: must contain only [a-zA-Z0-9._-]", repoID)
+	}
+	if strings.HasPrefix(repoID, "-") || strings.HasSuffix(repoID, "-") {
+		return fmt.Errorf("invalid repo ID %q: must not start or end with a hyphen", repoID)
+	}
+	return nil
+}
+
+// RepoDocsPath returns the path to a repository's docs directory in a mounted volume.
+func RepoDocsPath(volumeMountPoint, repoID string) string {
+	return filepath.Join(volumeMountPoint, constants.ReposDirName, repoID)
+}
+
+// MigrateRepoDocs moves a repository's docs from repos/<oldID> to repos/<newID>.
+// Use this when a repository's derived ID changes (e.g. switching from HTTPS to SSH remote).
+// It refuses to overwrite an existing destination; use MergeRepoDocs for that case.
+func MigrateRepoDocs(volumeMountPoint, oldID, newID string) error {
+	return migrateRepoDocs(volumeMountPoint, oldID, newID, false)
+}
+
+// AdoptLegacyCaseRepoDocs moves docs stored under an older mixed-case form of
+// repoID (from before repo IDs were lowercased) to repos/<repoID>, so they are
+// not orphaned. It returns the legacy ID that was adopted, or "" if there was
+// none. On a case-insensitive volume the directory is renamed in place. If
+// docs exist under both IDs, or under several legacy spellings, nothing is
+// moved and an error names them; combine them with MergeRepoDocs.
+func AdoptLegacyCaseRepoDocs(volumeMountPoint, repoID string) (string, error) {
+	if err := ValidateRepoID(repoID); err != nil {
+		return "", err
+	}
+	entries, err := os.ReadDir(filepath.Join(volumeMountPoint, constants.ReposDirName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read repos directory: %w", err)
+	}
+
+	var legacy []string
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != repoID && strings.EqualFold(entry.Name(), repoID) {
+			legacy = append(legacy, entry.Name())
+		}
+	}
+	switch len(legacy) {
+	case 0:
+		return "", nil
+	case 1:
+	default:
+		return "", fmt.Errorf("docs for %s exist under several IDs (%s); merge them with MergeRepoDocs", repoID, strings.Join(legacy, ", "))
+	}
+
+	oldPath := RepoDocsPath(volumeMountPoint, legacy[0])
+	newPath := RepoDocsPath(volumeMountPoint, repoID)
+	oldInfo, err := os.Stat(oldPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", oldPath, err)
+	}
+	newInfo, err := os.Stat(newPath)
+	if err == nil {
+		if !os.SameFile(oldInfo, newInfo) {
+			return "", fmt.Errorf("docs for %s exist under both %s and %s; merge them with MergeRepoDocs", repoID, legacy[0], repoID)
+		}
+		// Case-insensitive volume: the same directory, so only fix its case
+		if err := os.Rename(oldPath, newPath); err != nil {
+			return "", fmt.Errorf("failed to rename %s to %s: %w", oldPath, newPath, err)
+		}
+		return legacy[0], nil
+	}
+	if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to stat %s: %w", newPath, err)
+	}
+	if err := MigrateRepoDocs(volumeMountPoint, legacy[0], repoID); err != nil {
+		return "", err
+	}
+	return legacy[0], nil
+}
+
+// AdoptLegacyRepoDocs moves docs stored under legacyID, the ID a repository
+// had under an older ID scheme, to repos/<repoID>, so they are not orphaned
+// when the scheme changes. It only moves them when repos/<legacyID> exists and
+// repos/<repoID> does not, and reports whether it did; if both exist they are
+// left alone, since the legacy docs may belong to another repository.
+func AdoptLegacyRepoDocs(volumeMountPoint, legacyID, repoID string) (bool, error) {
+	if err := ValidateRepoID(legacyID); err != nil {
+		return false, fmt.Errorf("invalid legacy ID: %w", err)
+	}
+	if err := ValidateRepoID(repoID); err != nil {
+		return false, err
+	}
+	if legacyID == repoID {
+		return false, nil
+	}
+	for _, check := range []struct {
+		id   string
+		want bool
+	}{{legacyID, true}, {repoID, false}} {
+		path := RepoDocsPath(volumeMountPoint, check.id)
+		_, err := os.Lstat(path)
+		if err != nil && !os.IsNotExist(err) {
+			return false, fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		if (err == nil) != check.want {
+			return false, nil
+		}
+	}
+	if err := MigrateRepoDocs(volumeMountPoint, legacyID, repoID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// MergeRepoDocs is like MigrateRepoDocs but merges into an existing destination.
+// Entries that exist in both directories are left untouched and reported as an error,
+// so no documentation is ever overwritten.
+func MergeRepoDocs(volumeMountPoint, oldID, newID string) error {
+	return migrateRepoDocs(volumeMountPoint, oldID, newID, true)
+}
+
+func migrateRepoDocs(volumeMountPoint, oldID, newID string, merge bool) error {
+	if volumeMountPoint == "" {
+		return fmt.Errorf("volume mount point is required")
+	}
+	if err := ValidateRepoID(oldID); err != nil {
+		return fmt.Errorf("invalid old ID: %w", err)
+	}
+	if err := ValidateRepoID(newID); err != nil {
+		return fmt.Errorf("invalid new ID: %w", err)
+	}
+	if oldID == newID {
+		return nil
+	}
+
+	oldPath := RepoDocsPath(volumeMountPoint, oldID)
+	newPath := RepoDocsPath(volumeMountPoint, newID)
+
+	info, err := os.Stat(oldPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no docs found for %s at %s", oldID, oldPath)
+		}
+		return fmt.Errorf("failed to stat %s: %w", oldPath, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", oldPath)
+	}
+
+	if _, err := os.Lstat(newPath); err == nil {
+		if !merge {
+			return fmt.Errorf("destination already exists: %s (use merge to combine)", newPath)
+		}
+		return mergeDirs(oldPath, newPath)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to stat %s: %w", newPath, err)
+	}
+
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return fmt.Errorf("failed to move %s to %s: %w", oldPath, newPath, err)
+	}
+	return nil
+}
+
+// mergeDirs moves each top-level entry of src into dst, skipping conflicts.
+// src is removed if every entry was moved.
+func mergeDirs(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+
+	var conflicts []string
+	for _, entry := range entries {
+		from := filepath.Join(src, entry.Name())
+		to := filepath.Join(dst, entry.Name())
+		if _, err := os.Lstat(to); err == nil {
+			conflicts = append(conflicts, entry.Name())
+			continue
+		}
+		if err := os.Rename(from, to); err != nil {
+			return fmt.Errorf("failed to move %s to %s: %w", from, to, err)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("merge left %d conflicting entries in %s: %s",
+			len(conflicts), src, strings.Join(conflicts, ", "))
+	}
+
+	if err := os.Remove(src); err != nil {
+		return fmt.Errorf("failed to remove %s after merge: %w", src, err)
+	}
+	return nil
+}
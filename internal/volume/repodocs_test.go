@@ -0,0 +1,72 @@
+package volume
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAdoptLegacyCaseRepoDocs(t *testing.T) {
+	mountPoint := t.TempDir()
+	legacy := RepoDocsPath(mountPoint, "github.com-User-Repo")
+	if err := os.MkdirAll(legacy, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(legacy, "notes.md"), []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := AdoptLegacyCaseRepoDocs(mountPoint, "github.com-user-repo")
+	if err != nil || got != "github.com-User-Repo" {
+		t.Fatalf("AdoptLegacyCaseRepoDocs() = %q, %v, want the legacy ID", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(RepoDocsPath(mountPoint, "github.com-user-repo"), "notes.md")); err != nil {
+		t.Errorf("docs not moved to the lowercase ID: %v", err)
+	}
+	if got, err := AdoptLegacyCaseRepoDocs(mountPoint, "github.com-user-repo"); err != nil || got != "" {
+		t.Errorf("second AdoptLegacyCaseRepoDocs() = %q, %v, want nothing to do", got, err)
+	}
+
+	// Docs under both IDs are left for the user to merge
+	if err := os.MkdirAll(RepoDocsPath(mountPoint, "github.com-USER-repo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AdoptLegacyCaseRepoDocs(mountPoint, "github.com-user-repo"); err == nil {
+		t.Error("AdoptLegacyCaseRepoDocs() with docs under both IDs succeeded, want error")
+	}
+}
+
+func TestAdoptLegacyRepoDocs(t *testing.T) {
+	mountPoint := t.TempDir()
+	legacy := RepoDocsPath(mountPoint, "scratch")
+	if err := os.MkdirAll(legacy, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(legacy, "notes.md"), []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	moved, err := AdoptLegacyRepoDocs(mountPoint, "scratch", "scratch-1a2b3c4d")
+	if err != nil || !moved {
+		t.Fatalf("AdoptLegacyRepoDocs() = %v, %v, want moved", moved, err)
+	}
+	if _, err := os.Stat(filepath.Join(RepoDocsPath(mountPoint, "scratch-1a2b3c4d"), "notes.md")); err != nil {
+		t.Errorf("docs not moved: %v", err)
+	}
+
+	// Nothing left to adopt
+	if moved, err := AdoptLegacyRepoDocs(mountPoint, "scratch", "scratch-1a2b3c4d"); err != nil || moved {
+		t.Errorf("second AdoptLegacyRepoDocs() = %v, %v, want nothing moved", moved, err)
+	}
+
+	// Both exist: the legacy docs may belong to another repository
+	if err := os.MkdirAll(legacy, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if moved, err := AdoptLegacyRepoDocs(mountPoint, "scratch", "scratch-1a2b3c4d"); err != nil || moved {
+		t.Errorf("AdoptLegacyRepoDocs() with both = %v, %v, want nothing moved", moved, err)
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		t.Errorf("legacy docs removed: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package volume
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// ErrSizeIncomplete is returned by RepoSizes when some files could not be
+// read. The sizes are still returned but undercount the affected repos.
+var ErrSizeIncomplete = errors.New("some files could not be measured")
+
+// RepoSizes returns the total size in bytes of each repo docs directory under
+// repos/ in a mounted volume, keyed by repo ID. Symlinks count as their own
+// size, not their target's. Files that cannot be read are skipped and reported
+// by wrapping ErrSizeIncomplete. Returns nil if the volume is not mounted.
+func RepoSizes(volumeMountPoint string) (map[string]int64, error) {
+	if volumeMountPoint == "" {
+		return nil, fmt.Errorf("volume mount point is required")
+	}
+	if _, err := os.Stat(volumeMountPoint); os.IsNotExist(err) {
+		return nil, nil // Not mounted
+	}
+
+	reposDir := filepath.Join(volumeMountPoint, constants.ReposDirName)
+	entries, err := os.ReadDir(reposDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]int64{}, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", reposDir, err)
+	}
+
+	sizes := make(map[string]int64)
+	skipped := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		size, n := dirSize(filepath.Join(reposDir, entry.Name()))
+		sizes[entry.Name()] = size
+		skipped += n
+	}
+
+	if skipped > 0 {
+		return sizes, fmt.Errorf("%w: skipped %d unreadable entries under %s", ErrSizeIncomplete, skipped, reposDir)
+	}
+	return sizes, nil
+}
+
+// dirSize sums the sizes of files under dir and counts entries it had to skip.
+func dirSize(dir string) (size int64, skipped int) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			skipped++
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			skipped++
+			return nil
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, skipped
+}
@@ -0,0 +1,99 @@
+package volume
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+func TestRepoSizes(t *testing.T) {
+	mountPoint := t.TempDir()
+	writeFile(t, filepath.Join(RepoDocsPath(mountPoint, "a"), "one.md"), "12345")
+	writeFile(t, filepath.Join(RepoDocsPath(mountPoint, "a"), "sub", "two.md"), "123")
+	writeFile(t, filepath.Join(RepoDocsPath(mountPoint, "b"), "three.md"), "1")
+
+	sizes, err := RepoSizes(mountPoint)
+	if err != nil {
+		t.Fatalf("RepoSizes() error = %v", err)
+	}
+	if sizes["a"] != 8 || sizes["b"] != 1 || len(sizes) != 2 {
+		t.Errorf("RepoSizes() = %v, want a=8 b=1", sizes)
+	}
+
+	sizes, err = RepoSizes(filepath.Join(mountPoint, "missing"))
+	if err != nil || sizes != nil {
+		t.Errorf("RepoSizes(unmounted) = %v, %v, want nil, nil", sizes, err)
+	}
+}
+
+func TestFindDuplicateRepoIDs(t *testing.T) {
+	work, personal, empty := t.TempDir(), t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(RepoDocsPath(work, "shared"), "a.md"), "a")
+	writeFile(t, filepath.Join(RepoDocsPath(work, "work-only"), "b.md"), "b")
+	writeFile(t, filepath.Join(RepoDocsPath(personal, "shared"), "c.md"), "c")
+
+	duplicates, err := findDuplicateRepoIDs([]string{work, personal, empty})
+	if err != nil {
+		t.Fatalf("findDuplicateRepoIDs() error = %v", err)
+	}
+	if len(duplicates) != 1 || len(duplicates["shared"]) != 2 {
+		t.Errorf("findDuplicateRepoIDs() = %v, want only shared in both volumes", duplicates)
+	}
+}
+
+func TestRepoIDInUse(t *testing.T) {
+	mountPoint := t.TempDir()
+	if inUse, _, err := RepoIDInUse(mountPoint, "github.com-me-tool", "github.com-me-tool"); err != nil || inUse {
+		t.Fatalf("RepoIDInUse(missing) = %v, %v, want false, nil", inUse, err)
+	}
+
+	if err := WriteRepoRemote(mountPoint, "github.com-me-tool", "github.com-me-tool"); err != nil {
+		t.Fatalf("WriteRepoRemote() error = %v", err)
+	}
+	// A second write must not reattribute the directory
+	if err := WriteRepoRemote(mountPoint, "github.com-me-tool", "github.com-fork-tool"); err != nil {
+		t.Fatalf("WriteRepoRemote() error = %v", err)
+	}
+
+	if inUse, _, err := RepoIDInUse(mountPoint, "github.com-me-tool", "github.com-me-tool"); err != nil || inUse {
+		t.Errorf("RepoIDInUse(same remote) = %v, %v, want false, nil", inUse, err)
+	}
+	inUse, existing, err := RepoIDInUse(mountPoint, "github.com-me-tool", "github.com-fork-tool")
+	if err != nil || !inUse || existing != "github.com-me-tool" {
+		t.Errorf("RepoIDInUse(other remote) = %v, %q, %v, want true, github.com-me-tool", inUse, existing, err)
+	}
+}
+
+func TestReposByLastAccess(t *testing.T) {
+	mountPoint := t.TempDir()
+	for _, id := range []string{"recent", "old", "untracked"} {
+		if err := os.MkdirAll(RepoDocsPath(mountPoint, id), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []string{"old", "recent"} {
+		if err := TouchRepoAccess(mountPoint, id); err != nil {
+			t.Fatalf("TouchRepoAccess(%s) error = %v", id, err)
+		}
+	}
+	old := time.Now().Add(-90 * 24 * time.Hour)
+	if err := os.Chtimes(filepath.Join(RepoDocsPath(mountPoint, "old"), constants.RepoAccessMarkerName), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	repos, err := ReposByLastAccess(mountPoint)
+	if err != nil {
+		t.Fatalf("ReposByLastAccess() error = %v", err)
+	}
+	var ids []string
+	for _, r := range repos {
+		ids = append(ids, r.RepoID)
+	}
+	if got := strings.Join(ids, ","); got != "untracked,old,recent" {
+		t.Errorf("ReposByLastAccess() order = %s", got)
+	}
+}
@@ -0,0 +1,228 @@
+package volume
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// syncKeepNames are top-level entries of the destination SyncRepoDocs never
+// deletes, so the destination can be (or live inside) a git checkout.
+var syncKeepNames = map[string]bool{".git": true}
+
+// SyncRepoDocs mirrors repos/<repoID> of a mounted volume into destDir, like
+// `rsync -rlt`: directories are created, files copied when their size or
+// modification time differ, symlinks recreated as links, and modification
+// times preserved. With delete, entries of destDir that are not in the repo's
+// docs are removed, except a top-level .git. Use it to keep the docs in a
+// git-tracked directory outside the encrypted volume.
+//
+// The volume must be mounted; ErrNotMounted is returned otherwise, and callers
+// can treat it as nothing to sync. Files other than regular files, directories
+// and symlinks are skipped.
+func SyncRepoDocs(volumeMountPoint, repoID, destDir string, delete bool) error {
+	if volumeMountPoint == "" {
+		return fmt.Errorf("volume mount point is required")
+	}
+	if destDir == "" {
+		return fmt.Errorf("destination directory is required")
+	}
+	if err := ValidateRepoID(repoID); err != nil {
+		return err
+	}
+	if info, err := os.Stat(volumeMountPoint); err != nil || !info.IsDir() {
+		return fmt.Errorf("%w at %s", ErrNotMounted, volumeMountPoint)
+	}
+
+	src := RepoDocsPath(volumeMountPoint, repoID)
+	info, err := os.Stat(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no docs found for %s at %s", repoID, src)
+		}
+		return fmt.Errorf("failed to stat %s: %w", src, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", src)
+	}
+
+	dst, err := filepath.Abs(destDir)
+	if err != nil {
+		return err
+	}
+	if absSrc, err := filepath.Abs(src); err == nil && (isWithin(dst, absSrc) || isWithin(absSrc, dst)) {
+		return fmt.Errorf("destination %s overlaps the docs directory %s", dst, src)
+	}
+
+	// Directory times are set last, deepest first, since copying into a
+	// directory changes its modification time
+	type dirTime struct {
+		path    string
+		modTime time.Time
+	}
+	var dirs []dirTime
+	seen := map[string]bool{}
+
+	err = filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		seen[rel] = true
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			if err := syncDir(target, info.Mode().Perm()); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirTime{target, info.ModTime()})
+			return nil
+		case info.Mode().IsRegular():
+			return syncFile(p, target, info)
+		case info.Mode()&fs.ModeSymlink != 0:
+			return syncSymlink(p, target)
+		default:
+			return nil
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sync %s to %s: %w", src, dst, err)
+	}
+
+	if delete {
+		if err := deleteExtraneous(dst, seen); err != nil {
+			return fmt.Errorf("failed to delete extraneous files in %s: %w", dst, err)
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chtimes(dirs[i].path, dirs[i].modTime, dirs[i].modTime); err != nil {
+			return fmt.Errorf("failed to set times on %s: %w", dirs[i].path, err)
+		}
+	}
+	return nil
+}
+
+// isWithin reports whether p is dir or inside it.
+func isWithin(p, dir string) bool {
+	return p == dir || strings.HasPrefix(p, dir+string(filepath.Separator))
+}
+
+// syncDir ensures target is a directory, replacing anything else there.
+func syncDir(target string, perm fs.FileMode) error {
+	if info, err := os.Lstat(target); err == nil {
+		if info.IsDir() {
+			return nil
+		}
+		if err := os.Remove(target); err != nil {
+			return err
+		}
+	}
+	return os.MkdirAll(target, perm)
+}
+
+// syncFile copies src to target unless target already has the same size and
+// modification time.
+func syncFile(src, target string, info fs.FileInfo) error {
+	if existing, err := os.Lstat(target); err == nil {
+		if existing.Mode().IsRegular() && existing.Size() == info.Size() && existing.ModTime().Equal(info.ModTime()) {
+			return nil
+		}
+		if existing.IsDir() {
+			if err := os.RemoveAll(target); err != nil {
+				return err
+			}
+		}
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// Write beside the target and rename so an interrupted sync never leaves a
+	// truncated file
+	out, err := os.CreateTemp(filepath.Dir(target), ".capsule-sync-*")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(out.Name(), info.Mode().Perm())
+	}
+	if err == nil {
+		err = os.Chtimes(out.Name(), info.ModTime(), info.ModTime())
+	}
+	if err == nil {
+		err = os.Rename(out.Name(), target)
+	}
+	if err != nil {
+		os.Remove(out.Name())
+		return err
+	}
+	return nil
+}
+
+// syncSymlink recreates the symlink src at target with the same link text.
+func syncSymlink(src, target string) error {
+	link, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if existing, err := os.Readlink(target); err == nil && existing == link {
+		return nil
+	}
+	if err := os.RemoveAll(target); err != nil {
+		return err
+	}
+	return os.Symlink(link, target)
+}
+
+// deleteExtraneous removes entries under dst whose relative paths are not in
+// keep, leaving syncKeepNames at the top level alone.
+func deleteExtraneous(dst string, keep map[string]bool) error {
+	var errs []error
+	err := filepath.WalkDir(dst, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dst, p)
+		if err != nil {
+			return err
+		}
+		if keep[rel] {
+			return nil
+		}
+		if syncKeepNames[rel] {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if err := os.RemoveAll(p); err != nil {
+			errs = append(errs, err)
+		}
+		if d.IsDir() {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	return errors.Join(err, errors.Join(errs...))
+}
@@ -0,0 +1,75 @@
+package volume
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// Command describes a single external command invocation.
+type Command struct {
+	Name   string
+	Args   []string
+	Stdin  io.Reader // May be nil
+	Stdout io.Writer // May be nil to discard
+	Stderr io.Writer // May be nil to discard
+}
+
+// String returns the command line. Stdin is never included, since it may carry a password.
+func (c Command) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// CommandRunner executes the hdiutil and diskutil commands issued by the volume manager.
+// Replacing it allows dry runs and testing without macOS.
+type CommandRunner interface {
+	Run(ctx context.Context, cmd Command) error
+}
+
+// ExecRunner runs commands with os/exec.
+type ExecRunner struct{}
+
+// Run executes the command and waits for it to finish.
+func (ExecRunner) Run(ctx context.Context, c Command) error {
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Stdin = c.Stdin
+	cmd.Stdout = c.Stdout
+	cmd.Stderr = c.Stderr
+	return cmd.Run()
+}
+
+// DryRunRunner logs each command instead of running it. Every command succeeds
+// with no output.
+type DryRunRunner struct {
+	Out io.Writer
+}
+
+// Run writes the command line to Out.
+func (r DryRunRunner) Run(ctx context.Context, c Command) error {
+	if r.Out != nil {
+		fmt.Fprintf(r.Out, "[dry-run] %s\n", c)
+	}
+	return nil
+}
+
+// run executes a command, discarding its output.
+func (m *MacOSVolumeManager) run(ctx context.Context, stdin io.Reader, name string, args ...string) error {
+	return m.runner.Run(ctx, Command{Name: name, Args: args, Stdin: stdin})
+}
+
+// output executes a command and returns its stdout.
+func (m *MacOSVolumeManager) output(ctx context.Context, stdin io.Reader, name string, args ...string) ([]byte, error) {
+	var stdout bytes.Buffer
+	err := m.runner.Run(ctx, Command{Name: name, Args: args, Stdin: stdin, Stdout: &stdout})
+	return stdout.Bytes(), err
+}
+
+// combinedOutput executes a command and returns its interleaved stdout and stderr.
+func (m *MacOSVolumeManager) combinedOutput(ctx context.Context, stdin io.Reader, name string, args ...string) ([]byte, error) {
+	var out bytes.Buffer
+	err := m.runner.Run(ctx, Command{Name: name, Args: args, Stdin: stdin, Stdout: &out, Stderr: &out})
+	return out.Bytes(), err
+}
@@ -0,0 +1,80 @@
+package volume
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// SeedShellConfig copies host shell config files into the volume home so the
+// container shell picks up the user's aliases and prompt. files maps a host path
+// to a destination relative to the volume root, which must lie under the home
+// directory, e.g. "~/.config/fish/config.fish" -> "home/.config/fish/config.fish".
+// Host files that do not exist are skipped, and existing copies are overwritten,
+// so seeding again is safe. Files executable on the host stay executable.
+func SeedShellConfig(volumeMountPoint string, files map[string]string) error {
+	if volumeMountPoint == "" {
+		return fmt.Errorf("volume mount point is required")
+	}
+	volumeHome := filepath.Join(volumeMountPoint, VolumeHomeDir)
+
+	for src, rel := range files {
+		dst, err := shellConfigDest(volumeMountPoint, volumeHome, rel)
+		if err != nil {
+			return err
+		}
+		src, err = expandHome(src)
+		if err != nil {
+			return err
+		}
+
+		info, err := os.Stat(src)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", src, err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("shell config %s is a directory", src)
+		}
+
+		perm := constants.FilePermissions
+		if info.Mode().Perm()&0100 != 0 {
+			perm = 0700
+		}
+		if err := copyFileIfExists(src, dst, perm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// shellConfigDest resolves a volume-relative destination and rejects any that
+// would escape the volume home.
+func shellConfigDest(volumeMountPoint, volumeHome, rel string) (string, error) {
+	if rel == "" || filepath.IsAbs(rel) {
+		return "", fmt.Errorf("shell config destination %q must be relative to the volume", rel)
+	}
+	dst := filepath.Join(volumeMountPoint, rel)
+	if !strings.HasPrefix(dst, volumeHome+string(filepath.Separator)) {
+		return "", fmt.Errorf("shell config destination %q must be under %s/", rel, VolumeHomeDir)
+	}
+	return dst, nil
+}
+
+// expandHome replaces a leading "~/" with the user's home directory.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, path[2:]), nil
+}
@@ -0,0 +1,39 @@
+package volume
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+func TestSeedShellConfig(t *testing.T) {
+	host := t.TempDir()
+	mountPoint := t.TempDir()
+	src := filepath.Join(host, "config.fish")
+	if err := os.WriteFile(src, []byte("alias g git\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		src:                            "home/.config/fish/config.fish",
+		filepath.Join(host, "missing"): "home/.bashrc",
+	}
+	for range 2 {
+		if err := SeedShellConfig(mountPoint, files); err != nil {
+			t.Fatalf("SeedShellConfig() error = %v", err)
+		}
+	}
+	info, err := os.Stat(filepath.Join(mountPoint, "home", ".config", "fish", "config.fish"))
+	if err != nil || info.Mode().Perm() != constants.FilePermissions {
+		t.Errorf("seeded config = %v, %v", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(mountPoint, "home", ".bashrc")); !os.IsNotExist(err) {
+		t.Errorf("missing host file was seeded: %v", err)
+	}
+
+	if err := SeedShellConfig(mountPoint, map[string]string{src: "repos/config.fish"}); err == nil {
+		t.Error("SeedShellConfig() accepted a destination outside home")
+	}
+}
@@ -0,0 +1,273 @@
+package volume
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jeanhaley32/claude-capsule/internal/constants"
+)
+
+// ErrSnapshotsUnsupported is returned for volumes that are not APFS.
+var ErrSnapshotsUnsupported = errors.New("snapshots are not supported on this filesystem (APFS required)")
+
+// snapshotIndexFile maps capsule snapshot names to APFS snapshot names. It lives
+// in the volume root and is excluded from restores.
+const snapshotIndexFile = ".capsule-snapshots.json"
+
+// validSnapshotName restricts user-chosen snapshot names.
+var validSnapshotName = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,64}$`)
+
+// snapshotCommand runs a disk tool and returns its standard output. Tests
+// replace it to simulate diskutil and tmutil.
+var snapshotCommand = func(name string, args ...string) ([]byte, error) {
+	output, err := exec.Command(name, args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		err = fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return output, err
+}
+
+// Snapshot is an APFS snapshot of a mounted volume.
+type Snapshot struct {
+	Name     string // Capsule name given to SnapshotVolume; empty for other snapshots
+	APFSName string // Name as reported by diskutil
+	UUID     string
+	XID      string
+}
+
+// SnapshotVolume takes an APFS snapshot of the mounted volume and records it
+// under name. macOS only lets unentitled tools create snapshots through
+// `tmutil localsnapshot`, which snapshots the local volumes Time Machine
+// covers. The capsule volume's own snapshot list is compared before and after,
+// and the snapshot that appeared on it is recorded; if none did, the volume is
+// not covered and ErrSnapshotsUnsupported is returned.
+func SnapshotVolume(mountPoint, name string) error {
+	if !validSnapshotName.MatchString(name) {
+		return fmt.Errorf("invalid snapshot name %q: must be 1-64 characters of [a-zA-Z0-9._-]", name)
+	}
+	if err := requireAPFS(mountPoint); err != nil {
+		return err
+	}
+
+	index, err := readSnapshotIndex(mountPoint)
+	if err != nil {
+		return err
+	}
+	if _, exists := index[name]; exists {
+		return fmt.Errorf("snapshot %q already exists", name)
+	}
+
+	before, err := listAPFSSnapshots(mountPoint)
+	if err != nil {
+		return err
+	}
+	if _, err := snapshotCommand("tmutil", "localsnapshot"); err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	after, err := listAPFSSnapshots(mountPoint)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool, len(before))
+	for _, s := range before {
+		existing[s.UUID] = true
+	}
+	var created *Snapshot
+	for i := range after {
+		if !existing[after[i].UUID] {
+			created = &after[i]
+		}
+	}
+	if created == nil {
+		return fmt.Errorf("%w: no snapshot was created on %s; add it to Time Machine's local snapshots", ErrSnapshotsUnsupported, mountPoint)
+	}
+
+	index[name] = created.APFSName
+	return writeSnapshotIndex(mountPoint, index)
+}
+
+// SnapshotVolume takes an APFS snapshot of the mounted volume under name; see
+// the package function SnapshotVolume.
+func (m *MacOSVolumeManager) SnapshotVolume(mountPoint, name string) error {
+	return SnapshotVolume(mountPoint, name)
+}
+
+// ListSnapshots returns the APFS snapshots of the mounted volume, with capsule
+// names filled in for those created by SnapshotVolume.
+func ListSnapshots(mountPoint string) ([]Snapshot, error) {
+	if err := requireAPFS(mountPoint); err != nil {
+		return nil, err
+	}
+
+	snapshots, err := listAPFSSnapshots(mountPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := readSnapshotIndex(mountPoint)
+	if err != nil {
+		return nil, err
+	}
+	for i := range snapshots {
+		for name, apfsName := range index {
+			if snapshots[i].APFSName == apfsName {
+				snapshots[i].Name = name
+			}
+		}
+	}
+	return snapshots, nil
+}
+
+// RestoreSnapshot rolls the volume's contents back to the named snapshot. The
+// snapshot is mounted read-only and synced over the live volume, so the
+// container should be stopped first.
+func RestoreSnapshot(mountPoint, name string) error {
+	snapshots, err := ListSnapshots(mountPoint)
+	if err != nil {
+		return err
+	}
+	var apfsName string
+	for _, s := range snapshots {
+		if s.Name == name || s.APFSName == name {
+			apfsName = s.APFSName
+			break
+		}
+	}
+	if apfsName == "" {
+		return fmt.Errorf("snapshot %q not found", name)
+	}
+
+	info, err := diskutilInfo(mountPoint)
+	if err != nil {
+		return err
+	}
+	device := info["Device Node"]
+	if device == "" {
+		return fmt.Errorf("could not determine device for %s", mountPoint)
+	}
+
+	snapshotDir, err := os.MkdirTemp(TempDir(), "capsule-snapshot-*")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot mount point: %w", err)
+	}
+	defer os.Remove(snapshotDir)
+
+	if output, err := exec.Command("mount_apfs", "-o", "ro", "-s", apfsName, device, snapshotDir).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to mount snapshot: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	defer exec.Command("umount", snapshotDir).Run()
+
+	output, err := exec.Command("rsync", "-a", "--delete", "--exclude", "/"+snapshotIndexFile,
+		snapshotDir+"/", mountPoint+"/").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// listAPFSSnapshots returns the snapshots diskutil reports for the volume.
+func listAPFSSnapshots(mountPoint string) ([]Snapshot, error) {
+	output, err := snapshotCommand("diskutil", "apfs", "listSnapshots", mountPoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	return parseSnapshotList(string(output)), nil
+}
+
+// requireAPFS returns ErrSnapshotsUnsupported unless the mount point is on APFS.
+func requireAPFS(mountPoint string) error {
+	info, err := diskutilInfo(mountPoint)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(info["Type (Bundle)"], "apfs") {
+		return fmt.Errorf("%w: %s is %s", ErrSnapshotsUnsupported, mountPoint, info["Type (Bundle)"])
+	}
+	return nil
+}
+
+// diskutilInfo returns the key/value pairs printed by `diskutil info`.
+func diskutilInfo(mountPoint string) (map[string]string, error) {
+	output, err := snapshotCommand("diskutil", "info", mountPoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get volume info for %s: %w", mountPoint, err)
+	}
+	return parseDiskutilInfo(string(output)), nil
+}
+
+// parseDiskutilInfo parses "Key: Value" lines from `diskutil info`.
+func parseDiskutilInfo(output string) map[string]string {
+	info := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		info[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return info
+}
+
+// parseSnapshotList parses `diskutil apfs listSnapshots` output, where each
+// snapshot starts with a "+-- <UUID>" line followed by "Name:" and "XID:" lines.
+func parseSnapshotList(output string) []Snapshot {
+	var snapshots []Snapshot
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimLeft(line, "| ")
+		if uuid, ok := strings.CutPrefix(line, "+-- "); ok {
+			snapshots = append(snapshots, Snapshot{UUID: strings.TrimSpace(uuid)})
+			continue
+		}
+		if len(snapshots) == 0 {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		current := &snapshots[len(snapshots)-1]
+		switch strings.TrimSpace(key) {
+		case "Name":
+			current.APFSName = strings.TrimSpace(value)
+		case "XID":
+			current.XID = strings.TrimSpace(value)
+		}
+	}
+	return snapshots
+}
+
+// readSnapshotIndex loads the snapshot name index, returning an empty index if none exists.
+func readSnapshotIndex(mountPoint string) (map[string]string, error) {
+	index := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(mountPoint, snapshotIndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+		return nil, fmt.Errorf("failed to read snapshot index: %w", err)
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot index: %w", err)
+	}
+	return index, nil
+}
+
+// writeSnapshotIndex saves the snapshot name index.
+func writeSnapshotIndex(mountPoint string, index map[string]string) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(mountPoint, snapshotIndexFile), data, constants.FilePermissions); err != nil {
+		return fmt.Errorf("failed to write snapshot index: %w", err)
+	}
+	return nil
+}
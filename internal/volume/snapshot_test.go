@@ -0,0 +1,92 @@
+package volume
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseSnapshotList(t *testing.T) {
+	output := `Snapshots for disk5s1 (2 found)
+|
++-- 6B5F2A3C-1111-2222-3333-444455556666
+|   Name:        com.apple.TimeMachine.2026-01-02-030405.local
+|   XID:         1234
+|   Purgeable:   Yes
+|
++-- 7C6E3B4D-1111-2222-3333-444455556666
+    Name:        com.apple.TimeMachine.2026-01-03-030405.local
+    XID:         1250
+    Purgeable:   Yes
+`
+	snapshots := parseSnapshotList(output)
+	if len(snapshots) != 2 {
+		t.Fatalf("parseSnapshotList() returned %d snapshots, want 2", len(snapshots))
+	}
+	want := Snapshot{
+		APFSName: "com.apple.TimeMachine.2026-01-02-030405.local",
+		UUID:     "6B5F2A3C-1111-2222-3333-444455556666",
+		XID:      "1234",
+	}
+	if snapshots[0] != want {
+		t.Errorf("snapshots[0] = %+v, want %+v", snapshots[0], want)
+	}
+	if snapshots[1].XID != "1250" {
+		t.Errorf("snapshots[1].XID = %q, want 1250", snapshots[1].XID)
+	}
+}
+
+// fakeSnapshotTools simulates diskutil and tmutil for a volume whose snapshot
+// list gains an entry each time tmutil runs, when onVolume is set.
+func fakeSnapshotTools(t *testing.T, onVolume bool) {
+	t.Helper()
+	list := "Snapshots for disk5s1 (0 found)\n"
+	orig := snapshotCommand
+	t.Cleanup(func() { snapshotCommand = orig })
+	snapshotCommand = func(name string, args ...string) ([]byte, error) {
+		switch {
+		case name == "diskutil" && args[0] == "info":
+			return []byte("   Type (Bundle):             apfs\n   Device Node:               /dev/disk5s1\n"), nil
+		case name == "diskutil" && args[0] == "apfs":
+			return []byte(list), nil
+		case name == "tmutil":
+			if onVolume {
+				list += "+-- 6B5F2A3C-1111-2222-3333-444455556666\n    Name:        com.apple.TimeMachine.2026-01-02-030405.local\n    XID:         1234\n"
+			}
+			return []byte("Created local snapshot with date: 2026-01-02-030405\n"), nil
+		}
+		return nil, errors.New("unexpected command " + name + " " + strings.Join(args, " "))
+	}
+}
+
+func TestSnapshotVolume_Listed(t *testing.T) {
+	mountPoint := t.TempDir()
+	fakeSnapshotTools(t, true)
+
+	if err := SnapshotVolume(mountPoint, "before-upgrade"); err != nil {
+		t.Fatalf("SnapshotVolume() error = %v", err)
+	}
+	snapshots, err := ListSnapshots(mountPoint)
+	if err != nil {
+		t.Fatalf("ListSnapshots() error = %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].Name != "before-upgrade" {
+		t.Errorf("ListSnapshots() = %+v, want the named snapshot", snapshots)
+	}
+	if err := SnapshotVolume(mountPoint, "before-upgrade"); err == nil {
+		t.Error("SnapshotVolume() with a duplicate name succeeded, want error")
+	}
+}
+
+func TestSnapshotVolume_NotOnVolume(t *testing.T) {
+	mountPoint := t.TempDir()
+	fakeSnapshotTools(t, false)
+
+	err := SnapshotVolume(mountPoint, "before-upgrade")
+	if !errors.Is(err, ErrSnapshotsUnsupported) {
+		t.Fatalf("SnapshotVolume() error = %v, want ErrSnapshotsUnsupported", err)
+	}
+	if index, _ := readSnapshotIndex(mountPoint); len(index) != 0 {
+		t.Errorf("snapshot index = %v, want nothing recorded", index)
+	}
+}
@@ -0,0 +1,56 @@
+package volume
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jeanhaley32/claude-capsule/internal/platform"
+)
+
+// MinTempDirFreeSpace is the free space SetTempDir requires, enough for the
+// scratch files of a typical volume operation.
+const MinTempDirFreeSpace = 1 << 30
+
+// tempDir is the scratch directory set with SetTempDir.
+var tempDir string
+
+// SetTempDir sets the directory volume operations use for scratch files,
+// e.g. a large external disk on a machine with a small boot volume. It must
+// be an absolute, writable directory with at least MinTempDirFreeSpace free.
+// Empty restores the default, os.TempDir().
+func SetTempDir(dir string) error {
+	if dir != "" {
+		if err := validateTempDir(dir); err != nil {
+			return err
+		}
+		dir = filepath.Clean(dir)
+	}
+	tempDir = dir
+	return nil
+}
+
+// TempDir returns the directory set with SetTempDir, or os.TempDir().
+func TempDir() string {
+	if tempDir != "" {
+		return tempDir
+	}
+	return os.TempDir()
+}
+
+// validateTempDir checks that dir is a writable directory with enough free space.
+func validateTempDir(dir string) error {
+	if err := ValidateVolumeDir(dir); err != nil {
+		return fmt.Errorf("invalid temp directory: %w", err)
+	}
+	free, err := platform.FreeSpace(dir)
+	if err != nil {
+		// Not fatal: platforms without statfs still get a usable directory
+		return nil
+	}
+	if free < MinTempDirFreeSpace {
+		return fmt.Errorf("temp directory %s has %d MB free, need at least %d MB",
+			dir, free>>20, MinTempDirFreeSpace>>20)
+	}
+	return nil
+}
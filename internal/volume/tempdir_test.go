@@ -0,0 +1,25 @@
+package volume
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSetTempDir(t *testing.T) {
+	t.Cleanup(func() { SetTempDir("") })
+
+	dir := t.TempDir()
+	if err := SetTempDir(dir); err != nil {
+		t.Fatalf("SetTempDir() error = %v", err)
+	}
+	if TempDir() != dir {
+		t.Errorf("TempDir() = %q, want %q", TempDir(), dir)
+	}
+
+	if err := SetTempDir("relative/tmp"); err == nil {
+		t.Error("SetTempDir() accepted a relative path")
+	}
+	if err := SetTempDir(""); err != nil || TempDir() != os.TempDir() {
+		t.Errorf("SetTempDir(\"\") = %v, TempDir() = %q, want default", err, TempDir())
+	}
+}
@@ -0,0 +1,68 @@
+package volume
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrVolumeCorrupted is returned by QuickVerifyVolumeFile when the volume image
+// is empty, truncated, or not a disk image at all.
+var ErrVolumeCorrupted = errors.New("volume file appears corrupted or truncated")
+
+// volumeMagics are the headers a capsule volume image can start with: an
+// encrypted image (as created by Bootstrap) or a plain sparse image.
+var volumeMagics = [][]byte{
+	[]byte("encrcdsa"),
+	[]byte("sprs"),
+}
+
+// sparseBundlePlist is the file every .sparsebundle directory must contain.
+const sparseBundlePlist = "Info.plist"
+
+// QuickVerifyVolumeFile checks, by reading only the header, that volumePath
+// looks like a usable disk image before hdiutil is asked to attach it. Errors
+// for damaged images wrap ErrVolumeCorrupted; restore those from a backup.
+func QuickVerifyVolumeFile(volumePath string) error {
+	info, err := os.Stat(volumePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("volume not found at %s", volumePath)
+		}
+		return fmt.Errorf("failed to stat volume %s: %w", volumePath, err)
+	}
+
+	// A sparse bundle is a directory of bands described by Info.plist
+	if info.IsDir() {
+		plist, err := os.Stat(filepath.Join(volumePath, sparseBundlePlist))
+		if err != nil || plist.Size() == 0 {
+			return fmt.Errorf("%w: %s has no %s; restore it from a backup", ErrVolumeCorrupted, volumePath, sparseBundlePlist)
+		}
+		return nil
+	}
+
+	if info.Size() == 0 {
+		return fmt.Errorf("%w: %s is empty; restore it from a backup", ErrVolumeCorrupted, volumePath)
+	}
+
+	f, err := os.Open(volumePath)
+	if err != nil {
+		return fmt.Errorf("failed to open volume %s: %w", volumePath, err)
+	}
+	defer f.Close()
+
+	header := make([]byte, 8)
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("failed to read volume %s: %w", volumePath, err)
+	}
+	for _, magic := range volumeMagics {
+		if bytes.HasPrefix(header[:n], magic) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s does not start with a disk image header; restore it from a backup", ErrVolumeCorrupted, volumePath)
+}
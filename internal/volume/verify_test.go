@@ -0,0 +1,40 @@
+package volume
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuickVerifyVolumeFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	if err := QuickVerifyVolumeFile(write("ok.sparseimage", "encrcdsa\x00\x00\x00\x02")); err != nil {
+		t.Errorf("encrypted image: error = %v", err)
+	}
+	for name, content := range map[string]string{
+		"empty.sparseimage":     "",
+		"truncated.sparseimage": "enc",
+		"garbage.sparseimage":   "<html>not an image</html>",
+	} {
+		if err := QuickVerifyVolumeFile(write(name, content)); !errors.Is(err, ErrVolumeCorrupted) {
+			t.Errorf("%s: error = %v, want ErrVolumeCorrupted", name, err)
+		}
+	}
+
+	bundle := filepath.Join(dir, "vol.sparsebundle")
+	if err := os.Mkdir(bundle, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := QuickVerifyVolumeFile(bundle); !errors.Is(err, ErrVolumeCorrupted) {
+		t.Errorf("bundle without plist: error = %v, want ErrVolumeCorrupted", err)
+	}
+}
@@ -0,0 +1,35 @@
+package volume
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Mount readiness polling
+const (
+	// DefaultMountWaitTimeout is how long callers should wait for a fresh mount to appear.
+	DefaultMountWaitTimeout = 10 * time.Second
+
+	mountPollInterval = 100 * time.Millisecond
+)
+
+// WaitMounted polls until mountPoint exists and can be stat'ed as a directory.
+// hdiutil can return before the mount point is visible to other processes, so
+// callers should wait on it between attaching the volume and starting a container.
+func WaitMounted(mountPoint string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		info, err := os.Stat(mountPoint)
+		if err == nil && info.IsDir() {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if err == nil {
+				err = fmt.Errorf("not a directory")
+			}
+			return fmt.Errorf("volume not ready at %s after %v: %w", mountPoint, timeout, err)
+		}
+		time.Sleep(mountPollInterval)
+	}
+}